@@ -2,10 +2,14 @@ package client
 
 import (
 	"bytes"
+	"crypto/rand"
 	"fmt"
 	"github.com/zond/god/common"
+	"github.com/zond/god/persistence"
 	"github.com/zond/setop"
+	"io"
 	"net/rpc"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -17,6 +21,44 @@ const (
 	stopped
 )
 
+// restoreBatchSize is the number of operations Restore reads before sending them to their owners.
+const restoreBatchSize = 1000
+
+// backupPageSize is the number of values and sub trees Backup fetches from their owner per request.
+const backupPageSize = 100
+
+// snapshotRefreshInterval is how often Backup tells the nodes that it still uses their snapshots, so that they keep them.
+const snapshotRefreshInterval = 10 * time.Second
+
+// repairOperations maps the read operations that will cause read repair to the operations used to repair lagging replicas.
+var repairOperations = map[string]string{
+	"DHash.Get":    "DHash.Repair",
+	"DHash.SubGet": "DHash.SubRepair",
+}
+
+// Consistency defines how many replicas a read or write will involve before it returns.
+type Consistency int32
+
+const (
+	// One will only involve the owner of the data.
+	One Consistency = iota
+	// Quorum will involve a majority of the replicas of the data.
+	Quorum
+	// All will involve all replicas of the data.
+	All
+)
+
+// replicas returns the number of replicas this Consistency involves when there are redundancy replicas.
+func (self Consistency) replicas(redundancy int) int {
+	switch self {
+	case One:
+		return common.Min(1, redundancy)
+	case Quorum:
+		return redundancy/2 + 1
+	}
+	return redundancy
+}
+
 func findKeys(op *setop.SetOp) (result map[string]bool) {
 	result = make(map[string]bool)
 	for _, source := range op.Sources {
@@ -46,6 +88,8 @@ func findKeys(op *setop.SetOp) (result map[string]bool) {
 //
 // If there are two methods with similar names except that one has a capital S prefixed, that means that the method with the capital S will not return until all nodes responsible for the written data has received the data, while the one without the capital S will return as soon as the owner of the data has received it.
 //
+// Methods without the capital S will return when as many nodes as the write Consistency of the Conn defines have received the data, and reads will ask as many nodes as the read Consistency defines. See SetReadConsistency, SetWriteConsistency and WithConsistency.
+//
 // Methods prefixed Sub will work on sub trees.
 //
 // Methods prefixed Reverse will work in reverse order. Return slices in reverse order and indices from the end instead of the start etc.
@@ -58,18 +102,24 @@ func findKeys(op *setop.SetOp) (result map[string]bool) {
 //
 // Usage: https://github.com/zond/god/blob/master/client/client_test.go
 type Conn struct {
-	ring  *common.Ring
-	state int32
+	ring             *common.Ring
+	state            int32
+	readConsistency  int32
+	writeConsistency int32
 }
 
 // NewConnRing creates a new Conn from a given set of known nodes. For internal usage.
 func NewConnRing(ring *common.Ring) *Conn {
-	return &Conn{ring: ring}
+	return &Conn{
+		ring:             ring,
+		readConsistency:  int32(All),
+		writeConsistency: int32(One),
+	}
 }
 
 // NewConn creates a new Conn to a cluster defined by the address of one of its members.
 func NewConn(addr string) (result *Conn, err error) {
-	result = &Conn{ring: common.NewRing()}
+	result = NewConnRing(common.NewRing())
 	var newNodes common.Remotes
 	err = common.Switch.Call(addr, "Discord.Nodes", 0, &newNodes)
 	result.ring.SetNodes(newNodes)
@@ -84,6 +134,31 @@ func MustConn(addr string) (result *Conn) {
 	}
 	return
 }
+
+// SetReadConsistency will make reads using this Conn ask c replicas and return the most recent value found. The default is All.
+func (self *Conn) SetReadConsistency(c Consistency) {
+	atomic.StoreInt32(&self.readConsistency, int32(c))
+}
+
+// SetWriteConsistency will make writes using this Conn, except those prefixed with a capital S that always use All, return when c replicas have received the data. The default is One.
+func (self *Conn) SetWriteConsistency(c Consistency) {
+	atomic.StoreInt32(&self.writeConsistency, int32(c))
+}
+
+// WithConsistency returns a Conn sharing the set of known nodes with this Conn, but using the given read and write Consistency.
+func (self *Conn) WithConsistency(read, write Consistency) *Conn {
+	return &Conn{
+		ring:             self.ring,
+		readConsistency:  int32(read),
+		writeConsistency: int32(write),
+	}
+}
+func (self *Conn) readReplicas() int {
+	return Consistency(atomic.LoadInt32(&self.readConsistency)).replicas(self.ring.Redundancy())
+}
+func (self *Conn) writeAcks() int {
+	return Consistency(atomic.LoadInt32(&self.writeConsistency)).replicas(self.ring.Redundancy())
+}
 func (self *Conn) hasState(s int32) bool {
 	return atomic.LoadInt32(&self.state) == s
 }
@@ -152,6 +227,7 @@ func (self *Conn) subClear(key []byte, sync bool) {
 	data := common.Item{
 		Key:  key,
 		Sync: sync,
+		Acks: self.writeAcks(),
 	}
 	_, _, successor := self.ring.Remotes(key)
 	var x int
@@ -165,65 +241,264 @@ func (self *Conn) subDel(key, subKey []byte, sync bool) {
 		Key:    key,
 		SubKey: subKey,
 		Sync:   sync,
+		Acks:   self.writeAcks(),
 	}
 	_, _, successor := self.ring.Remotes(key)
 	var x int
 	if err := successor.Call("DHash.SubDel", data, &x); err != nil {
+		if _, ok := err.(rpc.ServerError); ok {
+			return
+		}
 		self.removeNode(*successor)
 		self.subDel(key, subKey, sync)
 	}
 }
-func (self *Conn) subPutVia(succ *common.Remote, key, subKey, value []byte, sync bool) {
+func (self *Conn) subPrefixDel(key, prefix []byte, sync bool) (deleted int) {
 	data := common.Item{
 		Key:    key,
-		SubKey: subKey,
-		Value:  value,
+		SubKey: prefix,
 		Sync:   sync,
+		Acks:   self.writeAcks(),
+	}
+	_, _, successor := self.ring.Remotes(key)
+	if err := successor.Call("DHash.SubPrefixDel", data, &deleted); err != nil {
+		self.removeNode(*successor)
+		return self.subPrefixDel(key, prefix, sync)
+	}
+	return
+}
+func (self *Conn) subDelRange(op common.RangeOp) (deleted int) {
+	op.Acks = self.writeAcks()
+	_, _, successor := self.ring.Remotes(op.Range.Key)
+	if err := successor.Call("DHash.SubDelRange", op, &deleted); err != nil {
+		self.removeNode(*successor)
+		return self.subDelRange(op)
+	}
+	return
+}
+
+// indexRangeOp returns a RangeOp deleting the indices between min and max in the sub tree defined by key.
+func indexRangeOp(key []byte, min, max *int, sync bool) (result common.RangeOp) {
+	result = common.RangeOp{
+		Range: common.Range{
+			Key:    key,
+			MinInc: min != nil,
+			MaxInc: max != nil,
+		},
+		Index: true,
+		Sync:  sync,
+	}
+	if min != nil {
+		result.Range.MinIndex = *min
+	}
+	if max != nil {
+		result.Range.MaxIndex = *max
+	}
+	return
+}
+func (self *Conn) subPutVia(succ *common.Remote, key, subKey, value []byte, ttl time.Duration, sync bool) (err error) {
+	data := common.Item{
+		Key:      key,
+		SubKey:   subKey,
+		Value:    value,
+		Sync:     sync,
+		Lifetime: int64(ttl),
+		Acks:     self.writeAcks(),
 	}
 	var x int
-	if err := succ.Call("DHash.SubPut", data, &x); err != nil {
+	if err = succ.Call("DHash.SubPut", data, &x); err != nil {
+		if _, ok := err.(rpc.ServerError); ok {
+			return
+		}
 		self.removeNode(*succ)
 		_, _, newSuccessor := self.ring.Remotes(key)
 		*succ = *newSuccessor
-		self.subPutVia(succ, key, subKey, value, sync)
+		return self.subPutVia(succ, key, subKey, value, ttl, sync)
 	}
+	return
 }
-func (self *Conn) subPut(key, subKey, value []byte, sync bool) {
+func (self *Conn) subPut(key, subKey, value []byte, ttl time.Duration, sync bool) error {
 	_, _, successor := self.ring.Remotes(key)
-	self.subPutVia(successor, key, subKey, value, sync)
+	return self.subPutVia(successor, key, subKey, value, ttl, sync)
 }
 func (self *Conn) del(key []byte, sync bool) {
 	data := common.Item{
 		Key:  key,
 		Sync: sync,
+		Acks: self.writeAcks(),
 	}
 	_, _, successor := self.ring.Remotes(key)
 	var x int
 	if err := successor.Call("DHash.Del", data, &x); err != nil {
+		if _, ok := err.(rpc.ServerError); ok {
+			return
+		}
 		self.removeNode(*successor)
 		self.del(key, sync)
 	}
 }
-func (self *Conn) putVia(succ *common.Remote, key, value []byte, sync bool) {
+func (self *Conn) putVia(succ *common.Remote, key, value []byte, ttl time.Duration, sync bool) {
 	data := common.Item{
-		Key:   key,
-		Value: value,
-		Sync:  sync,
+		Key:      key,
+		Value:    value,
+		Sync:     sync,
+		Lifetime: int64(ttl),
+		Acks:     self.writeAcks(),
 	}
 	var x int
 	if err := succ.Call("DHash.Put", data, &x); err != nil {
+		if _, ok := err.(rpc.ServerError); ok {
+			return
+		}
 		self.removeNode(*succ)
 		_, _, newSuccessor := self.ring.Remotes(key)
 		*succ = *newSuccessor
-		self.putVia(succ, key, value, sync)
+		self.putVia(succ, key, value, ttl, sync)
 	}
 }
-func (self *Conn) put(key, value []byte, sync bool) {
+func (self *Conn) put(key, value []byte, ttl time.Duration, sync bool) {
 	_, _, successor := self.ring.Remotes(key)
-	self.putVia(successor, key, value, sync)
+	self.putVia(successor, key, value, ttl, sync)
+}
+func (self *Conn) multiPut(items []common.Item, indices []int, errs []error) {
+	var owners common.Remotes
+	var batches [][]common.Item
+	var batchIndices [][]int
+	batchByAddr := make(map[string]int)
+	for index, item := range items {
+		_, _, successor := self.ring.Remotes(item.Key)
+		batch, found := batchByAddr[successor.Addr]
+		if !found {
+			batch = len(owners)
+			batchByAddr[successor.Addr] = batch
+			owners = append(owners, *successor)
+			batches = append(batches, nil)
+			batchIndices = append(batchIndices, nil)
+		}
+		batches[batch] = append(batches[batch], item)
+		batchIndices[batch] = append(batchIndices[batch], indices[index])
+	}
+	futures := make([]*rpc.Call, len(owners))
+	results := make([]*[]string, len(owners))
+	for batch, owner := range owners {
+		var thisResult []string
+		results[batch] = &thisResult
+		futures[batch] = owner.Go("DHash.MultiPut", batches[batch], &thisResult)
+	}
+	for batch, future := range futures {
+		<-future.Done
+		if future.Error != nil {
+			if _, ok := future.Error.(rpc.ServerError); ok {
+				for _, index := range batchIndices[batch] {
+					errs[index] = future.Error
+				}
+			} else {
+				self.removeNode(owners[batch])
+				self.multiPut(batches[batch], batchIndices[batch], errs)
+			}
+		} else {
+			for index, msg := range *results[batch] {
+				if msg != "" {
+					errs[batchIndices[batch][index]] = fmt.Errorf("%v", msg)
+				}
+			}
+		}
+	}
+}
+func (self *Conn) multiPutSync(items []common.Item, sync bool) (errs []error) {
+	indices := make([]int, len(items))
+	toPut := make([]common.Item, len(items))
+	for index, item := range items {
+		indices[index] = index
+		item.Sync = sync
+		item.Acks = self.writeAcks()
+		toPut[index] = item
+	}
+	errs = make([]error, len(items))
+	self.multiPut(toPut, indices, errs)
+	return
+}
+func (self *Conn) multiSubPut(key []byte, values map[string][]byte, sync bool) (errs map[string]error) {
+	items := make([]common.Item, 0, len(values))
+	for subKey, value := range values {
+		items = append(items, common.Item{
+			Key:    key,
+			SubKey: []byte(subKey),
+			Value:  value,
+		})
+	}
+	for index, err := range self.multiPutSync(items, sync) {
+		if err != nil {
+			if errs == nil {
+				errs = make(map[string]error)
+			}
+			errs[string(items[index].SubKey)] = err
+		}
+	}
+	return
+}
+func (self *Conn) conditional(operation string, data common.Item) (result bool) {
+	data.Acks = self.writeAcks()
+	_, _, successor := self.ring.Remotes(data.Key)
+	if err := successor.Call(operation, data, &result); err != nil {
+		if _, ok := err.(rpc.ServerError); ok {
+			return
+		}
+		self.removeNode(*successor)
+		return self.conditional(operation, data)
+	}
+	return
+}
+func (self *Conn) incr(operation string, data common.Item) (result int64, err error) {
+	data.Acks = self.writeAcks()
+	_, _, successor := self.ring.Remotes(data.Key)
+	if err = successor.Call(operation, data, &result); err != nil {
+		if _, ok := err.(rpc.ServerError); ok {
+			return
+		}
+		self.removeNode(*successor)
+		return self.incr(operation, data)
+	}
+	return
+}
+func (self *Conn) jsonGet(op common.JSONOp) (value []byte, found bool, err error) {
+	var result common.Item
+	_, _, successor := self.ring.Remotes(op.Key)
+	if err = successor.Call("DHash.JSONGet", op, &result); err != nil {
+		if _, ok := err.(rpc.ServerError); ok {
+			return
+		}
+		self.removeNode(*successor)
+		return self.jsonGet(op)
+	}
+	return result.Value, result.Exists, nil
+}
+func (self *Conn) jsonUpdate(operation string, op common.JSONOp) (doc []byte, err error) {
+	op.Acks = self.writeAcks()
+	_, _, successor := self.ring.Remotes(op.Key)
+	if err = successor.Call(operation, op, &doc); err != nil {
+		if _, ok := err.(rpc.ServerError); ok {
+			return
+		}
+		self.removeNode(*successor)
+		return self.jsonUpdate(operation, op)
+	}
+	return
+}
+func (self *Conn) invoke(inv common.Invocation) (result []byte, err error) {
+	inv.Acks = self.writeAcks()
+	_, _, successor := self.ring.Remotes(inv.Key)
+	if err = successor.Call("DHash.Invoke", inv, &result); err != nil {
+		if _, ok := err.(rpc.ServerError); ok {
+			return
+		}
+		self.removeNode(*successor)
+		return self.invoke(inv)
+	}
+	return
 }
 func (self *Conn) mergeRecent(operation string, r common.Range, up bool) (result []common.Item) {
-	currentRedundancy := self.ring.Redundancy()
+	currentRedundancy := self.readReplicas()
 	futures := make([]*rpc.Call, currentRedundancy)
 	results := make([]*[]common.Item, currentRedundancy)
 	nodes := make(common.Remotes, currentRedundancy)
@@ -248,7 +523,7 @@ func (self *Conn) mergeRecent(operation string, r common.Range, up bool) (result
 	return
 }
 func (self *Conn) findRecent(operation string, data common.Item) (result *common.Item) {
-	currentRedundancy := self.ring.Redundancy()
+	currentRedundancy := self.readReplicas()
 	futures := make([]*rpc.Call, currentRedundancy)
 	results := make([]*common.Item, currentRedundancy)
 	nodes := make(common.Remotes, currentRedundancy)
@@ -272,11 +547,126 @@ func (self *Conn) findRecent(operation string, data common.Item) (result *common
 			result = results[index]
 		}
 	}
+	if repair, found := repairOperations[operation]; found {
+		for index, thisResult := range results {
+			if thisResult.Timestamp < result.Timestamp && (thisResult.Exists || result.Exists) {
+				go self.repair(nodes[index], repair, *result)
+			}
+		}
+	}
+	return
+}
+
+// repair will push data, the most recent version found, to a replica that returned an older version.
+func (self *Conn) repair(node common.Remote, operation string, data common.Item) {
+	var x int
+	node.Call(operation, data, &x)
+}
+
+// multiFindRecent will ask all replicas of the owners of the items, in parallel and in one batch per replica, for the items,
+// and return the most recent version of each item.
+func (self *Conn) multiFindRecent(operation string, items []common.Item) (result []common.Item) {
+	var batches [][]common.Item
+	var batchIndices [][]int
+	batchByAddr := make(map[string]int)
+	for index, item := range items {
+		_, _, successor := self.ring.Remotes(item.Key)
+		batch, found := batchByAddr[successor.Addr]
+		if !found {
+			batch = len(batches)
+			batchByAddr[successor.Addr] = batch
+			batches = append(batches, nil)
+			batchIndices = append(batchIndices, nil)
+		}
+		batches[batch] = append(batches[batch], item)
+		batchIndices[batch] = append(batchIndices[batch], index)
+	}
+	currentRedundancy := self.readReplicas()
+	var futures []*rpc.Call
+	var results []*[]common.Item
+	var nodes common.Remotes
+	var indices [][]int
+	for batch, batchItems := range batches {
+		nextKey := batchItems[0].Key
+		var nextSuccessor *common.Remote
+		for i := 0; i < currentRedundancy; i++ {
+			_, _, nextSuccessor = self.ring.Remotes(nextKey)
+			var thisResult []common.Item
+			nodes = append(nodes, *nextSuccessor)
+			results = append(results, &thisResult)
+			indices = append(indices, batchIndices[batch])
+			futures = append(futures, nextSuccessor.Go(operation, batchItems, &thisResult))
+			nextKey = nextSuccessor.Pos
+		}
+	}
+	result = make([]common.Item, len(items))
+	for index, future := range futures {
+		<-future.Done
+		if future.Error != nil {
+			self.removeNode(nodes[index])
+			return self.multiFindRecent(operation, items)
+		}
+		for resultIndex, item := range *results[index] {
+			if itemIndex := indices[index][resultIndex]; result[itemIndex].Timestamp < item.Timestamp {
+				result[itemIndex] = item
+			}
+		}
+	}
+	return
+}
+func (self *Conn) watch(w common.Watch) (changes chan common.Change, stop func()) {
+	changes = make(chan common.Change)
+	stopped := make(chan bool)
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			close(stopped)
+		})
+	}
+	var owner common.Remote
+	for {
+		_, _, successor := self.ring.Remotes(w.Key)
+		owner = *successor
+		var result common.WatchResult
+		if err := owner.Call("DHash.Watch", common.Watch{Seq: -1}, &result); err != nil {
+			self.removeNode(owner)
+		} else {
+			w.Seq, w.After = result.Seq, result.Time
+			break
+		}
+	}
+	go func() {
+		defer close(changes)
+		for {
+			select {
+			case <-stopped:
+				return
+			default:
+			}
+			if _, _, successor := self.ring.Remotes(w.Key); successor.Addr != owner.Addr {
+				owner = *successor
+				w.Seq = 0
+			}
+			var result common.WatchResult
+			if err := owner.Call("DHash.Watch", w, &result); err != nil {
+				self.removeNode(owner)
+				continue
+			}
+			for _, change := range result.Changes {
+				select {
+				case changes <- change:
+				case <-stopped:
+					return
+				}
+			}
+			w.Seq, w.After = result.Seq, result.Time
+		}
+	}()
 	return
 }
 func (self *Conn) consume(c chan [2][]byte, wait *sync.WaitGroup, successor *common.Remote) {
 	for pair := range c {
-		self.putVia(successor, pair[0], pair[1], false)
+		self.putVia(successor, pair[0], pair[1], 0, false)
 	}
 	wait.Done()
 }
@@ -303,7 +693,7 @@ func (self *Conn) dump(c chan [2][]byte, wait *sync.WaitGroup) {
 func (self *Conn) subDump(key []byte, c chan [2][]byte, wait *sync.WaitGroup) {
 	_, _, succ := self.ring.Remotes(key)
 	for pair := range c {
-		self.subPutVia(succ, key, pair[0], pair[1], false)
+		self.subPutVia(succ, key, pair[0], pair[1], 0, false)
 	}
 	wait.Done()
 }
@@ -318,24 +708,499 @@ func (self *Conn) Clear() {
 	}
 }
 
+// Backup will write a snapshot of the configuration, values, sub trees and sub tree configurations of the cluster to w, readable by Restore.
+// Every node first takes a snapshot of itself, and the backup is then fetched one page at a time from the snapshot of the owner of each page,
+// so writes done after the snapshots are not included. Nodes that fail will be removed, and their pages fetched from the snapshots of the
+// nodes owning them instead, which hold them as replicas.
+func (self *Conn) Backup(w io.Writer) (err error) {
+	var writer *persistence.Writer
+	if writer, err = persistence.NewWriter(w, persistence.GzipCompression); err != nil {
+		return
+	}
+	snapshot := make([]byte, 16)
+	if _, err = rand.Read(snapshot); err != nil {
+		return
+	}
+	var x int
+	refreshed := time.Now()
+	for _, node := range self.ring.Nodes() {
+		if e := node.Call("DHash.Snapshot", snapshot, &x); e != nil {
+			self.removeNode(node)
+		}
+	}
+	defer func() {
+		for _, node := range self.ring.Nodes() {
+			node.Call("DHash.ReleaseSnapshot", snapshot, &x)
+		}
+	}()
+	var conf common.Conf
+	_, _, successor := self.ring.Remotes(nil)
+	for err = successor.Call("DHash.Configuration", 0, &conf); err != nil; err = successor.Call("DHash.Configuration", 0, &conf) {
+		if _, ok := err.(rpc.ServerError); ok {
+			return
+		}
+		self.removeNode(*successor)
+		_, _, successor = self.ring.Remotes(nil)
+	}
+	if len(conf.Data) > 0 {
+		if err = writer.Write(persistence.Op{Configuration: conf.Data, Timestamp: conf.Timestamp}); err != nil {
+			return
+		}
+	}
+	var from []byte
+	fromInc := true
+	for {
+		if time.Since(refreshed) > snapshotRefreshInterval {
+			refreshed = time.Now()
+			for _, node := range self.ring.Nodes() {
+				node.Call("DHash.Snapshot", snapshot, &x)
+			}
+		}
+		_, _, owner := self.ring.Remotes(from)
+		var next []byte
+		if bytes.Compare(from, owner.Pos) < 0 {
+			next = owner.Pos
+		}
+		var ops []persistence.Op
+		if err = owner.Call("DHash.BackupPage", common.SnapshotRange{Snapshot: snapshot, Range: common.Range{Min: from, MinInc: fromInc, Max: next, Len: backupPageSize}}, &ops); err != nil {
+			if _, ok := err.(rpc.ServerError); ok {
+				return
+			}
+			self.removeNode(*owner)
+			continue
+		}
+		keys := 0
+		var last []byte
+		for _, op := range ops {
+			if keys == 0 || bytes.Compare(op.Key, last) != 0 {
+				keys, last = keys+1, op.Key
+			}
+			if err = writer.Write(op); err != nil {
+				return
+			}
+		}
+		if keys == backupPageSize {
+			from, fromInc = last, false
+		} else if next != nil {
+			from, fromInc = next, true
+		} else {
+			break
+		}
+	}
+	return writer.Close()
+}
+
+// Restore will put the values, sub trees and configurations in the snapshot read from r, as written by Backup, into the nodes owning them,
+// keeping their timestamps, unless the nodes have newer data for them.
+func (self *Conn) Restore(r io.Reader) (err error) {
+	var ops []persistence.Op
+	if _, err = persistence.Read(r, func(op persistence.Op) {
+		if ops = append(ops, op); len(ops) == restoreBatchSize {
+			self.restore(ops)
+			ops = nil
+		}
+	}); err != nil {
+		return
+	}
+	self.restore(ops)
+	return
+}
+
+// restore will send ops to the nodes owning them, in one batch per owner.
+func (self *Conn) restore(ops []persistence.Op) {
+	batches := make(map[string][]persistence.Op)
+	owners := make(map[string]common.Remote)
+	for _, op := range ops {
+		_, _, successor := self.ring.Remotes(op.Key)
+		batches[successor.Addr] = append(batches[successor.Addr], op)
+		owners[successor.Addr] = *successor
+	}
+	var x int
+	for addr, batch := range batches {
+		if err := owners[addr].Call("DHash.Restore", batch, &x); err != nil {
+			self.removeNode(owners[addr])
+			self.restore(batch)
+		}
+	}
+}
+
 // SSubPut will put value under subKey in the sub tree defined by key.
 func (self *Conn) SSubPut(key, subKey, value []byte) {
-	self.subPut(key, subKey, value, true)
+	self.subPut(key, subKey, value, 0, true)
 }
 
 // SubPut will put value under subKey in the sub tree defined by key.
 func (self *Conn) SubPut(key, subKey, value []byte) {
-	self.subPut(key, subKey, value, false)
+	self.subPut(key, subKey, value, 0, false)
+}
+
+// SSubPutChecked will put value under subKey in the sub tree defined by key, and return any error from the owner of the sub tree,
+// such as value or subKey not matching the valueEncoding or keyEncoding in the configuration of the sub tree.
+func (self *Conn) SSubPutChecked(key, subKey, value []byte) error {
+	return self.subPut(key, subKey, value, 0, true)
+}
+
+// SubPutChecked will put value under subKey in the sub tree defined by key, and return any error from the owner of the sub tree,
+// such as value or subKey not matching the valueEncoding or keyEncoding in the configuration of the sub tree.
+func (self *Conn) SubPutChecked(key, subKey, value []byte) error {
+	return self.subPut(key, subKey, value, 0, false)
+}
+
+// SSubPutTTL will put value under subKey in the sub tree defined by key, and make it expire after ttl.
+func (self *Conn) SSubPutTTL(key, subKey, value []byte, ttl time.Duration) {
+	self.subPut(key, subKey, value, ttl, true)
+}
+
+// SubPutTTL will put value under subKey in the sub tree defined by key, and make it expire after ttl.
+func (self *Conn) SubPutTTL(key, subKey, value []byte, ttl time.Duration) {
+	self.subPut(key, subKey, value, ttl, false)
 }
 
 // SPut will put value under key.
 func (self *Conn) SPut(key, value []byte) {
-	self.put(key, value, true)
+	self.put(key, value, 0, true)
 }
 
 // Put will put value under key.
 func (self *Conn) Put(key, value []byte) {
-	self.put(key, value, false)
+	self.put(key, value, 0, false)
+}
+
+// SPutTTL will put value under key, and make it expire after ttl.
+func (self *Conn) SPutTTL(key, value []byte, ttl time.Duration) {
+	self.put(key, value, ttl, true)
+}
+
+// PutTTL will put value under key, and make it expire after ttl.
+func (self *Conn) PutTTL(key, value []byte, ttl time.Duration) {
+	self.put(key, value, ttl, false)
+}
+
+// MultiPut will put the Value of each item under its Key, or under its SubKey in the sub tree defined by its Key if it has a SubKey.
+// The items are sent in one batch per owner, and the returned errs will contain an error, or nil, for each item.
+// Each item may have a Lifetime, after which its value will expire.
+func (self *Conn) MultiPut(items []common.Item) (errs []error) {
+	return self.multiPutSync(items, false)
+}
+
+// SMultiPut will put the Value of each item under its Key, or under its SubKey in the sub tree defined by its Key if it has a SubKey.
+// The items are sent in one batch per owner, and the returned errs will contain an error, or nil, for each item.
+// Each item may have a Lifetime, after which its value will expire.
+func (self *Conn) SMultiPut(items []common.Item) (errs []error) {
+	return self.multiPutSync(items, true)
+}
+
+// MultiSubPut will put all values in the sub tree defined by key, in one batch, and return the errors for the sub keys that failed.
+func (self *Conn) MultiSubPut(key []byte, values map[string][]byte) (errs map[string]error) {
+	return self.multiSubPut(key, values, false)
+}
+
+// SMultiSubPut will put all values in the sub tree defined by key, in one batch, and return the errors for the sub keys that failed.
+func (self *Conn) SMultiSubPut(key []byte, values map[string][]byte) (errs map[string]error) {
+	return self.multiSubPut(key, values, true)
+}
+
+// CompareAndSwap will put value under key if the current value under key is expected, and return whether it did.
+// A nil expected means that key must not exist.
+func (self *Conn) CompareAndSwap(key, expected, value []byte) (swapped bool) {
+	return self.conditional("DHash.CompareAndSwap", common.Item{
+		Key:         key,
+		Expected:    expected,
+		HasExpected: expected != nil,
+		Value:       value,
+	})
+}
+
+// SCompareAndSwap will put value under key if the current value under key is expected, and return whether it did.
+// A nil expected means that key must not exist.
+func (self *Conn) SCompareAndSwap(key, expected, value []byte) (swapped bool) {
+	return self.conditional("DHash.CompareAndSwap", common.Item{
+		Key:         key,
+		Expected:    expected,
+		HasExpected: expected != nil,
+		Value:       value,
+		Sync:        true,
+	})
+}
+
+// PutIfAbsent will put value under key if key doesn't exist, and return whether it did.
+func (self *Conn) PutIfAbsent(key, value []byte) (swapped bool) {
+	return self.CompareAndSwap(key, nil, value)
+}
+
+// SPutIfAbsent will put value under key if key doesn't exist, and return whether it did.
+func (self *Conn) SPutIfAbsent(key, value []byte) (swapped bool) {
+	return self.SCompareAndSwap(key, nil, value)
+}
+
+// DelIfEqual will delete key if the current value under key is expected, and return whether it did.
+func (self *Conn) DelIfEqual(key, expected []byte) (deleted bool) {
+	return self.conditional("DHash.DelIfEqual", common.Item{
+		Key:         key,
+		Expected:    expected,
+		HasExpected: expected != nil,
+	})
+}
+
+// SDelIfEqual will delete key if the current value under key is expected, and return whether it did.
+func (self *Conn) SDelIfEqual(key, expected []byte) (deleted bool) {
+	return self.conditional("DHash.DelIfEqual", common.Item{
+		Key:         key,
+		Expected:    expected,
+		HasExpected: expected != nil,
+		Sync:        true,
+	})
+}
+
+// SubCompareAndSwap will put value under subKey in the sub tree defined by key if the current value under subKey is expected, and return whether it did.
+// A nil expected means that subKey must not exist.
+func (self *Conn) SubCompareAndSwap(key, subKey, expected, value []byte) (swapped bool) {
+	return self.conditional("DHash.SubCompareAndSwap", common.Item{
+		Key:         key,
+		SubKey:      subKey,
+		Expected:    expected,
+		HasExpected: expected != nil,
+		Value:       value,
+	})
+}
+
+// SSubCompareAndSwap will put value under subKey in the sub tree defined by key if the current value under subKey is expected, and return whether it did.
+// A nil expected means that subKey must not exist.
+func (self *Conn) SSubCompareAndSwap(key, subKey, expected, value []byte) (swapped bool) {
+	return self.conditional("DHash.SubCompareAndSwap", common.Item{
+		Key:         key,
+		SubKey:      subKey,
+		Expected:    expected,
+		HasExpected: expected != nil,
+		Value:       value,
+		Sync:        true,
+	})
+}
+
+// SubPutIfAbsent will put value under subKey in the sub tree defined by key if subKey doesn't exist, and return whether it did.
+func (self *Conn) SubPutIfAbsent(key, subKey, value []byte) (swapped bool) {
+	return self.SubCompareAndSwap(key, subKey, nil, value)
+}
+
+// SSubPutIfAbsent will put value under subKey in the sub tree defined by key if subKey doesn't exist, and return whether it did.
+func (self *Conn) SSubPutIfAbsent(key, subKey, value []byte) (swapped bool) {
+	return self.SSubCompareAndSwap(key, subKey, nil, value)
+}
+
+// SubDelIfEqual will delete subKey in the sub tree defined by key if the current value under subKey is expected, and return whether it did.
+func (self *Conn) SubDelIfEqual(key, subKey, expected []byte) (deleted bool) {
+	return self.conditional("DHash.SubDelIfEqual", common.Item{
+		Key:         key,
+		SubKey:      subKey,
+		Expected:    expected,
+		HasExpected: expected != nil,
+	})
+}
+
+// SSubDelIfEqual will delete subKey in the sub tree defined by key if the current value under subKey is expected, and return whether it did.
+func (self *Conn) SSubDelIfEqual(key, subKey, expected []byte) (deleted bool) {
+	return self.conditional("DHash.SubDelIfEqual", common.Item{
+		Key:         key,
+		SubKey:      subKey,
+		Expected:    expected,
+		HasExpected: expected != nil,
+		Sync:        true,
+	})
+}
+
+// Incr will add delta to the int64 under key, and return the new value.
+// A missing value is treated as 0, and a value that isn't an int64 encoded like setop.EncodeInt64 will cause an error.
+func (self *Conn) Incr(key []byte, delta int64) (result int64, err error) {
+	return self.incr("DHash.Incr", common.Item{
+		Key:   key,
+		Value: setop.EncodeInt64(delta),
+	})
+}
+
+// SIncr will add delta to the int64 under key, and return the new value.
+// A missing value is treated as 0, and a value that isn't an int64 encoded like setop.EncodeInt64 will cause an error.
+func (self *Conn) SIncr(key []byte, delta int64) (result int64, err error) {
+	return self.incr("DHash.Incr", common.Item{
+		Key:   key,
+		Value: setop.EncodeInt64(delta),
+		Sync:  true,
+	})
+}
+
+// Decr will subtract delta from the int64 under key, and return the new value.
+func (self *Conn) Decr(key []byte, delta int64) (result int64, err error) {
+	return self.Incr(key, -delta)
+}
+
+// SubIncr will add delta to the int64 under subKey in the sub tree defined by key, and return the new value.
+// A missing value is treated as 0, and a value that isn't an int64 encoded like setop.EncodeInt64 will cause an error.
+func (self *Conn) SubIncr(key, subKey []byte, delta int64) (result int64, err error) {
+	return self.incr("DHash.SubIncr", common.Item{
+		Key:    key,
+		SubKey: subKey,
+		Value:  setop.EncodeInt64(delta),
+	})
+}
+
+// SSubIncr will add delta to the int64 under subKey in the sub tree defined by key, and return the new value.
+// A missing value is treated as 0, and a value that isn't an int64 encoded like setop.EncodeInt64 will cause an error.
+func (self *Conn) SSubIncr(key, subKey []byte, delta int64) (result int64, err error) {
+	return self.incr("DHash.SubIncr", common.Item{
+		Key:    key,
+		SubKey: subKey,
+		Value:  setop.EncodeInt64(delta),
+		Sync:   true,
+	})
+}
+
+// SubDecr will subtract delta from the int64 under subKey in the sub tree defined by key, and return the new value.
+func (self *Conn) SubDecr(key, subKey []byte, delta int64) (result int64, err error) {
+	return self.SubIncr(key, subKey, -delta)
+}
+
+// JSONGet will return the JSON encoded field at path, of the form '$.a.b' or '$.a.0', of the JSON document under key, and whether it was found.
+func (self *Conn) JSONGet(key []byte, path string) (value []byte, found bool, err error) {
+	return self.jsonGet(common.JSONOp{
+		Key:  key,
+		Path: path,
+	})
+}
+
+// SubJSONGet will return the JSON encoded field at path, of the form '$.a.b' or '$.a.0', of the JSON document under subKey in the sub tree defined by key,
+// and whether it was found.
+func (self *Conn) SubJSONGet(key, subKey []byte, path string) (value []byte, found bool, err error) {
+	return self.jsonGet(common.JSONOp{
+		Key:    key,
+		SubKey: subKey,
+		Sub:    true,
+		Path:   path,
+	})
+}
+
+// JSONSet will atomically set the field at path, of the form '$.a.b' or '$.a.0', of the JSON document under key to the JSON encoded value,
+// and return the new document. A missing document, or missing objects on the path, will be created.
+func (self *Conn) JSONSet(key []byte, path string, value []byte) (doc []byte, err error) {
+	return self.jsonUpdate("DHash.JSONSet", common.JSONOp{
+		Key:   key,
+		Path:  path,
+		Value: value,
+	})
+}
+
+// SJSONSet will atomically set the field at path, of the form '$.a.b' or '$.a.0', of the JSON document under key to the JSON encoded value,
+// and return the new document. A missing document, or missing objects on the path, will be created.
+func (self *Conn) SJSONSet(key []byte, path string, value []byte) (doc []byte, err error) {
+	return self.jsonUpdate("DHash.JSONSet", common.JSONOp{
+		Key:   key,
+		Path:  path,
+		Value: value,
+		Sync:  true,
+	})
+}
+
+// SubJSONSet will atomically set the field at path, of the form '$.a.b' or '$.a.0', of the JSON document under subKey in the sub tree defined by key
+// to the JSON encoded value, and return the new document. A missing document, or missing objects on the path, will be created.
+func (self *Conn) SubJSONSet(key, subKey []byte, path string, value []byte) (doc []byte, err error) {
+	return self.jsonUpdate("DHash.JSONSet", common.JSONOp{
+		Key:    key,
+		SubKey: subKey,
+		Sub:    true,
+		Path:   path,
+		Value:  value,
+	})
+}
+
+// SSubJSONSet will atomically set the field at path, of the form '$.a.b' or '$.a.0', of the JSON document under subKey in the sub tree defined by key
+// to the JSON encoded value, and return the new document. A missing document, or missing objects on the path, will be created.
+func (self *Conn) SSubJSONSet(key, subKey []byte, path string, value []byte) (doc []byte, err error) {
+	return self.jsonUpdate("DHash.JSONSet", common.JSONOp{
+		Key:    key,
+		SubKey: subKey,
+		Sub:    true,
+		Path:   path,
+		Value:  value,
+		Sync:   true,
+	})
+}
+
+// JSONMerge will atomically apply the JSON merge patch (RFC 7386) patch to the JSON document under key, and return the new document.
+func (self *Conn) JSONMerge(key, patch []byte) (doc []byte, err error) {
+	return self.jsonUpdate("DHash.JSONMerge", common.JSONOp{
+		Key:   key,
+		Value: patch,
+	})
+}
+
+// SJSONMerge will atomically apply the JSON merge patch (RFC 7386) patch to the JSON document under key, and return the new document.
+func (self *Conn) SJSONMerge(key, patch []byte) (doc []byte, err error) {
+	return self.jsonUpdate("DHash.JSONMerge", common.JSONOp{
+		Key:   key,
+		Value: patch,
+		Sync:  true,
+	})
+}
+
+// SubJSONMerge will atomically apply the JSON merge patch (RFC 7386) patch to the JSON document under subKey in the sub tree defined by key,
+// and return the new document.
+func (self *Conn) SubJSONMerge(key, subKey, patch []byte) (doc []byte, err error) {
+	return self.jsonUpdate("DHash.JSONMerge", common.JSONOp{
+		Key:    key,
+		SubKey: subKey,
+		Sub:    true,
+		Value:  patch,
+	})
+}
+
+// SSubJSONMerge will atomically apply the JSON merge patch (RFC 7386) patch to the JSON document under subKey in the sub tree defined by key,
+// and return the new document.
+func (self *Conn) SSubJSONMerge(key, subKey, patch []byte) (doc []byte, err error) {
+	return self.jsonUpdate("DHash.JSONMerge", common.JSONOp{
+		Key:    key,
+		SubKey: subKey,
+		Sub:    true,
+		Value:  patch,
+		Sync:   true,
+	})
+}
+
+// Invoke will run the procedure registered on the nodes as name, with args, on the owner of key, and return what it returned.
+// The writes of the procedure are replicated like those of Put and SubPut.
+func (self *Conn) Invoke(name string, key, args []byte) (result []byte, err error) {
+	return self.invoke(common.Invocation{
+		Name: name,
+		Key:  key,
+		Args: args,
+	})
+}
+
+// SInvoke will run the procedure registered on the nodes as name, with args, on the owner of key, and return what it returned.
+// The writes of the procedure are replicated like those of SPut and SSubPut.
+func (self *Conn) SInvoke(name string, key, args []byte) (result []byte, err error) {
+	return self.invoke(common.Invocation{
+		Name: name,
+		Key:  key,
+		Args: args,
+		Sync: true,
+	})
+}
+
+// Watch will return a channel of the changes to the value under key, and a function that will stop watching and close the channel.
+//
+// The changes are read from the owner of key, and when the ownership moves the watch will continue with the new owner.
+func (self *Conn) Watch(key []byte) (changes chan common.Change, stop func()) {
+	return self.watch(common.Watch{
+		Key: key,
+	})
+}
+
+// WatchSub will return a channel of the changes to the sub tree defined by key, and a function that will stop watching and close the channel.
+//
+// The changes are read from the owner of key, and when the ownership moves the watch will continue with the new owner.
+func (self *Conn) WatchSub(key []byte) (changes chan common.Change, stop func()) {
+	return self.watch(common.Watch{
+		Key: key,
+		Sub: true,
+	})
 }
 
 // Dump will return a channel to send multiple key/value pairs through. When finished, close the channel and #Wait for the *sync.WaitGroup.
@@ -376,6 +1241,57 @@ func (self *Conn) SSubDel(key, subKey []byte) {
 	self.subDel(key, subKey, true)
 }
 
+// SubPrefixDel will remove all values under sub keys starting with prefix from the sub tree defined by key, and return the number of values removed.
+func (self *Conn) SubPrefixDel(key, prefix []byte) (deleted int) {
+	return self.subPrefixDel(key, prefix, false)
+}
+
+// SSubPrefixDel will remove all values under sub keys starting with prefix from the sub tree defined by key, and return the number of values removed.
+func (self *Conn) SSubPrefixDel(key, prefix []byte) (deleted int) {
+	return self.subPrefixDel(key, prefix, true)
+}
+
+// SubDelRange will remove all values between min and max from the sub tree defined by key, and return the number of values removed.
+// A min of nil will remove from the start. A max of nil will remove to the end.
+func (self *Conn) SubDelRange(key, min, max []byte, mininc, maxinc bool) (deleted int) {
+	return self.subDelRange(common.RangeOp{
+		Range: common.Range{
+			Key:    key,
+			Min:    min,
+			Max:    max,
+			MinInc: mininc,
+			MaxInc: maxinc,
+		},
+	})
+}
+
+// SSubDelRange will remove all values between min and max from the sub tree defined by key, and return the number of values removed.
+// A min of nil will remove from the start. A max of nil will remove to the end.
+func (self *Conn) SSubDelRange(key, min, max []byte, mininc, maxinc bool) (deleted int) {
+	return self.subDelRange(common.RangeOp{
+		Range: common.Range{
+			Key:    key,
+			Min:    min,
+			Max:    max,
+			MinInc: mininc,
+			MaxInc: maxinc,
+		},
+		Sync: true,
+	})
+}
+
+// SubDelRangeIndex will remove all values between index min and max from the sub tree defined by key, and return the number of values removed.
+// A min of nil will remove from the start. A max of nil will remove to the end.
+func (self *Conn) SubDelRangeIndex(key []byte, min, max *int) (deleted int) {
+	return self.subDelRange(indexRangeOp(key, min, max, false))
+}
+
+// SSubDelRangeIndex will remove all values between index min and max from the sub tree defined by key, and return the number of values removed.
+// A min of nil will remove from the start. A max of nil will remove to the end.
+func (self *Conn) SSubDelRangeIndex(key []byte, min, max *int) (deleted int) {
+	return self.subDelRange(indexRangeOp(key, min, max, true))
+}
+
 // SDel will remove the byte value under key.
 func (self *Conn) SDel(key []byte) {
 	self.del(key, true)
@@ -534,6 +1450,284 @@ func (self *Conn) Count(key, min, max []byte, mininc, maxinc bool) (result int)
 	return
 }
 
+// SubAggregate will compute fn (common.AggregateSum, common.AggregateMin, common.AggregateMax or common.AggregateAvg) of the values between min and max
+// in the sub tree defined by key, on the owner of the sub tree.
+//
+// The values are decoded using encoding (common.EncodingInt64 or common.EncodingFloat64), and values that aren't 8 bytes long are ignored.
+// The result is encoded using encoding, except averages which are always encoded like setop.EncodeFloat64. The min, max and avg of no values is nil.
+func (self *Conn) SubAggregate(key, min, max []byte, mininc, maxinc bool, fn, encoding string) (result []byte, err error) {
+	a := common.Aggregation{
+		Range: common.Range{
+			Key:    key,
+			Min:    min,
+			Max:    max,
+			MinInc: mininc,
+			MaxInc: maxinc,
+		},
+		Function: fn,
+		Encoding: encoding,
+	}
+	_, _, successor := self.ring.Remotes(key)
+	if err = successor.Call("DHash.SubAggregate", a, &result); err != nil {
+		if _, ok := err.(rpc.ServerError); ok {
+			return
+		}
+		self.removeNode(*successor)
+		return self.SubAggregate(key, min, max, mininc, maxinc, fn, encoding)
+	}
+	return
+}
+
+func (self *Conn) geoAdd(op common.GeoOp) (err error) {
+	op.Acks = self.writeAcks()
+	var x int
+	_, _, successor := self.ring.Remotes(op.Key)
+	if err = successor.Call("DHash.GeoAdd", op, &x); err != nil {
+		if _, ok := err.(rpc.ServerError); ok {
+			return
+		}
+		self.removeNode(*successor)
+		return self.geoAdd(op)
+	}
+	return
+}
+
+// GeoAdd will put member at lat and lon in the geospatial sub tree defined by key, moving it if it already was there.
+//
+// Geospatial sub trees contain two sub keys for each member, and should only be modified using GeoAdd and GeoDel.
+func (self *Conn) GeoAdd(key, member []byte, lat, lon float64) error {
+	return self.geoAdd(common.GeoOp{
+		Key:    key,
+		Member: member,
+		Lat:    lat,
+		Lon:    lon,
+	})
+}
+
+// SGeoAdd will put member at lat and lon in the geospatial sub tree defined by key, moving it if it already was there.
+//
+// Geospatial sub trees contain two sub keys for each member, and should only be modified using GeoAdd and GeoDel.
+func (self *Conn) SGeoAdd(key, member []byte, lat, lon float64) error {
+	return self.geoAdd(common.GeoOp{
+		Key:    key,
+		Member: member,
+		Lat:    lat,
+		Lon:    lon,
+		Sync:   true,
+	})
+}
+func (self *Conn) geoDel(op common.GeoOp) (deleted bool) {
+	op.Acks = self.writeAcks()
+	_, _, successor := self.ring.Remotes(op.Key)
+	if err := successor.Call("DHash.GeoDel", op, &deleted); err != nil {
+		self.removeNode(*successor)
+		return self.geoDel(op)
+	}
+	return
+}
+
+// GeoDel will remove member from the geospatial sub tree defined by key, and return whether it was there.
+func (self *Conn) GeoDel(key, member []byte) bool {
+	return self.geoDel(common.GeoOp{
+		Key:    key,
+		Member: member,
+	})
+}
+
+// SGeoDel will remove member from the geospatial sub tree defined by key, and return whether it was there.
+func (self *Conn) SGeoDel(key, member []byte) bool {
+	return self.geoDel(common.GeoOp{
+		Key:    key,
+		Member: member,
+		Sync:   true,
+	})
+}
+
+// GeoRadius will return the members within radius meters of lat and lon in the geospatial sub tree defined by key, nearest first.
+func (self *Conn) GeoRadius(key []byte, lat, lon, radius float64) (result []common.GeoMember, err error) {
+	q := common.GeoRadiusQuery{
+		Key:    key,
+		Lat:    lat,
+		Lon:    lon,
+		Radius: radius,
+	}
+	_, _, successor := self.ring.Remotes(key)
+	if err = successor.Call("DHash.GeoRadius", q, &result); err != nil {
+		if _, ok := err.(rpc.ServerError); ok {
+			return
+		}
+		self.removeNode(*successor)
+		return self.GeoRadius(key, lat, lon, radius)
+	}
+	return
+}
+
+// GeoBox will return the members between minLat and maxLat, and between minLon and maxLon, in the geospatial sub tree defined by key, in geohash order.
+// If minLon is greater than maxLon the box crosses the 180th meridian.
+func (self *Conn) GeoBox(key []byte, minLat, minLon, maxLat, maxLon float64) (result []common.GeoMember, err error) {
+	q := common.GeoBoxQuery{
+		Key:    key,
+		MinLat: minLat,
+		MinLon: minLon,
+		MaxLat: maxLat,
+		MaxLon: maxLon,
+	}
+	_, _, successor := self.ring.Remotes(key)
+	if err = successor.Call("DHash.GeoBox", q, &result); err != nil {
+		if _, ok := err.(rpc.ServerError); ok {
+			return
+		}
+		self.removeNode(*successor)
+		return self.GeoBox(key, minLat, minLon, maxLat, maxLon)
+	}
+	return
+}
+
+func (self *Conn) sketch(operation string, op common.SketchOp, result interface{}) (err error) {
+	_, _, successor := self.ring.Remotes(op.Key)
+	if err = successor.Call(operation, op, result); err != nil {
+		if _, ok := err.(rpc.ServerError); ok {
+			return
+		}
+		self.removeNode(*successor)
+		return self.sketch(operation, op, result)
+	}
+	return
+}
+func (self *Conn) pfAdd(op common.SketchOp) (changed bool, err error) {
+	op.Acks = self.writeAcks()
+	err = self.sketch("DHash.PFAdd", op, &changed)
+	return
+}
+
+// PFAdd will add elements to the HyperLogLog under key, creating it if missing, and return whether it changed.
+//
+// HyperLogLogs on different replicas are merged when they are synchronized, instead of the newest replacing the oldest.
+func (self *Conn) PFAdd(key []byte, elements ...[]byte) (changed bool, err error) {
+	return self.pfAdd(common.SketchOp{
+		Key:      key,
+		Elements: elements,
+	})
+}
+
+// SPFAdd will add elements to the HyperLogLog under key, creating it if missing, and return whether it changed.
+//
+// HyperLogLogs on different replicas are merged when they are synchronized, instead of the newest replacing the oldest.
+func (self *Conn) SPFAdd(key []byte, elements ...[]byte) (changed bool, err error) {
+	return self.pfAdd(common.SketchOp{
+		Key:      key,
+		Elements: elements,
+		Sync:     true,
+	})
+}
+
+// PFCount will return the estimated number of distinct elements added to the HyperLogLog under key.
+func (self *Conn) PFCount(key []byte) (result int, err error) {
+	err = self.sketch("DHash.PFCount", common.SketchOp{
+		Key: key,
+	}, &result)
+	return
+}
+func (self *Conn) pfMerge(op common.SketchOp) error {
+	op.Acks = self.writeAcks()
+	var x int
+	return self.sketch("DHash.PFMerge", op, &x)
+}
+
+// PFMerge will merge the HyperLogLogs under sources into the HyperLogLog under key, creating it if missing.
+func (self *Conn) PFMerge(key []byte, sources ...[]byte) error {
+	return self.pfMerge(common.SketchOp{
+		Key:     key,
+		Sources: sources,
+	})
+}
+
+// SPFMerge will merge the HyperLogLogs under sources into the HyperLogLog under key, creating it if missing.
+func (self *Conn) SPFMerge(key []byte, sources ...[]byte) error {
+	return self.pfMerge(common.SketchOp{
+		Key:     key,
+		Sources: sources,
+		Sync:    true,
+	})
+}
+func (self *Conn) bfReserve(op common.SketchOp) error {
+	op.Acks = self.writeAcks()
+	var x int
+	return self.sketch("DHash.BFReserve", op, &x)
+}
+
+// BFReserve will create an empty Bloom filter under key with a false positive rate of errorRate when it contains capacity elements.
+// It returns an error if key already has a value.
+func (self *Conn) BFReserve(key []byte, capacity int, errorRate float64) error {
+	return self.bfReserve(common.SketchOp{
+		Key:       key,
+		Capacity:  capacity,
+		ErrorRate: errorRate,
+	})
+}
+
+// SBFReserve will create an empty Bloom filter under key with a false positive rate of errorRate when it contains capacity elements.
+// It returns an error if key already has a value.
+func (self *Conn) SBFReserve(key []byte, capacity int, errorRate float64) error {
+	return self.bfReserve(common.SketchOp{
+		Key:       key,
+		Capacity:  capacity,
+		ErrorRate: errorRate,
+		Sync:      true,
+	})
+}
+func (self *Conn) bfAdd(op common.SketchOp) (added int, err error) {
+	op.Acks = self.writeAcks()
+	err = self.sketch("DHash.BFAdd", op, &added)
+	return
+}
+
+// BFAdd will add elements to the Bloom filter under key, and return how many of them were not probably there already.
+// A missing Bloom filter will be created with common.BloomFilterCapacity and common.BloomFilterErrorRate.
+//
+// Bloom filters of the same size on different replicas are merged when they are synchronized, instead of the newest replacing the oldest.
+func (self *Conn) BFAdd(key []byte, elements ...[]byte) (added int, err error) {
+	return self.bfAdd(common.SketchOp{
+		Key:      key,
+		Elements: elements,
+	})
+}
+
+// SBFAdd will add elements to the Bloom filter under key, and return how many of them were not probably there already.
+// A missing Bloom filter will be created with common.BloomFilterCapacity and common.BloomFilterErrorRate.
+//
+// Bloom filters of the same size on different replicas are merged when they are synchronized, instead of the newest replacing the oldest.
+func (self *Conn) SBFAdd(key []byte, elements ...[]byte) (added int, err error) {
+	return self.bfAdd(common.SketchOp{
+		Key:      key,
+		Elements: elements,
+		Sync:     true,
+	})
+}
+
+// BFExists will return whether each of elements has probably been added to the Bloom filter under key.
+func (self *Conn) BFExists(key []byte, elements ...[]byte) (result []bool, err error) {
+	err = self.sketch("DHash.BFExists", common.SketchOp{
+		Key:      key,
+		Elements: elements,
+	}, &result)
+	return
+}
+
+// SubPrefixCount will count the number of sub keys starting with prefix in the sub tree defined by key.
+func (self *Conn) SubPrefixCount(key, prefix []byte) (result int) {
+	r := common.Range{
+		Key: key,
+		Min: prefix,
+	}
+	_, _, successor := self.ring.Remotes(key)
+	if err := successor.Call("DHash.SubPrefixCount", r, &result); err != nil {
+		self.removeNode(*successor)
+		return self.SubPrefixCount(key, prefix)
+	}
+	return
+}
+
 // MirrorNextIndex will return the key, value and index of the first key after index in the mirror tree of the sub tree defined by key.
 func (self *Conn) MirrorNextIndex(key []byte, index int) (foundKey, foundValue []byte, foundIndex int, existed bool) {
 	data := common.Item{
@@ -768,6 +1962,16 @@ func (self *Conn) Slice(key, min, max []byte, mininc, maxinc bool) (result []com
 	return
 }
 
+// SubPrefixSlice will return the sub keys and values starting with prefix in the sub tree defined by key.
+func (self *Conn) SubPrefixSlice(key, prefix []byte) (result []common.Item) {
+	r := common.Range{
+		Key: key,
+		Min: prefix,
+	}
+	result = self.mergeRecent("DHash.SubPrefixSlice", r, true)
+	return
+}
+
 // SliceLen will return at most maxRes elements after min in the sub tree defined by key.
 // A min of nil will return from the start.
 func (self *Conn) SliceLen(key, min []byte, mininc bool, maxRes int) (result []common.Item) {
@@ -893,6 +2097,103 @@ func (self *Conn) SubGet(key, subKey []byte) (value []byte, existed bool) {
 	return
 }
 
+// MultiGet will return the values under keys, fetched in parallel with one request per node, mapped by key.
+// Keys without values will not be present in the result.
+func (self *Conn) MultiGet(keys [][]byte) (result map[string][]byte) {
+	items := make([]common.Item, len(keys))
+	for index, key := range keys {
+		items[index].Key = key
+	}
+	result = make(map[string][]byte)
+	for _, item := range self.multiFindRecent("DHash.MultiGet", items) {
+		if item.Value != nil && item.Exists {
+			result[string(item.Key)] = item.Value
+		}
+	}
+	return
+}
+
+// indexItems sorts common.IndexItems by field, key and sub key.
+type indexItems []common.IndexItem
+
+func (self indexItems) Len() int {
+	return len(self)
+}
+func (self indexItems) Swap(i, j int) {
+	self[i], self[j] = self[j], self[i]
+}
+func (self indexItems) Less(i, j int) bool {
+	if cmp := bytes.Compare(self[i].Field, self[j].Field); cmp != 0 {
+		return cmp < 0
+	}
+	if cmp := bytes.Compare(self[i].Key, self[j].Key); cmp != 0 {
+		return cmp < 0
+	}
+	return bytes.Compare(self[i].SubKey, self[j].SubKey) < 0
+}
+
+// IndexSlice will return the entries in the secondary index indexName with fields between min and max, fetched in parallel from all nodes.
+// A min of nil will return from the start. A max of nil will return to the end.
+//
+// Every node only indexes the sub trees it holds, so this will always send one request to each node in the cluster.
+// The indexes are in memory on each node, and each node only returns entries for the sub trees it owns, so while nodes join or leave
+// and sub trees migrate, the result depends on which nodes have received them yet, and may miss entries or include stale ones.
+//
+// Secondary indexes are created by setting 'index.NAME' to 'jsonpath:$.FIELD' in the configuration of a sub tree, using SubAddConfiguration.
+func (self *Conn) IndexSlice(indexName string, min, max []byte, mininc, maxinc bool) (result []common.IndexItem) {
+	r := common.Range{
+		Key:    []byte(indexName),
+		Min:    min,
+		Max:    max,
+		MinInc: mininc,
+		MaxInc: maxinc,
+	}
+	nodes := self.ring.Nodes()
+	futures := make([]*rpc.Call, len(nodes))
+	results := make([]*[]common.IndexItem, len(nodes))
+	for index, node := range nodes {
+		var thisResult []common.IndexItem
+		results[index] = &thisResult
+		futures[index] = node.Go("DHash.IndexSlice", r, &thisResult)
+	}
+	newest := make(map[[2]string]int)
+	for index, future := range futures {
+		<-future.Done
+		if future.Error != nil {
+			self.removeNode(nodes[index])
+			return self.IndexSlice(indexName, min, max, mininc, maxinc)
+		}
+		for _, item := range *results[index] {
+			id := [2]string{string(item.Key), string(item.SubKey)}
+			if existing, found := newest[id]; !found {
+				newest[id] = len(result)
+				result = append(result, item)
+			} else if result[existing].Timestamp < item.Timestamp {
+				result[existing] = item
+			}
+		}
+	}
+	sort.Sort(indexItems(result))
+	return
+}
+
+// MultiSubGet will return the values under subKeys in the sub tree defined by key, fetched with one request per replica, mapped by sub key.
+// Sub keys without values will not be present in the result.
+func (self *Conn) MultiSubGet(key []byte, subKeys [][]byte) (result map[string][]byte) {
+	items := make([]common.Item, len(subKeys))
+	for index, subKey := range subKeys {
+		items[index].Key = key
+		items[index].SubKey = subKey
+	}
+	result = make(map[string][]byte)
+	for _, item := range self.multiFindRecent("DHash.MultiSubGet", items) {
+		if item.Value != nil && item.Exists {
+			result[string(item.SubKey)] = item.Value
+		}
+	}
+	return
+}
+
 // Get will return the value under key.
 func (self *Conn) Get(key []byte) (value []byte, existed bool) {
 	data := common.Item{
@@ -989,6 +2290,9 @@ func (self *Conn) Describe() string {
 // Either expr.Op or expr.Code has to be set.
 //
 // If expr.Op is nil expr.Code will be parsed using SetOpParser to provide expr.Op.
+//
+// If all merges in expr.Op are Append, as when expr.Code picks no merges, and all sub trees it reads from have the same valueEncoding,
+// the server will use the common.DefaultMerge of that encoding.
 func (self *Conn) SetExpression(expr setop.SetExpression) (result []setop.SetOpResult) {
 	if expr.Op == nil {
 		expr.Op = setop.MustParse(expr.Code)
@@ -1061,16 +2365,18 @@ func (self *Conn) AddConfiguration(key, value string) {
 // SubAddConfiguration will set a key and value to the configuration of the sub tree defined by key.
 //
 // To mirror a sub tree, set mirrored=yes. To turn off mirroring of a sub tree, set mirrored!=yes.
+//
+// To index the JSON values of a sub tree by a field, set index.NAME=jsonpath:$.FIELD, and query the index using IndexSlice.
 func (self *Conn) SubAddConfiguration(treeKey []byte, key, value string) {
 	conf := common.ConfItem{
 		TreeKey: treeKey,
 		Key:     key,
 		Value:   value,
 	}
-	_, _, successor := self.ring.Remotes(nil)
+	_, _, successor := self.ring.Remotes(treeKey)
 	var x int
 	if err := successor.Call("DHash.SubAddConfiguration", conf, &x); err != nil {
 		self.removeNode(*successor)
-		self.AddConfiguration(key, value)
+		self.SubAddConfiguration(treeKey, key, value)
 	}
 }
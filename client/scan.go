@@ -0,0 +1,236 @@
+package client
+
+import (
+	"bytes"
+	"net/rpc"
+
+	"github.com/zond/god/common"
+)
+
+// DefaultScanPageSize is the number of items a Cursor fetches per request if ScanOptions.PageSize is not set.
+const DefaultScanPageSize = 100
+
+// ScanOptions define how a Cursor iterates.
+type ScanOptions struct {
+	MinInc   bool // whether the min key of the scan is included
+	MaxInc   bool // whether the max key of the scan is included
+	Reverse  bool // whether to iterate from max to min
+	PageSize int  // the number of items to fetch per request
+}
+
+// Cursor iterates over the top level keys of the database, or over the keys of a sub tree, fetching one page at a time.
+//
+// Each page is fetched from the owner of the next key (and its replicas, depending on the read consistency), so a Cursor
+// will continue from the last key it returned even if the ring changes during the scan.
+//
+// A Cursor is not safe for concurrent use.
+type Cursor struct {
+	conn    *Conn
+	key     []byte
+	sub     bool
+	min     []byte
+	max     []byte
+	opts    ScanOptions
+	from    []byte
+	fromInc bool
+	page    []common.Item
+	item    common.Item
+	done    bool
+	err     error
+}
+
+// Scan will return a Cursor over the keys between min and max in the sub tree defined by key, or over the top level keys if key is nil.
+// A min of nil will start at the first key. A max of nil will stop at the last key.
+//
+// Iterate over the result using
+//
+//	cursor := conn.Scan(key, min, max, client.ScanOptions{})
+//	for cursor.Next() {
+//	  item := cursor.Item()
+//	}
+//	if err := cursor.Err(); err != nil {
+//	  // the scan stopped early
+//	}
+func (self *Conn) Scan(key, min, max []byte, opts ScanOptions) (result *Cursor) {
+	if opts.PageSize < 1 {
+		opts.PageSize = DefaultScanPageSize
+	}
+	result = &Cursor{
+		conn:    self,
+		key:     key,
+		sub:     key != nil,
+		min:     min,
+		max:     max,
+		opts:    opts,
+		from:    min,
+		fromInc: opts.MinInc,
+	}
+	if opts.Reverse {
+		result.from, result.fromInc = max, opts.MaxInc
+	}
+	return
+}
+
+// Next will move the Cursor to the next item, and return whether there was one.
+// If fetching the next page fails, Next will return false, and Err will return the error.
+func (self *Cursor) Next() bool {
+	for len(self.page) == 0 {
+		if self.done {
+			return false
+		}
+		self.fetch()
+	}
+	self.item, self.page = self.page[0], self.page[1:]
+	return true
+}
+
+// Item returns the item the Cursor is at.
+func (self *Cursor) Item() common.Item {
+	return self.item
+}
+
+// Err returns the error that stopped the Cursor, if any.
+func (self *Cursor) Err() error {
+	return self.err
+}
+
+// fetch will fetch the next page of items, and move the position of the Cursor past them.
+// If a node fails, it will be removed, and the page fetched again from the same position from the nodes owning it instead.
+// If that isn't possible, or a node returns an error, the error will be stored and the Cursor will be done.
+func (self *Cursor) fetch() {
+	var page []common.Item
+	var more, last bool
+	var next []byte
+	var err error
+	for {
+		if self.sub {
+			page, more, err = self.fetchSub()
+			last = true
+		} else {
+			page, more, next, last, err = self.fetchTop()
+		}
+		if _, ok := err.(rpc.ServerError); err == nil || ok || self.conn.ring.Size() == 0 {
+			break
+		}
+	}
+	if err != nil {
+		self.err, self.done = err, true
+		return
+	}
+	self.page = page
+	if len(page) > 0 {
+		self.from, self.fromInc = page[len(page)-1].Key, false
+	}
+	if !more {
+		if last {
+			self.done = true
+		} else {
+			self.from, self.fromInc = next, !self.opts.Reverse
+		}
+	}
+}
+
+// fetchSub will fetch the next page of items from the owner of the sub tree, and its replicas.
+func (self *Cursor) fetchSub() (page []common.Item, more bool, err error) {
+	r := common.Range{
+		Key:    self.key,
+		Min:    self.min,
+		Max:    self.max,
+		MinInc: self.opts.MinInc,
+		MaxInc: self.opts.MaxInc,
+		Len:    self.opts.PageSize,
+	}
+	operation := "DHash.SliceLen"
+	if self.opts.Reverse {
+		operation = "DHash.ReverseSliceLen"
+		r.Max, r.MaxInc = self.from, self.fromInc
+	} else {
+		r.Min, r.MinInc = self.from, self.fromInc
+	}
+	_, _, owner := self.conn.ring.Remotes(self.key)
+	return self.conn.scanPage(operation, *owner, r, !self.opts.Reverse)
+}
+
+// fetchTop will fetch the next page of top level items from the owner of the keys after (or before, if reverse) the position of the Cursor,
+// and its replicas. next is where the range of that owner ends, and last is whether that is the end of the scan.
+func (self *Cursor) fetchTop() (page []common.Item, more bool, next []byte, last bool, err error) {
+	r := common.Range{
+		Len: self.opts.PageSize,
+	}
+	var owner *common.Remote
+	operation := "DHash.ScanLen"
+	if self.opts.Reverse {
+		operation = "DHash.ReverseScanLen"
+		var at *common.Remote
+		_, at, owner = self.conn.ring.Remotes(self.from)
+		if at != nil && !self.fromInc {
+			owner = at
+		}
+		predecessor, _, _ := self.conn.ring.Remotes(owner.Pos)
+		if cmp := bytes.Compare(self.from, predecessor.Pos); self.from == nil || cmp > 0 || (cmp == 0 && self.fromInc) {
+			next = predecessor.Pos
+		}
+		r.Max, r.MaxInc = self.from, self.fromInc
+		if self.min != nil && (next == nil || bytes.Compare(self.min, next) > 0) {
+			r.Min, r.MinInc, last = self.min, self.opts.MinInc, true
+		} else {
+			r.Min, r.MinInc, last = next, true, next == nil
+		}
+	} else {
+		_, _, owner = self.conn.ring.Remotes(self.from)
+		if bytes.Compare(self.from, owner.Pos) < 0 {
+			next = owner.Pos
+		}
+		r.Min, r.MinInc = self.from, self.fromInc
+		if self.max != nil && (next == nil || bytes.Compare(self.max, next) < 0) {
+			r.Max, r.MaxInc, last = self.max, self.opts.MaxInc, true
+		} else {
+			r.Max, r.MaxInc, last = next, false, next == nil
+		}
+	}
+	page, more, err = self.conn.scanPage(operation, *owner, r, !self.opts.Reverse)
+	return
+}
+
+// scanPage will fetch one page of items using operation from owner and the replicas it is supposed to have, and merge the results.
+// Items beyond the last item of any full page are dropped, since the replica that returned the full page may be missing items there.
+// more is true if any replica returned a full page. A node that fails is removed, and its error returned.
+func (self *Conn) scanPage(operation string, owner common.Remote, r common.Range, up bool) (result []common.Item, more bool, err error) {
+	currentRedundancy := self.readReplicas()
+	futures := make([]*rpc.Call, currentRedundancy)
+	results := make([]*[]common.Item, currentRedundancy)
+	nodes := make(common.Remotes, currentRedundancy)
+	nextSuccessor := &owner
+	for i := 0; i < currentRedundancy; i++ {
+		var thisResult []common.Item
+		nodes[i] = *nextSuccessor
+		results[i] = &thisResult
+		futures[i] = nextSuccessor.Go(operation, r, &thisResult)
+		_, _, nextSuccessor = self.ring.Remotes(nextSuccessor.Pos)
+	}
+	var limit []byte
+	for index, future := range futures {
+		<-future.Done
+		if future.Error != nil {
+			self.removeNode(nodes[index])
+			return nil, false, future.Error
+		}
+		if thisResult := *results[index]; len(thisResult) >= r.Len {
+			last := thisResult[len(thisResult)-1].Key
+			if cmp := bytes.Compare(last, limit); !more || (up && cmp < 0) || (!up && cmp > 0) {
+				limit = last
+			}
+			more = true
+		}
+	}
+	result = common.MergeItems(results, up)
+	if more {
+		for index, item := range result {
+			if cmp := bytes.Compare(item.Key, limit); (up && cmp > 0) || (!up && cmp < 0) {
+				result = result[:index]
+				break
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,223 @@
+package client
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"net/rpc"
+
+	"github.com/zond/god/common"
+)
+
+// ErrTxnConflict is returned by Txn#Commit when a value read in the transaction was changed before the commit,
+// or when another transaction was committing the same keys at the same time.
+var ErrTxnConflict = fmt.Errorf("Transaction conflict")
+
+// Txn is an optimistic transaction over any number of values and sub tree values.
+//
+// Reads in a Txn remember the timestamp of the value they read, and writes are buffered in the Txn until Commit.
+//
+// Commit will use two phase commit to make the owner of each touched key verify that no read value has changed and lock the keys,
+// then make the coordinator of the Txn, the owner of its id, record that it is committed, and then make the owners perform the buffered writes.
+//
+// Prepared keys are locked against other transactions, and all other writes of them, or of the sub trees containing them, fail until the Txn is committed or aborted.
+// Prepared transactions and commit decisions are logged by nodes that log, and survive restarts.
+// Owners that wait too long for the commit ask the coordinator whether the Txn was committed, and make it record that it was aborted if it wasn't,
+// so if the client dies the Txn is either performed or aborted on all owners. Owners that can't reach the coordinator keep their keys locked until they can.
+//
+// A Txn is not safe for concurrent use.
+type Txn struct {
+	conn      *Conn
+	id        []byte
+	reads     []common.TxnOp
+	writes    []common.TxnOp
+	committed bool
+}
+
+// Txn will return a new Txn using this Conn.
+func (self *Conn) Txn() *Txn {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+	return &Txn{
+		conn: self,
+		id:   id,
+	}
+}
+
+// written returns the value of the last buffered write to op.
+func (self *Txn) written(op common.TxnOp) (value []byte, existed, found bool) {
+	for i := len(self.writes) - 1; i >= 0; i-- {
+		w := self.writes[i]
+		if w.Sub == op.Sub && bytes.Compare(w.Key, op.Key) == 0 && (!op.Sub || bytes.Compare(w.SubKey, op.SubKey) == 0) {
+			return w.Value, !w.Del, true
+		}
+	}
+	return
+}
+func (self *Txn) read(operation string, op common.TxnOp) (value []byte, existed bool) {
+	if value, existed, found := self.written(op); found {
+		return value, existed
+	}
+	data := common.Item{
+		Key:    op.Key,
+		SubKey: op.SubKey,
+	}
+	_, _, successor := self.conn.ring.Remotes(op.Key)
+	var result common.Item
+	if err := successor.Call(operation, data, &result); err != nil {
+		self.conn.removeNode(*successor)
+		return self.read(operation, op)
+	}
+	op.Timestamp = result.Timestamp
+	self.reads = append(self.reads, op)
+	if result.Value != nil {
+		value, existed = result.Value, result.Exists
+	}
+	return
+}
+
+// Get will return the value under key, as last written in this Txn or as it is in the database.
+func (self *Txn) Get(key []byte) (value []byte, existed bool) {
+	return self.read("DHash.Get", common.TxnOp{
+		Key: key,
+	})
+}
+
+// SubGet will return the value under subKey in the sub tree defined by key, as last written in this Txn or as it is in the database.
+func (self *Txn) SubGet(key, subKey []byte) (value []byte, existed bool) {
+	return self.read("DHash.SubGet", common.TxnOp{
+		Key:    key,
+		SubKey: subKey,
+		Sub:    true,
+	})
+}
+
+// Put will put value under key when the Txn is committed.
+func (self *Txn) Put(key, value []byte) {
+	self.writes = append(self.writes, common.TxnOp{
+		Key:   key,
+		Value: value,
+	})
+}
+
+// Del will delete key when the Txn is committed.
+func (self *Txn) Del(key []byte) {
+	self.writes = append(self.writes, common.TxnOp{
+		Key: key,
+		Del: true,
+	})
+}
+
+// SubPut will put value under subKey in the sub tree defined by key when the Txn is committed.
+func (self *Txn) SubPut(key, subKey, value []byte) {
+	self.writes = append(self.writes, common.TxnOp{
+		Key:    key,
+		SubKey: subKey,
+		Sub:    true,
+		Value:  value,
+	})
+}
+
+// SubDel will delete subKey from the sub tree defined by key when the Txn is committed.
+func (self *Txn) SubDel(key, subKey []byte) {
+	self.writes = append(self.writes, common.TxnOp{
+		Key:    key,
+		SubKey: subKey,
+		Sub:    true,
+		Del:    true,
+	})
+}
+
+// parts returns the part of this Txn each owner is responsible for.
+func (self *Txn) parts(sync bool) (owners common.Remotes, parts []*common.Txn) {
+	byAddr := make(map[string]*common.Txn)
+	part := func(key []byte) *common.Txn {
+		_, _, successor := self.conn.ring.Remotes(key)
+		if result, found := byAddr[successor.Addr]; found {
+			return result
+		}
+		result := &common.Txn{
+			Id:   self.id,
+			Sync: sync,
+			Acks: self.conn.writeAcks(),
+		}
+		byAddr[successor.Addr] = result
+		owners = append(owners, *successor)
+		parts = append(parts, result)
+		return result
+	}
+	for _, op := range self.reads {
+		p := part(op.Key)
+		p.Reads = append(p.Reads, op)
+	}
+	for _, op := range self.writes {
+		p := part(op.Key)
+		p.Writes = append(p.Writes, op)
+	}
+	return
+}
+func (self *Txn) abort(owners common.Remotes) {
+	var x int
+	for _, owner := range owners {
+		owner.Call("DHash.AbortTxn", self.id, &x)
+	}
+}
+
+// decide will make the coordinator of this Txn record that it is committed, unless a prepared owner has already made it record
+// that it is aborted, and return whether it is committed.
+func (self *Txn) decide() (committed bool, err error) {
+	_, _, successor := self.conn.ring.Remotes(self.id)
+	if err = successor.Call("DHash.DecideTxn", self.id, &committed); err != nil {
+		if _, ok := err.(rpc.ServerError); ok {
+			return
+		}
+		self.conn.removeNode(*successor)
+		return self.decide()
+	}
+	return
+}
+func (self *Txn) commit(sync bool) (err error) {
+	if self.committed {
+		return fmt.Errorf("%x is already committed", self.id)
+	}
+	self.committed = true
+	owners, parts := self.parts(sync)
+	for index, owner := range owners {
+		var prepared bool
+		if err = owner.Call("DHash.PrepareTxn", *parts[index], &prepared); err != nil || !prepared {
+			self.abort(owners[:index+1])
+			if err == nil {
+				err = ErrTxnConflict
+			} else if _, ok := err.(rpc.ServerError); !ok {
+				self.conn.removeNode(owner)
+			}
+			return
+		}
+	}
+	var committed bool
+	if committed, err = self.decide(); err != nil || !committed {
+		self.abort(owners)
+		if err == nil {
+			err = ErrTxnConflict
+		}
+		return
+	}
+	// Owners that miss the commit will ask the coordinator whether the Txn was committed, so failures here don't matter.
+	var x int
+	for _, owner := range owners {
+		owner.Call("DHash.CommitTxn", self.id, &x)
+	}
+	return
+}
+
+// Commit will atomically perform all writes in this Txn, unless any value read in it has changed, in which case ErrTxnConflict is returned.
+func (self *Txn) Commit() error {
+	return self.commit(false)
+}
+
+// SCommit will atomically perform all writes in this Txn, unless any value read in it has changed, in which case ErrTxnConflict is returned.
+func (self *Txn) SCommit() error {
+	return self.commit(true)
+}
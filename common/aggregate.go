@@ -0,0 +1,16 @@
+package common
+
+const (
+	AggregateSum = "sum"
+	AggregateMin = "min"
+	AggregateMax = "max"
+	AggregateAvg = "avg"
+)
+
+// Aggregation is a computation of Function (AggregateSum, AggregateMin, AggregateMax or AggregateAvg) over the values
+// encoded with Encoding (EncodingInt64 or EncodingFloat64) between Range.Min and Range.Max in the sub tree defined by Range.Key.
+type Aggregation struct {
+	Range    Range
+	Function string
+	Encoding string
+}
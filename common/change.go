@@ -0,0 +1,42 @@
+package common
+
+const (
+	ChangePut   = "Put"
+	ChangeDel   = "Del"
+	ChangeClear = "Clear"
+)
+
+// Change describes a change to a value, or to a sub tree, in a dhash.Node.
+type Change struct {
+	Key       []byte
+	SubKey    []byte
+	Sub       bool   // whether the change was to the sub tree under Key instead of the value under Key
+	Type      string // ChangePut, ChangeDel or ChangeClear, where a ChangeClear with a nil Key means that the entire node was cleared
+	OldValue  []byte
+	NewValue  []byte
+	Timestamp int64
+	Seq       int64 // the sequence number of the change in the node that reported it
+}
+
+// Watch defines what changes a watcher wants, and which it has already seen.
+type Watch struct {
+	Key   []byte
+	Sub   bool  // whether to watch the sub tree under Key instead of the value under Key
+	Seq   int64 // the Seq of the last change seen from the watched node, 0 to use After or -1 to just get the current Seq
+	After int64 // the Timestamp of the last change seen, used when starting to watch a node
+}
+
+// WatchResult contains the changes a Watch found, and the state of the node that found them.
+type WatchResult struct {
+	Changes []Change
+	Seq     int64 // the Seq of the last change in the node
+	Time    int64 // the time in the node when the changes were found
+}
+
+// Matches returns whether change is relevant to this Watch.
+func (self Watch) Matches(change Change) bool {
+	if change.Type == ChangeClear && change.Key == nil {
+		return true
+	}
+	return self.Sub == change.Sub && string(self.Key) == string(change.Key)
+}
@@ -183,6 +183,7 @@ type DHashDescription struct {
 	OwnedEntries int
 	HeldEntries  int
 	Load         float64
+	ReadRepairs  int64
 	Nodes        Remotes
 }
 
@@ -198,6 +199,7 @@ func (self DHashDescription) Describe() string {
 		OwnedEntries int
 		HeldEntries  int
 		Load         float64
+		ReadRepairs  int64
 		Nodes        string
 	}{
 		Addr:         self.Addr,
@@ -209,6 +211,7 @@ func (self DHashDescription) Describe() string {
 		OwnedEntries: self.OwnedEntries,
 		HeldEntries:  self.HeldEntries,
 		Load:         self.Load,
+		ReadRepairs:  self.ReadRepairs,
 		Nodes:        fmt.Sprintf("\n%v", self.Nodes.Describe()),
 	})
 }
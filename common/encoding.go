@@ -0,0 +1,80 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/zond/setop"
+)
+
+const (
+	ValueEncoding = "valueEncoding" // the sub tree configuration key defining the encoding of its values
+	KeyEncoding   = "keyEncoding"   // the sub tree configuration key defining the encoding of its keys
+)
+
+const (
+	EncodingInt64   = "int64"   // encoded like setop.EncodeInt64
+	EncodingFloat64 = "float64" // encoded like setop.EncodeFloat64
+	EncodingBigInt  = "bigint"  // encoded like setop.EncodeBigInt
+	EncodingString  = "string"  // UTF-8 strings
+	EncodingJSON    = "json"    // JSON documents
+)
+
+// ValidateEncoding returns an error if b is not encoded using encoding, or if encoding is unknown. An empty encoding accepts anything.
+func ValidateEncoding(encoding string, b []byte) (err error) {
+	switch encoding {
+	case "", EncodingBigInt:
+	case EncodingInt64, EncodingFloat64:
+		if len(b) != 8 {
+			err = fmt.Errorf("%v is not a valid %v, it has to be 8 bytes long", b, encoding)
+		}
+	case EncodingString:
+		if !utf8.Valid(b) {
+			err = fmt.Errorf("%v is not a valid %v", b, encoding)
+		}
+	case EncodingJSON:
+		if !json.Valid(b) {
+			err = fmt.Errorf("%#v is not valid %v", string(b), encoding)
+		}
+	default:
+		err = fmt.Errorf("Unknown encoding %#v", encoding)
+	}
+	return
+}
+
+// DecodeValue returns b decoded using encoding, as an int64, float64, *big.Int, string or json.RawMessage.
+// If encoding is empty, or b can't be decoded, it returns nil.
+func DecodeValue(encoding string, b []byte) (result interface{}) {
+	if b == nil || ValidateEncoding(encoding, b) != nil {
+		return nil
+	}
+	switch encoding {
+	case EncodingInt64:
+		result, _ = setop.DecodeInt64(b)
+	case EncodingFloat64:
+		result, _ = setop.DecodeFloat64(b)
+	case EncodingBigInt:
+		result = setop.DecodeBigInt(b)
+	case EncodingString:
+		result = string(b)
+	case EncodingJSON:
+		result = json.RawMessage(b)
+	}
+	return
+}
+
+// DefaultMerge returns the setop.SetOpMerge that makes sense for values encoded using encoding, and whether there is one.
+func DefaultMerge(encoding string) (merge setop.SetOpMerge, found bool) {
+	switch encoding {
+	case EncodingInt64:
+		return setop.IntegerSum, true
+	case EncodingFloat64:
+		return setop.FloatSum, true
+	case EncodingBigInt:
+		return setop.BigIntAdd, true
+	case EncodingString:
+		return setop.ConCat, true
+	}
+	return
+}
@@ -0,0 +1,47 @@
+package common
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/zond/setop"
+)
+
+func TestEncodings(t *testing.T) {
+	for _, test := range []struct {
+		encoding string
+		value    []byte
+		decoded  interface{}
+	}{
+		{EncodingInt64, setop.EncodeInt64(-4), int64(-4)},
+		{EncodingFloat64, setop.EncodeFloat64(1.5), float64(1.5)},
+		{EncodingBigInt, setop.EncodeBigInt(big.NewInt(1000)), big.NewInt(1000)},
+		{EncodingString, []byte("hello"), "hello"},
+		{EncodingJSON, []byte(`{"a":1}`), json.RawMessage(`{"a":1}`)},
+	} {
+		if err := ValidateEncoding(test.encoding, test.value); err != nil {
+			t.Errorf("%v should be a valid %v, but got %v", test.value, test.encoding, err)
+		}
+		if decoded := DecodeValue(test.encoding, test.value); !reflect.DeepEqual(decoded, test.decoded) {
+			t.Errorf("%v should decode to %v, but got %v", test.value, test.decoded, decoded)
+		}
+	}
+	for _, test := range []struct {
+		encoding string
+		value    []byte
+	}{
+		{EncodingInt64, []byte("abc")},
+		{EncodingString, []byte{0xff}},
+		{EncodingJSON, []byte("{")},
+		{"base64", []byte("abc")},
+	} {
+		if err := ValidateEncoding(test.encoding, test.value); err == nil {
+			t.Errorf("%v should not be a valid %v", test.value, test.encoding)
+		}
+		if decoded := DecodeValue(test.encoding, test.value); decoded != nil {
+			t.Errorf("%v should not decode as %v, but got %v", test.value, test.encoding, decoded)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package common
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	// GeoStep is the number of bits of latitude, and of longitude, in a geohash.
+	GeoStep = 26
+	// GeoEarthRadius is the radius of the earth in meters used by GeoDistance.
+	GeoEarthRadius = 6372797.560856
+	// geoMaxCells is the max number of geohash cells used to cover a box.
+	geoMaxCells = 16
+)
+
+// GeoOp is an addition or removal of Member, at Lat and Lon, in the geospatial sub tree defined by Key.
+type GeoOp struct {
+	Key    []byte
+	Member []byte
+	Lat    float64
+	Lon    float64
+	Sync   bool
+	Acks   int
+}
+
+// GeoRadiusQuery is a search for the members within Radius meters of Lat and Lon in the geospatial sub tree defined by Key.
+type GeoRadiusQuery struct {
+	Key    []byte
+	Lat    float64
+	Lon    float64
+	Radius float64
+}
+
+// GeoBoxQuery is a search for the members between MinLat and MaxLat, and between MinLon and MaxLon, in the geospatial sub tree defined by Key.
+// If MinLon is greater than MaxLon the box crosses the 180th meridian.
+type GeoBoxQuery struct {
+	Key    []byte
+	MinLat float64
+	MinLon float64
+	MaxLat float64
+	MaxLon float64
+}
+
+// GeoMember is a member found by a geospatial search. Distance is the distance in meters from the center of a radius search.
+type GeoMember struct {
+	Member   []byte
+	Lat      float64
+	Lon      float64
+	Distance float64
+}
+
+// GeoHashRange is the range of geohashes from Min, inclusive, to Max, exclusive.
+type GeoHashRange struct {
+	Min uint64
+	Max uint64
+}
+
+// ValidateGeo returns an error unless lat is between -90 and 90 and lon between -180 and 180.
+func ValidateGeo(lat, lon float64) error {
+	if !(lat >= -90 && lat <= 90) || !(lon >= -180 && lon <= 180) {
+		return fmt.Errorf("%v,%v is not a valid latitude,longitude", lat, lon)
+	}
+	return nil
+}
+
+func geoIndex(degrees, max float64) uint64 {
+	index := uint64((degrees + max) / (2 * max) * (1 << GeoStep))
+	if index >= 1<<GeoStep {
+		index = 1<<GeoStep - 1
+	}
+	return index
+}
+
+func geoInterleave(latIndex, lonIndex uint64, step uint) (result uint64) {
+	for bit := int(step) - 1; bit >= 0; bit-- {
+		result = result<<2 | (lonIndex>>uint(bit)&1)<<1 | latIndex>>uint(bit)&1
+	}
+	return
+}
+
+// GeoHash returns the geohash of lat and lon, with GeoStep bits of each interleaved so that nearby positions tend to have nearby geohashes.
+func GeoHash(lat, lon float64) uint64 {
+	return geoInterleave(geoIndex(lat, 90), geoIndex(lon, 180), GeoStep)
+}
+
+// GeoDistance returns the distance in meters between lat1,lon1 and lat2,lon2 along the surface of the earth.
+func GeoDistance(lat1, lon1, lat2, lon2 float64) float64 {
+	lat1r, lat2r := lat1*math.Pi/180, lat2*math.Pi/180
+	u := math.Sin((lat2r - lat1r) / 2)
+	v := math.Sin((lon2 - lon1) * math.Pi / 180 / 2)
+	return 2 * GeoEarthRadius * math.Asin(math.Sqrt(u*u+math.Cos(lat1r)*math.Cos(lat2r)*v*v))
+}
+
+// GeoBoxContains returns whether lat,lon is inside the box, which crosses the 180th meridian if minLon is greater than maxLon.
+func GeoBoxContains(minLat, minLon, maxLat, maxLon, lat, lon float64) bool {
+	if lat < minLat || lat > maxLat {
+		return false
+	}
+	if minLon > maxLon {
+		return lon >= minLon || lon <= maxLon
+	}
+	return lon >= minLon && lon <= maxLon
+}
+
+// GeoBoxCover returns the sorted and merged ranges of geohashes that cover the box, which crosses the 180th meridian if minLon is greater than maxLon.
+func GeoBoxCover(minLat, minLon, maxLat, maxLon float64) (result []GeoHashRange) {
+	if minLon > maxLon {
+		return geoMerge(append(geoCover(minLat, minLon, maxLat, 180), geoCover(minLat, -180, maxLat, maxLon)...))
+	}
+	return geoMerge(geoCover(minLat, minLon, maxLat, maxLon))
+}
+
+// GeoRadiusCover returns the sorted and merged ranges of geohashes that cover the circle of radius meters around lat,lon.
+func GeoRadiusCover(lat, lon, radius float64) (result []GeoHashRange) {
+	deltaLat := radius / GeoEarthRadius * 180 / math.Pi
+	minLat, maxLat := math.Max(lat-deltaLat, -90), math.Min(lat+deltaLat, 90)
+	if minLat == -90 || maxLat == 90 {
+		return GeoBoxCover(minLat, -180, maxLat, 180)
+	}
+	deltaLon := deltaLat / math.Cos(math.Max(-minLat, maxLat)*math.Pi/180)
+	if deltaLon >= 180 {
+		return GeoBoxCover(minLat, -180, maxLat, 180)
+	}
+	minLon, maxLon := lon-deltaLon, lon+deltaLon
+	if minLon < -180 {
+		minLon += 360
+	}
+	if maxLon > 180 {
+		maxLon -= 360
+	}
+	return GeoBoxCover(minLat, minLon, maxLat, maxLon)
+}
+
+// geoCover returns the ranges of the geohash cells, at the finest step using at most geoMaxCells cells, that cover the box.
+func geoCover(minLat, minLon, maxLat, maxLon float64) (result []GeoHashRange) {
+	minLatIndex, maxLatIndex := geoIndex(minLat, 90), geoIndex(maxLat, 90)
+	minLonIndex, maxLonIndex := geoIndex(minLon, 180), geoIndex(maxLon, 180)
+	step := uint(GeoStep)
+	for ; step > 0; step-- {
+		shift := GeoStep - step
+		if ((maxLatIndex>>shift)-(minLatIndex>>shift)+1)*((maxLonIndex>>shift)-(minLonIndex>>shift)+1) <= geoMaxCells {
+			break
+		}
+	}
+	shift := GeoStep - step
+	for latIndex := minLatIndex >> shift; latIndex <= maxLatIndex>>shift; latIndex++ {
+		for lonIndex := minLonIndex >> shift; lonIndex <= maxLonIndex>>shift; lonIndex++ {
+			cell := geoInterleave(latIndex, lonIndex, step)
+			result = append(result, GeoHashRange{
+				Min: cell << (2 * shift),
+				Max: (cell + 1) << (2 * shift),
+			})
+		}
+	}
+	return
+}
+
+type geoHashRanges []GeoHashRange
+
+func (self geoHashRanges) Len() int           { return len(self) }
+func (self geoHashRanges) Less(i, j int) bool { return self[i].Min < self[j].Min }
+func (self geoHashRanges) Swap(i, j int)      { self[i], self[j] = self[j], self[i] }
+
+func geoMerge(ranges []GeoHashRange) (result []GeoHashRange) {
+	sort.Sort(geoHashRanges(ranges))
+	for _, r := range ranges {
+		if len(result) > 0 && r.Min <= result[len(result)-1].Max {
+			if r.Max > result[len(result)-1].Max {
+				result[len(result)-1].Max = r.Max
+			}
+		} else {
+			result = append(result, r)
+		}
+	}
+	return
+}
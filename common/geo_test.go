@@ -0,0 +1,56 @@
+package common
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func geoCovered(ranges []GeoHashRange, hash uint64) bool {
+	for _, r := range ranges {
+		if hash >= r.Min && hash < r.Max {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGeoDistance(t *testing.T) {
+	if d := GeoDistance(48.8566, 2.3522, 51.5074, -0.1278); math.Abs(d-343500) > 1000 {
+		t.Errorf("wanted Paris to be about 343.5km from London, but got %vm", d)
+	}
+	if d := GeoDistance(0, 179.5, 0, -179.5); math.Abs(d-111200) > 500 {
+		t.Errorf("wanted one degree across the 180th meridian to be about 111.2km, but got %vm", d)
+	}
+}
+
+func TestGeoCover(t *testing.T) {
+	for i := 0; i < 200; i++ {
+		lat, lon := rand.Float64()*170-85, rand.Float64()*360-180
+		radius := math.Pow(10, rand.Float64()*6+1)
+		ranges := GeoRadiusCover(lat, lon, radius)
+		if len(ranges) > 2*geoMaxCells {
+			t.Errorf("wanted at most %v ranges, but got %v", 2*geoMaxCells, len(ranges))
+		}
+		for j := 0; j < 100; j++ {
+			// a point at a random bearing, strictly inside the radius.
+			distance, bearing := rand.Float64()*radius*0.99/GeoEarthRadius, rand.Float64()*2*math.Pi
+			latr, lonr := lat*math.Pi/180, lon*math.Pi/180
+			plat := math.Asin(math.Sin(latr)*math.Cos(distance) + math.Cos(latr)*math.Sin(distance)*math.Cos(bearing))
+			plon := lonr + math.Atan2(math.Sin(bearing)*math.Sin(distance)*math.Cos(latr), math.Cos(distance)-math.Sin(latr)*math.Sin(plat))
+			plat, plon = plat*180/math.Pi, math.Remainder(plon*180/math.Pi, 360)
+			if !geoCovered(ranges, GeoHash(plat, plon)) {
+				t.Fatalf("%v,%v is %vm from %v,%v, but not covered by %v", plat, plon, GeoDistance(lat, lon, plat, plon), lat, lon, ranges)
+			}
+		}
+	}
+	ranges := GeoBoxCover(10, 170, 20, -170)
+	for _, pos := range [][2]float64{{10, 170}, {15, 180}, {20, -170}, {12, -175}} {
+		if !geoCovered(ranges, GeoHash(pos[0], pos[1])) {
+			t.Errorf("%v is inside the box, but not covered by %v", pos, ranges)
+		}
+	}
+	if GeoBoxContains(10, 170, 20, -170, 15, 0) {
+		t.Errorf("15,0 is not inside the box crossing the 180th meridian")
+	}
+}
@@ -4,3 +4,12 @@ type Index struct {
 	N       int
 	Existed bool
 }
+
+// IndexItem is an entry in a secondary index, containing the indexed Field, and the Key of the sub tree, SubKey, Value and Timestamp it indexes.
+type IndexItem struct {
+	Field     []byte
+	Key       []byte
+	SubKey    []byte
+	Value     []byte
+	Timestamp int64
+}
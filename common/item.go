@@ -1,12 +1,17 @@
 package common
 
 type Item struct {
-	Key       []byte
-	SubKey    []byte
-	Value     []byte
-	Exists    bool
-	Timestamp int64
-	TTL       int
-	Index     int
-	Sync      bool
+	Key         []byte
+	SubKey      []byte
+	Value       []byte
+	Exists      bool
+	Timestamp   int64
+	TTL         int
+	Index       int
+	Sync        bool
+	Lifetime    int64  // if not 0, a put or read Value will expire this many nanoseconds after Timestamp
+	Expected    []byte // the value a conditional operation expects the current value to be, where nil means that it expects no value unless HasExpected
+	HasExpected bool   // if true, a nil Expected means the empty value, since gob decodes empty byte slices as nil
+	Acks        int    // if more than 1, the number of replicas, including the receiving one, that must have a write before it returns
+	Mergeable   bool   // if true, the Value was written by a sketch operation like PFAdd, and replicas merge it with instead of replacing it by other mergeable values
 }
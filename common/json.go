@@ -0,0 +1,12 @@
+package common
+
+// JSONOp is a read or update of a field in a JSON document stored as a value, or as a sub tree value.
+type JSONOp struct {
+	Key    []byte
+	SubKey []byte
+	Sub    bool   // whether SubKey in the sub tree Key is meant, instead of the value under Key
+	Path   string // the path of the field, of the form '$.a.b' or '$.a.0', where '$' is the whole document
+	Value  []byte // the JSON encoded value to set, or the JSON merge patch to apply
+	Sync   bool
+	Acks   int // if more than 1, the number of replicas, including the receiving one, that must have a write before it returns
+}
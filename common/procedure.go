@@ -0,0 +1,10 @@
+package common
+
+// Invocation is a call to a procedure registered on the dhash.Nodes, to be run on the owner of Key.
+type Invocation struct {
+	Name string
+	Key  []byte
+	Args []byte
+	Sync bool
+	Acks int // if more than 1, the number of replicas, including the receiving one, that must have each write before the invocation returns
+}
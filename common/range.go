@@ -10,3 +10,19 @@ type Range struct {
 	MaxIndex int
 	Len      int
 }
+
+// SnapshotRange is a Range of the keys in the snapshot named Snapshot, taken by a node for a backup.
+type SnapshotRange struct {
+	Snapshot []byte
+	Range    Range
+}
+
+// RangeOp is a deletion of the keys in Range, or of the indices in Range if Index is true, in the sub tree defined by Range.Key.
+type RangeOp struct {
+	Range     Range
+	Index     bool
+	TTL       int
+	Timestamp int64
+	Sync      bool
+	Acks      int
+}
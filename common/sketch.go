@@ -0,0 +1,224 @@
+package common
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/zond/god/murmur"
+)
+
+const (
+	// HyperLogLogPrecision is the number of hash bits used to select a register, making the standard error of a HyperLogLog about 1.6%.
+	HyperLogLogPrecision = 12
+	// BloomFilterCapacity and BloomFilterErrorRate define the size of a Bloom filter created by adding to a missing one.
+	BloomFilterCapacity  = 10000
+	BloomFilterErrorRate = 0.01
+)
+
+var (
+	hyperLogLogHeader = []byte("\x00hll")
+	bloomFilterHeader = []byte("\x00blm")
+)
+
+// SketchOp is an update of, or a query to, the HyperLogLog or Bloom filter under Key.
+type SketchOp struct {
+	Key       []byte
+	Elements  [][]byte
+	Sources   [][]byte // the keys of the HyperLogLogs to merge into the one under Key
+	Capacity  int      // the number of elements a reserved Bloom filter should hold
+	ErrorRate float64  // the false positive rate of a reserved Bloom filter when it holds Capacity elements
+	Sync      bool
+	Acks      int
+}
+
+func sketchHash(element []byte) (h1, h2 uint64) {
+	hash := murmur.HashBytes(element)
+	return binary.BigEndian.Uint64(hash), binary.BigEndian.Uint64(hash[8:])
+}
+
+// HyperLogLog is a value estimating the number of distinct elements added to it.
+//
+// Two HyperLogLogs under the same key are merged by radix.Sync, instead of the newest replacing the oldest.
+type HyperLogLog []byte
+
+// NewHyperLogLog returns an empty HyperLogLog.
+func NewHyperLogLog() (result HyperLogLog) {
+	result = make(HyperLogLog, len(hyperLogLogHeader)+1+1<<HyperLogLogPrecision)
+	copy(result, hyperLogLogHeader)
+	result[len(hyperLogLogHeader)] = HyperLogLogPrecision
+	return
+}
+
+// ParseHyperLogLog returns b as a HyperLogLog, or an error if b is not a HyperLogLog.
+func ParseHyperLogLog(b []byte) (result HyperLogLog, err error) {
+	if len(b) <= len(hyperLogLogHeader) || !bytes.HasPrefix(b, hyperLogLogHeader) || b[len(hyperLogLogHeader)] < 4 || b[len(hyperLogLogHeader)] > 18 || len(b) != len(hyperLogLogHeader)+1+1<<b[len(hyperLogLogHeader)] {
+		err = fmt.Errorf("The value is not a HyperLogLog")
+		return
+	}
+	result = HyperLogLog(b)
+	return
+}
+func (self HyperLogLog) registers() []byte {
+	return self[len(hyperLogLogHeader)+1:]
+}
+
+// Add will add element to the HyperLogLog, and return whether it changed.
+func (self HyperLogLog) Add(element []byte) (changed bool) {
+	precision := uint(self[len(hyperLogLogHeader)])
+	hash, _ := sketchHash(element)
+	index := hash >> (64 - precision)
+	rank := byte(1)
+	for rest := hash << precision; rest&(1<<63) == 0 && rank <= byte(64-precision); rest <<= 1 {
+		rank++
+	}
+	if registers := self.registers(); rank > registers[index] {
+		registers[index] = rank
+		changed = true
+	}
+	return
+}
+
+// Count returns the estimated number of distinct elements added to the HyperLogLog.
+func (self HyperLogLog) Count() int {
+	registers := self.registers()
+	m := float64(len(registers))
+	sum := 0.0
+	zeros := 0
+	for _, register := range registers {
+		sum += math.Pow(2, -float64(register))
+		if register == 0 {
+			zeros++
+		}
+	}
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return int(estimate + 0.5)
+}
+
+// Merge will make the HyperLogLog count the elements added to other as well, and return whether it changed.
+// HyperLogLogs with different precisions can't be merged.
+func (self HyperLogLog) Merge(other HyperLogLog) (changed bool, err error) {
+	if len(self) != len(other) {
+		err = fmt.Errorf("Can't merge HyperLogLogs with different precisions")
+		return
+	}
+	registers := self.registers()
+	for index, register := range other.registers() {
+		if register > registers[index] {
+			registers[index] = register
+			changed = true
+		}
+	}
+	return
+}
+
+// BloomFilter is a value that can tell if an element has probably been added to it, or definitely not.
+//
+// Two BloomFilters of the same size under the same key are merged by radix.Sync, instead of the newest replacing the oldest.
+type BloomFilter []byte
+
+// NewBloomFilter returns an empty BloomFilter with a false positive rate of errorRate when it contains capacity elements.
+func NewBloomFilter(capacity int, errorRate float64) (result BloomFilter, err error) {
+	if capacity < 1 || !(errorRate > 0 && errorRate < 1) {
+		err = fmt.Errorf("Can't create a Bloom filter with capacity %v and error rate %v", capacity, errorRate)
+		return
+	}
+	bits := math.Ceil(-float64(capacity) * math.Log(errorRate) / (math.Ln2 * math.Ln2))
+	hashes := math.Ceil(bits / float64(capacity) * math.Ln2)
+	if hashes > 255 {
+		hashes = 255
+	}
+	result = make(BloomFilter, len(bloomFilterHeader)+1+int(math.Ceil(bits/8)))
+	copy(result, bloomFilterHeader)
+	result[len(bloomFilterHeader)] = byte(hashes)
+	return
+}
+
+// ParseBloomFilter returns b as a BloomFilter, or an error if b is not a BloomFilter.
+func ParseBloomFilter(b []byte) (result BloomFilter, err error) {
+	if len(b) <= len(bloomFilterHeader)+1 || !bytes.HasPrefix(b, bloomFilterHeader) || b[len(bloomFilterHeader)] == 0 {
+		err = fmt.Errorf("The value is not a Bloom filter")
+		return
+	}
+	result = BloomFilter(b)
+	return
+}
+func (self BloomFilter) bits() []byte {
+	return self[len(bloomFilterHeader)+1:]
+}
+
+// each will call f with the byte index and bit mask of each bit element hashes to.
+func (self BloomFilter) each(element []byte, f func(index int, mask byte)) {
+	bits := uint64(len(self.bits()) * 8)
+	h1, h2 := sketchHash(element)
+	for i := uint64(0); i < uint64(self[len(bloomFilterHeader)]); i++ {
+		bit := (h1 + i*h2) % bits
+		f(int(bit/8), 1<<(bit%8))
+	}
+}
+
+// Add will add element to the BloomFilter, and return whether it was not already probably there.
+func (self BloomFilter) Add(element []byte) (added bool) {
+	bits := self.bits()
+	self.each(element, func(index int, mask byte) {
+		if bits[index]&mask == 0 {
+			bits[index] |= mask
+			added = true
+		}
+	})
+	return
+}
+
+// Exists returns whether element has probably been added to the BloomFilter.
+func (self BloomFilter) Exists(element []byte) (result bool) {
+	bits := self.bits()
+	result = true
+	self.each(element, func(index int, mask byte) {
+		if bits[index]&mask == 0 {
+			result = false
+		}
+	})
+	return
+}
+
+// Merge will make the BloomFilter contain the elements added to other as well, and return whether it changed.
+// BloomFilters of different sizes can't be merged.
+func (self BloomFilter) Merge(other BloomFilter) (changed bool, err error) {
+	if len(self) != len(other) || self[len(bloomFilterHeader)] != other[len(bloomFilterHeader)] {
+		err = fmt.Errorf("Can't merge Bloom filters of different sizes")
+		return
+	}
+	bits := self.bits()
+	for index, b := range other.bits() {
+		if bits[index]|b != bits[index] {
+			bits[index] |= b
+			changed = true
+		}
+	}
+	return
+}
+
+// MergeValues returns the merge of a and b, and true, if they are both HyperLogLogs or BloomFilters that can be merged.
+// Otherwise it returns false.
+func MergeValues(a, b []byte) (result []byte, ok bool) {
+	if ha, err := ParseHyperLogLog(a); err == nil {
+		if hb, err := ParseHyperLogLog(b); err == nil {
+			merged := append(HyperLogLog{}, ha...)
+			if _, err := merged.Merge(hb); err == nil {
+				return merged, true
+			}
+		}
+	} else if ba, err := ParseBloomFilter(a); err == nil {
+		if bb, err := ParseBloomFilter(b); err == nil {
+			merged := append(BloomFilter{}, ba...)
+			if _, err := merged.Merge(bb); err == nil {
+				return merged, true
+			}
+		}
+	}
+	return
+}
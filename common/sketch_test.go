@@ -0,0 +1,69 @@
+package common
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	hll1, hll2 := NewHyperLogLog(), NewHyperLogLog()
+	for i := 0; i < 100000; i++ {
+		hll1.Add([]byte(fmt.Sprint(i)))
+		hll2.Add([]byte(fmt.Sprint(i + 50000)))
+	}
+	if hll1.Add([]byte("0")) {
+		t.Errorf("adding an element twice should not change the HyperLogLog")
+	}
+	if count := hll1.Count(); count < 95000 || count > 105000 {
+		t.Errorf("wanted about 100000 but got %v", count)
+	}
+	if changed, err := hll1.Merge(hll2); err != nil || !changed {
+		t.Errorf("wanted a change but got %v, %v", changed, err)
+	}
+	if count := hll1.Count(); count < 142500 || count > 157500 {
+		t.Errorf("wanted about 150000 but got %v", count)
+	}
+	if count := NewHyperLogLog().Count(); count != 0 {
+		t.Errorf("wanted 0 but got %v", count)
+	}
+	if _, err := ParseHyperLogLog([]byte("hello")); err == nil {
+		t.Errorf("hello should not be a HyperLogLog")
+	}
+}
+
+func TestBloomFilter(t *testing.T) {
+	bf, err := NewBloomFilter(1000, 0.01)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	for i := 0; i < 1000; i++ {
+		bf.Add([]byte(fmt.Sprint(i)))
+	}
+	falsePositives := 0
+	for i := 0; i < 1000; i++ {
+		if !bf.Exists([]byte(fmt.Sprint(i))) {
+			t.Fatalf("%v should exist", i)
+		}
+		if bf.Exists([]byte(fmt.Sprint(i + 1000))) {
+			falsePositives++
+		}
+	}
+	if falsePositives > 30 {
+		t.Errorf("wanted about 10 false positives but got %v", falsePositives)
+	}
+	other, _ := NewBloomFilter(1000, 0.01)
+	other.Add([]byte("other"))
+	if merged, ok := MergeValues(bf, other); !ok || !BloomFilter(merged).Exists([]byte("other")) || !BloomFilter(merged).Exists([]byte("0")) {
+		t.Errorf("wanted the merged filter to contain both filters")
+	}
+	if bf.Exists([]byte("other")) {
+		t.Errorf("MergeValues should not modify its arguments")
+	}
+	small, _ := NewBloomFilter(10, 0.01)
+	if _, ok := MergeValues(bf, small); ok {
+		t.Errorf("filters of different sizes should not be mergeable")
+	}
+	if _, ok := MergeValues(bf, NewHyperLogLog()); ok {
+		t.Errorf("a filter and a HyperLogLog should not be mergeable")
+	}
+}
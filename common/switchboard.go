@@ -56,8 +56,8 @@ func (self *Switchboard) Call(addr, service string, args, reply interface{}) (er
 			self.lock.Lock()
 			delete(self.clients, addr)
 			self.lock.Unlock()
+			err = self.Call(addr, service, args, reply)
 		}
-		err = self.Call(addr, service, args, reply)
 	}
 	return
 }
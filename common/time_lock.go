@@ -29,6 +29,15 @@ func (self *TimeLock) Lock() {
 	atomic.StoreInt64(&self.lockdurations[self.index], -self.locktimes[self.index])
 }
 
+func (self *TimeLock) TryLock() bool {
+	if !self.lock.TryLock() {
+		return false
+	}
+	atomic.StoreInt64(&self.locktimes[self.index], time.Now().UnixNano())
+	atomic.StoreInt64(&self.lockdurations[self.index], -self.locktimes[self.index])
+	return true
+}
+
 func (self *TimeLock) Unlock() {
 	atomic.AddInt64(&self.lockdurations[self.index], time.Now().UnixNano())
 	self.index = (self.index + 1) % logsize
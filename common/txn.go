@@ -0,0 +1,20 @@
+package common
+
+// TxnOp is a read or write of a value, or a sub tree value, in a transaction.
+type TxnOp struct {
+	Key       []byte
+	SubKey    []byte
+	Sub       bool // whether SubKey in the sub tree Key is meant, instead of the value under Key
+	Value     []byte
+	Del       bool  // whether a write is a delete instead of a put
+	Timestamp int64 // for reads, the timestamp the value had when it was read
+}
+
+// Txn is the part of a transaction that one owner node has to prepare and commit.
+type Txn struct {
+	Id     []byte
+	Reads  []TxnOp
+	Writes []TxnOp
+	Sync   bool
+	Acks   int // if more than 1, the number of replicas, including the owner, that must have each write before the commit returns
+}
@@ -3,11 +3,13 @@ package dhash
 import (
 	"bytes"
 	"fmt"
+	"net/rpc"
 	"sync/atomic"
 	"time"
 
 	"github.com/zond/god/client"
 	"github.com/zond/god/common"
+	"github.com/zond/god/radix"
 	"github.com/zond/setop"
 )
 
@@ -23,6 +25,7 @@ func (self *Node) Description() common.DHashDescription {
 		OwnedEntries: self.Owned(),
 		HeldEntries:  self.tree.RealSize(),
 		Load:         self.tree.Load(),
+		ReadRepairs:  atomic.LoadInt64(&self.readRepairs),
 		Nodes:        self.node.GetNodes(),
 	}
 }
@@ -41,7 +44,31 @@ func (self *Node) client() *client.Conn {
 }
 func (self *Node) Get(data common.Item, result *common.Item) error {
 	*result = data
-	result.Value, result.Timestamp, result.Exists = self.tree.Get(data.Key)
+	var exp int64
+	result.Value, result.Timestamp, exp, result.Mergeable, result.Exists = self.tree.GetMergeable(data.Key)
+	result.Lifetime = lifetime(result.Timestamp, exp)
+	return nil
+}
+
+// Repair will put data.Value, or a tombstone if !data.Exists, under data.Key if data.Timestamp is newer than what this node has,
+// and count it as a read repair if it did.
+func (self *Node) Repair(data common.Item) error {
+	if _, current, _ := self.tree.Get(data.Key); current < data.Timestamp {
+		if self.tree.PutTimestamp(radix.Rip(data.Key), data.Value, data.Exists, current, data.Timestamp, expires(data), data.Mergeable && data.Exists) {
+			atomic.AddInt64(&self.readRepairs, 1)
+		}
+	}
+	return nil
+}
+
+// SubRepair will put data.Value, or a tombstone if !data.Exists, under data.SubKey in the sub tree data.Key if data.Timestamp is newer than what this node has,
+// and count it as a read repair if it did.
+func (self *Node) SubRepair(data common.Item) error {
+	if _, current, _ := self.tree.SubGet(data.Key, data.SubKey); current < data.Timestamp {
+		if self.tree.SubPutTimestamp(radix.Rip(data.Key), radix.Rip(data.SubKey), data.Value, data.Exists, current, data.Timestamp, expires(data)) {
+			atomic.AddInt64(&self.readRepairs, 1)
+		}
+	}
 	return nil
 }
 func (self *Node) Prev(data common.Item, result *common.Item) error {
@@ -66,6 +93,19 @@ func (self *Node) Count(r common.Range, result *int) error {
 	*result = self.tree.SubSizeBetween(r.Key, r.Min, r.Max, r.MinInc, r.MaxInc)
 	return nil
 }
+
+// SubAggregate will set result to a.Function of the values encoded with a.Encoding between a.Range.Min and a.Range.Max
+// in the sub tree defined by a.Range.Key.
+func (self *Node) SubAggregate(a common.Aggregation, result *[]byte) (err error) {
+	*result, err = self.tree.SubAggregateBetween(a.Range.Key, a.Range.Min, a.Range.Max, a.Range.MinInc, a.Range.MaxInc).Result(a.Function, a.Encoding)
+	return
+}
+
+// SubPrefixCount will set result to the number of sub keys starting with r.Min in the sub tree defined by r.Key.
+func (self *Node) SubPrefixCount(r common.Range, result *int) error {
+	*result = self.tree.SubCountPrefix(r.Key, r.Min)
+	return nil
+}
 func (self *Node) MirrorLast(data common.Item, result *common.Item) error {
 	result.Key, result.Value, result.Timestamp, result.Exists = self.tree.SubMirrorLast(data.Key)
 	return nil
@@ -176,8 +216,21 @@ func (self *Node) Slice(r common.Range, items *[]common.Item) error {
 	})
 	return nil
 }
+
+// SubPrefixSlice will set items to the sub keys and values starting with r.Min in the sub tree defined by r.Key.
+func (self *Node) SubPrefixSlice(r common.Range, items *[]common.Item) error {
+	self.tree.SubEachWithPrefix(r.Key, r.Min, func(key []byte, value []byte, version int64) bool {
+		*items = append(*items, common.Item{
+			Key:       key,
+			Value:     value,
+			Timestamp: version,
+		})
+		return true
+	})
+	return nil
+}
 func (self *Node) SliceLen(r common.Range, items *[]common.Item) error {
-	self.tree.SubEachBetween(r.Key, r.Min, nil, r.MinInc, false, func(key []byte, value []byte, version int64) bool {
+	self.tree.SubEachBetween(r.Key, r.Min, r.Max, r.MinInc, r.MaxInc, func(key []byte, value []byte, version int64) bool {
 		*items = append(*items, common.Item{
 			Key:       key,
 			Value:     value,
@@ -188,7 +241,33 @@ func (self *Node) SliceLen(r common.Range, items *[]common.Item) error {
 	return nil
 }
 func (self *Node) ReverseSliceLen(r common.Range, items *[]common.Item) error {
-	self.tree.SubReverseEachBetween(r.Key, nil, r.Max, false, r.MaxInc, func(key []byte, value []byte, version int64) bool {
+	self.tree.SubReverseEachBetween(r.Key, r.Min, r.Max, r.MinInc, r.MaxInc, func(key []byte, value []byte, version int64) bool {
+		*items = append(*items, common.Item{
+			Key:       key,
+			Value:     value,
+			Timestamp: version,
+		})
+		return len(*items) < r.Len
+	})
+	return nil
+}
+
+// ScanLen will set items to at most r.Len top level keys and values between r.Min and r.Max.
+func (self *Node) ScanLen(r common.Range, items *[]common.Item) error {
+	self.tree.EachBetween(r.Min, r.Max, r.MinInc, r.MaxInc, func(key []byte, value []byte, version int64) bool {
+		*items = append(*items, common.Item{
+			Key:       key,
+			Value:     value,
+			Timestamp: version,
+		})
+		return len(*items) < r.Len
+	})
+	return nil
+}
+
+// ReverseScanLen will set items to at most r.Len top level keys and values between r.Max and r.Min, in reverse order.
+func (self *Node) ReverseScanLen(r common.Range, items *[]common.Item) error {
+	self.tree.ReverseEachBetween(r.Min, r.Max, r.MinInc, r.MaxInc, func(key []byte, value []byte, version int64) bool {
 		*items = append(*items, common.Item{
 			Key:       key,
 			Value:     value,
@@ -300,31 +379,379 @@ func (self *Node) IndexOf(data common.Item, result *common.Index) error {
 }
 func (self *Node) SubGet(data common.Item, result *common.Item) error {
 	*result = data
-	result.Value, result.Timestamp, result.Exists = self.tree.SubGet(data.Key, data.SubKey)
+	var exp int64
+	result.Value, result.Timestamp, exp, result.Exists = self.tree.SubGetExpires(data.Key, data.SubKey)
+	result.Lifetime = lifetime(result.Timestamp, exp)
+	return nil
+}
+
+// MultiGet will set result to the items with the values, timestamps and lifetimes under the keys of items.
+func (self *Node) MultiGet(items []common.Item, result *[]common.Item) error {
+	*result = make([]common.Item, len(items))
+	for index, item := range items {
+		var exp int64
+		(*result)[index] = item
+		(*result)[index].Value, (*result)[index].Timestamp, exp, (*result)[index].Mergeable, (*result)[index].Exists = self.tree.GetMergeable(item.Key)
+		(*result)[index].Lifetime = lifetime((*result)[index].Timestamp, exp)
+	}
+	return nil
+}
+
+// IndexSlice will set result to the entries in the secondary index named r.Key with fields between r.Min and r.Max, for the sub trees this node owns.
+func (self *Node) IndexSlice(r common.Range, result *[]common.IndexItem) error {
+	self.tree.IndexEachBetween(string(r.Key), r.Min, r.Max, r.MinInc, r.MaxInc, func(field, key, subKey, value []byte, timestamp int64) bool {
+		if self.node.GetSuccessorFor(key).Addr == self.node.GetBroadcastAddr() {
+			*result = append(*result, common.IndexItem{
+				Field:     field,
+				Key:       key,
+				SubKey:    subKey,
+				Value:     value,
+				Timestamp: timestamp,
+			})
+		}
+		return true
+	})
+	return nil
+}
+
+// MultiSubGet will set result to the items with the values, timestamps and lifetimes under the sub keys of items in the sub trees defined by their keys.
+func (self *Node) MultiSubGet(items []common.Item, result *[]common.Item) error {
+	*result = make([]common.Item, len(items))
+	for index, item := range items {
+		var exp int64
+		(*result)[index] = item
+		(*result)[index].Value, (*result)[index].Timestamp, exp, (*result)[index].Exists = self.tree.SubGetExpires(item.Key, item.SubKey)
+		(*result)[index].Lifetime = lifetime((*result)[index].Timestamp, exp)
+	}
 	return nil
 }
 func (self *Node) SubClear(data common.Item) error {
 	data.TTL, data.Timestamp = self.node.Redundancy(), self.timer.ContinuousTime()
-	return self.subClear(data)
+	return self.subClearUnlessPrepared(data, nil)
 }
 func (self *Node) SubDel(data common.Item) error {
 	data.TTL, data.Timestamp = self.node.Redundancy(), self.timer.ContinuousTime()
-	return self.subDel(data)
+	return self.subDelUnlessPrepared(data, nil)
 }
 func (self *Node) SubPut(data common.Item) error {
 	data.TTL, data.Timestamp = self.node.Redundancy(), self.timer.ContinuousTime()
-	return self.subPut(data)
+	return self.subPutUnlessPrepared(data, nil)
 }
 func (self *Node) Del(data common.Item) error {
 	data.TTL, data.Timestamp = self.node.Redundancy(), self.timer.ContinuousTime()
-	return self.del(data)
+	return self.delUnlessPrepared(data, nil)
 }
 func (self *Node) Put(data common.Item) error {
 	data.TTL, data.Timestamp = self.node.Redundancy(), self.timer.ContinuousTime()
-	return self.put(data)
+	return self.putUnlessPrepared(data, nil)
+}
+
+// MultiPut will put all items this node owns, where items with a SubKey are put in the sub tree defined by their Key,
+// and replicate them to the next node in one batch. Items owned by other nodes will be forwarded to them in one batch per owner, in parallel.
+// errs will contain an error message for each item, or "" if it was put.
+func (self *Node) MultiPut(items []common.Item, errs *[]string) error {
+	*errs = make([]string, len(items))
+	owned := make([]common.Item, 0, len(items))
+	ownedIndices := make([]int, 0, len(items))
+	var owners common.Remotes
+	var batches [][]common.Item
+	var batchIndices [][]int
+	batchByAddr := make(map[string]int)
+	for index, item := range items {
+		if succ := self.node.GetSuccessorFor(item.Key); succ.Addr != self.node.GetBroadcastAddr() {
+			batch, found := batchByAddr[succ.Addr]
+			if !found {
+				batch = len(owners)
+				batchByAddr[succ.Addr] = batch
+				owners = append(owners, succ)
+				batches = append(batches, nil)
+				batchIndices = append(batchIndices, nil)
+			}
+			batches[batch] = append(batches[batch], item)
+			batchIndices[batch] = append(batchIndices[batch], index)
+		} else {
+			item.TTL, item.Timestamp = self.node.Redundancy(), self.timer.ContinuousTime()
+			owned = append(owned, item)
+			ownedIndices = append(ownedIndices, index)
+		}
+	}
+	futures := make([]*rpc.Call, len(owners))
+	results := make([]*[]string, len(owners))
+	for batch, owner := range owners {
+		var forwardedErrs []string
+		results[batch] = &forwardedErrs
+		futures[batch] = owner.Go("DHash.MultiPut", batches[batch], &forwardedErrs)
+	}
+	for index, err := range self.multiPutUnlessPrepared(owned) {
+		if err != nil {
+			(*errs)[ownedIndices[index]] = err.Error()
+		}
+	}
+	for batch, future := range futures {
+		<-future.Done
+		for index, itemIndex := range batchIndices[batch] {
+			if future.Error != nil {
+				(*errs)[itemIndex] = future.Error.Error()
+			} else {
+				(*errs)[itemIndex] = (*results[batch])[index]
+			}
+		}
+	}
+	return nil
+}
+
+// expected returns the value a conditional operation with data expects, where nil means that it expects no value.
+func expected(data common.Item) []byte {
+	if data.HasExpected && data.Expected == nil {
+		return []byte{}
+	}
+	return data.Expected
+}
+
+// CompareAndSwap will put data.Value under data.Key if the current value is data.Expected, and set swapped to whether it did.
+func (self *Node) CompareAndSwap(data common.Item, swapped *bool) (err error) {
+	var f bool
+	if f, err = self.forwardUnlessOwner("DHash.CompareAndSwap", data.Key, data, swapped); f {
+		return
+	}
+	data.TTL, data.Timestamp = self.node.Redundancy(), self.timer.ContinuousTime()
+	if err = self.unlessPrepared(func() {
+		*swapped = self.tree.CompareAndPut(data.Key, expected(data), data.Value, data.Timestamp, expires(data))
+	}, itemTxnOp(data)); err == nil && *swapped {
+		self.replicate(data, "DHash.SlavePut")
+	}
+	return
+}
+
+// DelIfEqual will delete data.Key if the current value is data.Expected, and set deleted to whether it did.
+func (self *Node) DelIfEqual(data common.Item, deleted *bool) (err error) {
+	var f bool
+	if f, err = self.forwardUnlessOwner("DHash.DelIfEqual", data.Key, data, deleted); f {
+		return
+	}
+	data.TTL, data.Timestamp = self.node.Redundancy(), self.timer.ContinuousTime()
+	if err = self.unlessPrepared(func() {
+		*deleted = self.tree.CompareAndFakeDel(data.Key, expected(data), data.Timestamp)
+	}, delTxnOp(data)); err == nil && *deleted {
+		self.replicate(data, "DHash.SlaveDel")
+	}
+	return
+}
+
+// SubCompareAndSwap will put data.Value under data.SubKey in the sub tree data.Key if the current value is data.Expected, and set swapped to whether it did.
+func (self *Node) SubCompareAndSwap(data common.Item, swapped *bool) (err error) {
+	var f bool
+	if f, err = self.forwardUnlessOwner("DHash.SubCompareAndSwap", data.Key, data, swapped); f {
+		return
+	}
+	data.TTL, data.Timestamp = self.node.Redundancy(), self.timer.ContinuousTime()
+	if err = self.unlessPrepared(func() {
+		*swapped = self.tree.SubCompareAndPut(data.Key, data.SubKey, expected(data), data.Value, data.Timestamp, expires(data))
+	}, itemTxnOp(data)); err == nil && *swapped {
+		self.replicate(data, "DHash.SlaveSubPut")
+	}
+	return
+}
+
+// update will atomically replace the value under data.Key (or under data.SubKey in the sub tree data.Key, if sub) with what f returns for it,
+// replicate the new value like Put (or SubPut) does, and return it. A data.Mergeable value (not in a sub tree) is marked like radix.Tree#UpdateMergeable does. Nothing is changed if f returns nil or an error, or if a sub key or value
+// isn't encoded like the configuration of the sub tree requires.
+func (self *Node) update(data common.Item, sub bool, f func(oldBytes []byte, existed bool) (newBytes []byte, err error)) (newBytes []byte, err error) {
+	op := common.TxnOp{Key: data.Key}
+	if sub {
+		op.SubKey, op.Sub = data.SubKey, true
+	}
+	conf, _ := self.tree.SubConfiguration(data.Key)
+	data.TTL, data.Timestamp = self.node.Redundancy(), self.timer.ContinuousTime()
+	updater := func(oldBytes []byte, existed bool) (newBytes []byte, update bool) {
+		if newBytes, err = f(oldBytes, existed); err != nil || newBytes == nil {
+			return nil, false
+		}
+		written := op
+		written.Value = newBytes
+		if err = encodingError(conf, written); err != nil {
+			return nil, false
+		}
+		return newBytes, true
+	}
+	var exp int64
+	var updated bool
+	if e := self.unlessPrepared(func() {
+		if sub {
+			data.Value, exp, updated = self.tree.SubUpdate(data.Key, data.SubKey, data.Timestamp, updater)
+		} else if data.Mergeable {
+			data.Value, exp, updated = self.tree.UpdateMergeable(data.Key, data.Timestamp, updater)
+		} else {
+			data.Value, exp, updated = self.tree.Update(data.Key, data.Timestamp, updater)
+		}
+	}, op); e != nil {
+		return nil, e
+	}
+	if updated {
+		data.Lifetime = lifetime(data.Timestamp, exp)
+		newBytes = data.Value
+		if sub {
+			self.replicate(data, "DHash.SlaveSubPut")
+		} else {
+			self.replicate(data, "DHash.SlavePut")
+		}
+	}
+	return
+}
+
+// incrementer returns a function for radix.Tree#Update that adds delta to the int64 encoded value, stores the sum in result and any decoding error in err.
+func incrementer(delta int64, result *int64, err *error) func(oldBytes []byte, existed bool) (newBytes []byte, update bool) {
+	return func(oldBytes []byte, existed bool) (newBytes []byte, update bool) {
+		var current int64
+		if existed {
+			if current, *err = setop.DecodeInt64(oldBytes); *err != nil {
+				return
+			}
+		}
+		*result = current + delta
+		return setop.EncodeInt64(*result), true
+	}
+}
+
+// Incr will add the int64 encoded in data.Value to the int64 encoded under data.Key, and set result to the sum.
+// A missing value is treated as 0.
+func (self *Node) Incr(data common.Item, result *int64) (err error) {
+	var f bool
+	if f, err = self.forwardUnlessOwner("DHash.Incr", data.Key, data, result); f {
+		return
+	}
+	var delta int64
+	if delta, err = setop.DecodeInt64(data.Value); err != nil {
+		return
+	}
+	data.TTL, data.Timestamp = self.node.Redundancy(), self.timer.ContinuousTime()
+	var exp int64
+	var updated bool
+	if e := self.unlessPrepared(func() {
+		data.Value, exp, updated = self.tree.Update(data.Key, data.Timestamp, incrementer(delta, result, &err))
+	}, itemTxnOp(data)); e != nil {
+		return e
+	}
+	if updated {
+		data.Lifetime = lifetime(data.Timestamp, exp)
+		self.replicate(data, "DHash.SlavePut")
+	}
+	return
+}
+
+// SubIncr will add the int64 encoded in data.Value to the int64 encoded under data.SubKey in the sub tree data.Key, and set result to the sum.
+// A missing value is treated as 0.
+func (self *Node) SubIncr(data common.Item, result *int64) (err error) {
+	var f bool
+	if f, err = self.forwardUnlessOwner("DHash.SubIncr", data.Key, data, result); f {
+		return
+	}
+	if conf, _ := self.tree.SubConfiguration(data.Key); conf[common.ValueEncoding] != "" && conf[common.ValueEncoding] != common.EncodingInt64 {
+		return fmt.Errorf("%#v has values encoded as %v, and can't be incremented", string(data.Key), conf[common.ValueEncoding])
+	}
+	var delta int64
+	if delta, err = setop.DecodeInt64(data.Value); err != nil {
+		return
+	}
+	data.TTL, data.Timestamp = self.node.Redundancy(), self.timer.ContinuousTime()
+	var exp int64
+	var updated bool
+	if e := self.unlessPrepared(func() {
+		data.Value, exp, updated = self.tree.SubUpdate(data.Key, data.SubKey, data.Timestamp, incrementer(delta, result, &err))
+	}, itemTxnOp(data)); e != nil {
+		return e
+	}
+	if updated {
+		data.Lifetime = lifetime(data.Timestamp, exp)
+		self.replicate(data, "DHash.SlaveSubPut")
+	}
+	return
+}
+
+// SubDelIfEqual will delete data.SubKey in the sub tree data.Key if the current value is data.Expected, and set deleted to whether it did.
+func (self *Node) SubDelIfEqual(data common.Item, deleted *bool) (err error) {
+	var f bool
+	if f, err = self.forwardUnlessOwner("DHash.SubDelIfEqual", data.Key, data, deleted); f {
+		return
+	}
+	data.TTL, data.Timestamp = self.node.Redundancy(), self.timer.ContinuousTime()
+	if err = self.unlessPrepared(func() {
+		*deleted = self.tree.SubCompareAndFakeDel(data.Key, data.SubKey, expected(data), data.Timestamp)
+	}, delTxnOp(data)); err == nil && *deleted {
+		self.replicate(data, "DHash.SlaveSubDel")
+	}
+	return
+}
+
+// SubPrefixDel will replace all sub keys starting with data.SubKey in the sub tree defined by data.Key with tombstones,
+// and set deleted to the number of sub keys it replaced.
+func (self *Node) SubPrefixDel(data common.Item, deleted *int) (err error) {
+	var f bool
+	if f, err = self.forwardUnlessOwner("DHash.SubPrefixDel", data.Key, data, deleted); f {
+		return
+	}
+	data.TTL, data.Timestamp = self.node.Redundancy(), self.timer.ContinuousTime()
+	if err = self.unlessPreparedTree(data.Key, func() {
+		*deleted = self.tree.SubFakeDelPrefix(data.Key, data.SubKey, data.Timestamp)
+	}); err == nil {
+		self.replicate(data, "DHash.SlaveSubPrefixDel")
+	}
+	return
+}
+
+// SubDelRange will replace all keys in the range of op with tombstones in the sub tree defined by its key, and set deleted to the number of keys it replaced.
+// Index ranges are converted to key ranges before being replicated, since the indices may differ between replicas.
+func (self *Node) SubDelRange(op common.RangeOp, deleted *int) (err error) {
+	var f bool
+	if f, err = self.forwardUnlessOwner("DHash.SubDelRange", op.Range.Key, op, deleted); f {
+		return
+	}
+	op.TTL, op.Timestamp = self.node.Redundancy(), self.timer.ContinuousTime()
+	if op.Index {
+		min := &op.Range.MinIndex
+		max := &op.Range.MaxIndex
+		if !op.Range.MinInc {
+			min = nil
+		}
+		if !op.Range.MaxInc {
+			max = nil
+		}
+		var first, last []byte
+		if err = self.unlessPreparedTree(op.Range.Key, func() {
+			first, last, *deleted = self.tree.SubFakeDelBetweenIndex(op.Range.Key, min, max, op.Timestamp)
+		}); err == nil && *deleted > 0 {
+			op.Index = false
+			op.Range.Min, op.Range.Max, op.Range.MinInc, op.Range.MaxInc = first, last, true, true
+			self.replicateRange(op, "DHash.SlaveSubDelRange")
+		}
+		return
+	}
+	if err = self.unlessPreparedTree(op.Range.Key, func() {
+		*deleted = self.tree.SubFakeDelBetween(op.Range.Key, op.Range.Min, op.Range.Max, op.Range.MinInc, op.Range.MaxInc, op.Timestamp)
+	}); err == nil {
+		self.replicateRange(op, "DHash.SlaveSubDelRange")
+	}
+	return
+}
+
+// expires returns the time when the value in data will expire, or 0 if it never will.
+func expires(data common.Item) int64 {
+	if data.Lifetime > 0 {
+		return data.Timestamp + data.Lifetime
+	}
+	return 0
+}
+
+// lifetime returns the Lifetime of a value with timestamp that will expire at exp, or 0 if exp is 0 and it never will.
+func lifetime(timestamp, exp int64) int64 {
+	if exp != 0 {
+		return exp - timestamp
+	}
+	return 0
 }
 func (self *Node) forwardOperation(data common.Item, operation string) {
 	data.TTL--
+	data.Acks--
 	successor := self.node.GetSuccessor()
 	var x int
 	if self.hasCommListeners() {
@@ -343,64 +770,227 @@ func (self *Node) forwardOperation(data common.Item, operation string) {
 		err = successor.Call(operation, data, &x)
 	}
 }
-func (self *Node) Clear() {
-	self.tree.Clear(self.timer.ContinuousTime())
-}
-func (self *Node) subClear(data common.Item) error {
+
+// replicate will forward the operation to the next replica, synchronously if data.Sync is set or more replicas have to ack it, unless this is the last replica.
+// If data.Sync is set, or the local log always fsyncs, it will first wait for the local log to be durable.
+func (self *Node) replicate(data common.Item, operation string) {
+	self.tree.SyncLog(data.Sync)
 	if data.TTL > 1 {
-		if data.Sync {
-			self.forwardOperation(data, "DHash.SlaveSubClear")
+		if data.Sync || data.Acks > 1 {
+			self.forwardOperation(data, operation)
 		} else {
-			go self.forwardOperation(data, "DHash.SlaveSubClear")
+			go self.forwardOperation(data, operation)
 		}
 	}
-	self.tree.SubClear(data.Key, data.Timestamp)
-	return nil
 }
-func (self *Node) subDel(data common.Item) error {
-	if data.TTL > 1 {
-		if data.Sync {
-			self.forwardOperation(data, "DHash.SlaveSubDel")
+
+// forwardRange will forward op, with decremented TTL and Acks, to the next replica using operation.
+func (self *Node) forwardRange(op common.RangeOp, operation string) {
+	op.TTL--
+	op.Acks--
+	successor := self.node.GetSuccessor()
+	var x int
+	err := successor.Call(operation, op, &x)
+	for err != nil {
+		self.node.RemoveNode(successor)
+		successor = self.node.GetSuccessor()
+		err = successor.Call(operation, op, &x)
+	}
+}
+
+// replicateRange will forward op to the next replica like replicate does with items.
+func (self *Node) replicateRange(op common.RangeOp, operation string) {
+	self.tree.SyncLog(op.Sync)
+	if op.TTL > 1 {
+		if op.Sync || op.Acks > 1 {
+			self.forwardRange(op, operation)
+		} else {
+			go self.forwardRange(op, operation)
+		}
+	}
+}
+
+// forwardItems will forward the items, with decremented TTL and Acks, to the next replica in one batch using operation.
+func (self *Node) forwardItems(items []common.Item, operation string) {
+	items = append([]common.Item{}, items...)
+	for index, _ := range items {
+		items[index].TTL--
+		items[index].Acks--
+	}
+	successor := self.node.GetSuccessor()
+	var x int
+	err := successor.Call(operation, items, &x)
+	for err != nil {
+		self.node.RemoveNode(successor)
+		successor = self.node.GetSuccessor()
+		err = successor.Call(operation, items, &x)
+	}
+}
+
+// replicateItems will forward the operation on all items to the next replica in one batch, synchronously if the items are Sync or more replicas have to ack them, unless this is the last replica.
+func (self *Node) replicateItems(items []common.Item, operation string) {
+	if len(items) > 0 {
+		self.tree.SyncLog(items[0].Sync)
+	}
+	if len(items) > 0 && items[0].TTL > 1 {
+		if items[0].Sync || items[0].Acks > 1 {
+			self.forwardItems(items, operation)
 		} else {
-			go self.forwardOperation(data, "DHash.SlaveSubDel")
+			go self.forwardItems(items, operation)
 		}
 	}
+}
+
+// checkEncoding returns encodingError for op and the configuration of the sub tree op.Key.
+func (self *Node) checkEncoding(op common.TxnOp) error {
+	if !op.Sub || op.Del {
+		return nil
+	}
+	conf, _ := self.tree.SubConfiguration(op.Key)
+	return encodingError(conf, op)
+}
+
+// encodingError returns an error if op puts a sub key or value that isn't encoded like conf, the configuration of its sub tree, requires.
+// Deletes, and values outside sub trees, have no encoding. A nil value is not checked, since update checks the value it computes itself.
+func encodingError(conf map[string]string, op common.TxnOp) (err error) {
+	if !op.Sub || op.Del {
+		return
+	}
+	if err = common.ValidateEncoding(conf[common.KeyEncoding], op.SubKey); err != nil || op.Value == nil {
+		return
+	}
+	return common.ValidateEncoding(conf[common.ValueEncoding], op.Value)
+}
+
+// forwardUnlessOwner will make the owner of key perform operation, unless this node is the owner.
+func (self *Node) forwardUnlessOwner(operation string, key []byte, in, out interface{}) (forwarded bool, err error) {
+	if succ := self.node.GetSuccessorFor(key); succ.Addr != self.node.GetBroadcastAddr() {
+		forwarded, err = true, succ.Call(operation, in, out)
+	}
+	return
+}
+func (self *Node) Clear() {
+	self.tree.Clear(self.timer.ContinuousTime())
+}
+func (self *Node) subClear(data common.Item) error {
+	self.tree.SubClear(data.Key, data.Timestamp)
+	self.replicate(data, "DHash.SlaveSubClear")
+	return nil
+}
+func (self *Node) subDel(data common.Item) error {
 	self.tree.SubFakeDel(data.Key, data.SubKey, data.Timestamp)
+	self.replicate(data, "DHash.SlaveSubDel")
+	return nil
+}
+func (self *Node) subPrefixDel(data common.Item, deleted *int) error {
+	*deleted = self.tree.SubFakeDelPrefix(data.Key, data.SubKey, data.Timestamp)
+	self.replicate(data, "DHash.SlaveSubPrefixDel")
+	return nil
+}
+func (self *Node) subDelRange(op common.RangeOp, deleted *int) error {
+	*deleted = self.tree.SubFakeDelBetween(op.Range.Key, op.Range.Min, op.Range.Max, op.Range.MinInc, op.Range.MaxInc, op.Timestamp)
+	self.replicateRange(op, "DHash.SlaveSubDelRange")
 	return nil
 }
 func (self *Node) subPut(data common.Item) error {
-	if data.TTL > 1 {
-		if data.Sync {
-			self.forwardOperation(data, "DHash.SlaveSubPut")
+	self.tree.SubPutExpires(data.Key, data.SubKey, data.Value, data.Timestamp, expires(data))
+	self.replicate(data, "DHash.SlaveSubPut")
+	return nil
+}
+func (self *Node) multiPut(items []common.Item) error {
+	for _, item := range items {
+		if item.SubKey == nil {
+			self.tree.PutExpires(item.Key, item.Value, item.Timestamp, expires(item))
 		} else {
-			go self.forwardOperation(data, "DHash.SlaveSubPut")
+			self.tree.SubPutExpires(item.Key, item.SubKey, item.Value, item.Timestamp, expires(item))
 		}
 	}
-	self.tree.SubPut(data.Key, data.SubKey, data.Value, data.Timestamp)
+	self.replicateItems(items, "DHash.SlaveMultiPut")
 	return nil
 }
 func (self *Node) del(data common.Item) error {
-	if data.TTL > 1 {
-		if data.Sync {
-			self.forwardOperation(data, "DHash.SlaveDel")
-		} else {
-			go self.forwardOperation(data, "DHash.SlaveDel")
-		}
-	}
 	self.tree.FakeDel(data.Key, data.Timestamp)
+	self.replicate(data, "DHash.SlaveDel")
 	return nil
 }
 func (self *Node) put(data common.Item) error {
-	if data.TTL > 1 {
-		if data.Sync {
-			self.forwardOperation(data, "DHash.SlavePut")
-		} else {
-			go self.forwardOperation(data, "DHash.SlavePut")
-		}
+	if data.Mergeable {
+		self.tree.PutMergeable(data.Key, data.Value, data.Timestamp, expires(data))
+	} else {
+		self.tree.PutExpires(data.Key, data.Value, data.Timestamp, expires(data))
 	}
-	self.tree.Put(data.Key, data.Value, data.Timestamp)
+	self.replicate(data, "DHash.SlavePut")
 	return nil
 }
+
+// The ...UnlessPrepared functions are used by the owner of a key instead of the plain ones above, which are also used by the replicas.
+// They will not change anything, and will return an error, if a prepared transaction has locked the key, and will wait for any running
+// Procedure for the key other than proc to finish.
+
+func (self *Node) subClearUnlessPrepared(data common.Item, proc *ProcedureContext) (err error) {
+	if err = self.unlessPreparedTreeFor(proc, data.Key, func() {
+		self.tree.SubClear(data.Key, data.Timestamp)
+	}); err == nil {
+		self.replicate(data, "DHash.SlaveSubClear")
+	}
+	return
+}
+func (self *Node) subDelUnlessPrepared(data common.Item, proc *ProcedureContext) (err error) {
+	if err = self.unlessPreparedFor(proc, func() {
+		self.tree.SubFakeDel(data.Key, data.SubKey, data.Timestamp)
+	}, delTxnOp(data)); err == nil {
+		self.replicate(data, "DHash.SlaveSubDel")
+	}
+	return
+}
+func (self *Node) subPutUnlessPrepared(data common.Item, proc *ProcedureContext) (err error) {
+	if err = self.unlessPreparedFor(proc, func() {
+		self.tree.SubPutExpires(data.Key, data.SubKey, data.Value, data.Timestamp, expires(data))
+	}, itemTxnOp(data)); err == nil {
+		self.replicate(data, "DHash.SlaveSubPut")
+	}
+	return
+}
+func (self *Node) delUnlessPrepared(data common.Item, proc *ProcedureContext) (err error) {
+	if err = self.unlessPreparedFor(proc, func() {
+		self.tree.FakeDel(data.Key, data.Timestamp)
+	}, delTxnOp(data)); err == nil {
+		self.replicate(data, "DHash.SlaveDel")
+	}
+	return
+}
+func (self *Node) putUnlessPrepared(data common.Item, proc *ProcedureContext) (err error) {
+	data.Mergeable = false
+	if err = self.unlessPreparedFor(proc, func() {
+		self.tree.PutExpires(data.Key, data.Value, data.Timestamp, expires(data))
+	}, itemTxnOp(data)); err == nil {
+		self.replicate(data, "DHash.SlavePut")
+	}
+	return
+}
+
+// multiPutUnlessPrepared will put the items no prepared transaction has locked, after waiting for any running Procedure for their keys to finish,
+// replicate them in one batch, and return an error for each item, or nil if it was put.
+func (self *Node) multiPutUnlessPrepared(items []common.Item) (errs []error) {
+	errs = make([]error, len(items))
+	put := make([]common.Item, 0, len(items))
+	self.txnLock.Lock()
+	for index, item := range items {
+		self.waitForProcedures(nil, item.Key)
+		if errs[index] = self.writeError(itemTxnOp(item)); errs[index] == nil {
+			item.Mergeable = false
+			if item.SubKey == nil {
+				self.tree.PutExpires(item.Key, item.Value, item.Timestamp, expires(item))
+			} else {
+				self.tree.SubPutExpires(item.Key, item.SubKey, item.Value, item.Timestamp, expires(item))
+			}
+			put = append(put, item)
+		}
+	}
+	self.txnLock.Unlock()
+	self.replicateItems(put, "DHash.SlaveMultiPut")
+	return
+}
 func (self *Node) Size() int {
 	pred := self.node.GetPredecessor()
 	me := self.node.Remote()
@@ -419,17 +1009,24 @@ func (self *Node) SubSize(key []byte, result *int) error {
 	*result = self.tree.SubSize(key)
 	return nil
 }
+// SetExpression will execute expr, and either store the results in the sub tree expr.Dest or set items to them.
+// If all merges in expr are Append, as when expr.Code picks no merges, and all sub trees expr reads from have the same valueEncoding,
+// they will be replaced with the common.DefaultMerge of that encoding.
 func (self *Node) SetExpression(expr setop.SetExpression, items *[]setop.SetOpResult) (err error) {
 	if expr.Dest != nil {
-		if expr.Op.Merge == setop.Append {
-			err = fmt.Errorf("When storing results of Set expressions the Append merge function is not allowed")
-			return
-		}
 		successor := self.node.GetSuccessorFor(expr.Dest)
 		if successor.Addr != self.node.GetBroadcastAddr() {
 			return successor.Call("DHash.SetExpression", expr, items)
 		}
 	}
+	if err = self.defaultMerges(expr); err != nil {
+		return
+	}
+	if expr.Dest != nil && expr.Op.Merge == setop.Append {
+		err = fmt.Errorf("When storing results of Set expressions the Append merge function is not allowed")
+		return
+	}
+	var putErr error
 	data := common.Item{
 		Key: expr.Dest,
 	}
@@ -452,9 +1049,14 @@ func (self *Node) SetExpression(expr setop.SetExpression, items *[]setop.SetOpRe
 			data.Value = res.Values[0]
 			data.TTL = self.node.Redundancy()
 			data.Timestamp = self.timer.ContinuousTime()
-			self.subPut(data)
+			if err := self.subPutUnlessPrepared(data, nil); err != nil && putErr == nil {
+				putErr = err
+			}
 		}
 	})
+	if err == nil {
+		err = putErr
+	}
 	return
 }
 func (self *Node) AddConfiguration(c common.ConfItem) {
@@ -0,0 +1,114 @@
+package dhash
+
+import (
+	"bytes"
+	"io"
+	"time"
+
+	"github.com/zond/god/common"
+	"github.com/zond/god/persistence"
+	"github.com/zond/god/radix"
+)
+
+// backup will write the operations created by f to w, gzip compressed.
+func backup(w io.Writer, f func(func(op persistence.Op))) (err error) {
+	var writer *persistence.Writer
+	if writer, err = persistence.NewWriter(w, persistence.GzipCompression); err != nil {
+		return
+	}
+	f(func(op persistence.Op) {
+		if err == nil {
+			err = writer.Write(op)
+		}
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	return
+}
+
+// Backup will write a snapshot of all values, sub trees and configurations of this node, including those it only holds as a replica, to w.
+// The snapshot is of the node as it was when Backup was called, so writes done during the backup are not included.
+func (self *Node) Backup(w io.Writer) error {
+	snapshot := self.tree.Snapshot()
+	return backup(w, func(f func(op persistence.Op)) {
+		snapshot.BackupBetween(nil, nil, true, false, f)
+	})
+}
+
+// BackupOwned will write a snapshot of the values and sub trees this node owns, and the configurations of them and of this node, to w,
+// as they were when BackupOwned was called.
+func (self *Node) BackupOwned(w io.Writer) error {
+	pred := self.node.GetPredecessor()
+	me := self.node.Remote()
+	snapshot := self.tree.Snapshot()
+	return backup(w, func(f func(op persistence.Op)) {
+		if cmp := bytes.Compare(pred.Pos, me.Pos); cmp != 0 {
+			snapshot.BackupBetween(pred.Pos, me.Pos, true, false, f)
+		} else if !pred.Less(me) {
+			snapshot.BackupBetween(nil, nil, true, false, f)
+		}
+	})
+}
+
+// Restore will put the values, sub trees and configurations in the snapshot read from r into this node, keeping their timestamps,
+// unless this node has newer data for them. They will reach their owners and replicas during the regular synchronization.
+func (self *Node) Restore(r io.Reader) (err error) {
+	_, err = persistence.Read(r, self.tree.ApplyNewer)
+	return
+}
+
+// RestoreOps will put the values, sub trees and configurations in ops, as created by Backup, into this node, keeping their timestamps,
+// unless this node has newer data for them.
+func (self *Node) RestoreOps(ops []persistence.Op) {
+	for _, op := range ops {
+		self.tree.ApplyNewer(op)
+	}
+}
+
+// snapshotTimeout is how long a snapshot taken by Snapshot is kept after it was last used.
+const snapshotTimeout = time.Minute
+
+// backupSnapshot is a snapshot of the tree of a node, kept for a backup fetching it one page at a time.
+type backupSnapshot struct {
+	tree     *radix.Tree
+	lastUsed time.Time
+}
+
+// snapshot returns the snapshot named name, taking it if it doesn't exist, and forgets the snapshots unused for longer than snapshotTimeout.
+func (self *Node) snapshot(name []byte) *radix.Tree {
+	self.snapshotLock.Lock()
+	defer self.snapshotLock.Unlock()
+	now := time.Now()
+	for n, snapshot := range self.snapshots {
+		if now.Sub(snapshot.lastUsed) > snapshotTimeout {
+			delete(self.snapshots, n)
+		}
+	}
+	snapshot, found := self.snapshots[string(name)]
+	if !found {
+		snapshot = &backupSnapshot{tree: self.tree.Snapshot()}
+		self.snapshots[string(name)] = snapshot
+	}
+	snapshot.lastUsed = now
+	return snapshot.tree
+}
+
+// Snapshot will take a snapshot of this node named name, for BackupPage to fetch pages from, or keep it if it is already taken.
+func (self *Node) Snapshot(name []byte) {
+	self.snapshot(name)
+}
+
+// ReleaseSnapshot will forget the snapshot named name.
+func (self *Node) ReleaseSnapshot(name []byte) {
+	self.snapshotLock.Lock()
+	defer self.snapshotLock.Unlock()
+	delete(self.snapshots, string(name))
+}
+
+// BackupPage will set result to the operations that recreate the first r.Range.Len values and sub trees between r.Range.Min and r.Range.Max,
+// with their configurations, as they were when the snapshot named r.Snapshot was taken. If it wasn't, or was forgotten, it is taken now.
+func (self *Node) BackupPage(r common.SnapshotRange, result *[]persistence.Op) error {
+	*result, _, _ = self.snapshot(r.Snapshot).BackupPage(r.Range.Min, r.Range.Max, r.Range.MinInc, r.Range.MaxInc, r.Range.Len)
+	return nil
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"math/big"
 	"net"
+	"reflect"
 	"runtime"
+	"sort"
 	"testing"
 	"time"
 
@@ -20,10 +22,21 @@ type testClient interface {
 	SubPut(key, subKey, value []byte)
 	SPut(key, value []byte)
 	Put(key, value []byte)
+	SSubPutTTL(key, subKey, value []byte, ttl time.Duration)
+	SPutTTL(key, value []byte, ttl time.Duration)
+	SSubCompareAndSwap(key, subKey, expected, value []byte) bool
+	SSubPutIfAbsent(key, subKey, value []byte) bool
+	SSubDelIfEqual(key, subKey, expected []byte) bool
+	SCompareAndSwap(key, expected, value []byte) bool
+	SPutIfAbsent(key, value []byte) bool
+	SDelIfEqual(key, expected []byte) bool
+	SSubIncr(key, subKey []byte, delta int64) (int64, error)
+	SIncr(key []byte, delta int64) (int64, error)
 	SubClear(key []byte)
 	SSubClear(key []byte)
 	SubDel(key, subKey []byte)
 	SSubDel(key, subKey []byte)
+	SSubPrefixDel(key, prefix []byte) int
 	SDel(key []byte)
 	Del(key []byte)
 	MirrorReverseIndexOf(key, subKey []byte) (index int, existed bool)
@@ -34,6 +47,7 @@ type testClient interface {
 	Prev(key []byte) (prevKey, prevValue []byte, existed bool)
 	MirrorCount(key, min, max []byte, mininc, maxinc bool) (result int)
 	Count(key, min, max []byte, mininc, maxinc bool) (result int)
+	SubPrefixCount(key, prefix []byte) (result int)
 	MirrorNextIndex(key []byte, index int) (foundKey, foundValue []byte, foundIndex int, existed bool)
 	MirrorPrevIndex(key []byte, index int) (foundKey, foundValue []byte, foundIndex int, existed bool)
 	NextIndex(key []byte, index int) (foundKey, foundValue []byte, foundIndex int, existed bool)
@@ -48,6 +62,7 @@ type testClient interface {
 	SliceIndex(key []byte, min, max *int) (result []common.Item)
 	ReverseSlice(key, min, max []byte, mininc, maxinc bool) (result []common.Item)
 	Slice(key, min, max []byte, mininc, maxinc bool) (result []common.Item)
+	SubPrefixSlice(key, prefix []byte) (result []common.Item)
 	SliceLen(key, min []byte, mininc bool, maxRes int) (result []common.Item)
 	ReverseSliceLen(key, max []byte, maxinc bool, maxRes int) (result []common.Item)
 	SubMirrorPrev(key, subKey []byte) (prevKey, prevValue []byte, existed bool)
@@ -151,6 +166,18 @@ func testClientInterface(t *testing.T, dhashes []*Node, c testClient) {
 	testSubGetPutDel(t, c)
 	fmt.Println("  === Run testSubClear")
 	testSubClear(t, c)
+	fmt.Println("  === Run testTTL")
+	testTTL(t, c)
+	fmt.Println("  === Run testConditional")
+	testConditional(t, c)
+	fmt.Println("  === Run testSubConditional")
+	testSubConditional(t, c)
+	fmt.Println("  === Run testIncr")
+	testIncr(t, c)
+	fmt.Println("  === Run testSubIncr")
+	testSubIncr(t, c)
+	fmt.Println("  === Run testSubPrefix")
+	testSubPrefix(t, c)
 	fmt.Println("  === Run testIndices")
 	testIndices(t, dhashes, c)
 	if rc, ok := c.(*client.Conn); ok {
@@ -158,6 +185,40 @@ func testClientInterface(t *testing.T, dhashes []*Node, c testClient) {
 		testDump(t, rc)
 		fmt.Println("  === Run testSubDump")
 		testSubDump(t, rc)
+		fmt.Println("  === Run testTxn")
+		testTxn(t, dhashes, rc)
+		fmt.Println("  === Run testMultiPut")
+		testMultiPut(t, rc)
+		fmt.Println("  === Run testMultiGet")
+		testMultiGet(t, rc)
+		fmt.Println("  === Run testConsistency")
+		testConsistency(t, dhashes, rc)
+		fmt.Println("  === Run testReadRepair")
+		testReadRepair(t, dhashes, rc)
+		fmt.Println("  === Run testWatch")
+		testWatch(t, rc)
+		fmt.Println("  === Run testIndexSlice")
+		testIndexSlice(t, rc)
+		fmt.Println("  === Run testScan")
+		testScan(t, rc)
+		fmt.Println("  === Run testSubDelRange")
+		testSubDelRange(t, rc)
+		fmt.Println("  === Run testInvoke")
+		testInvoke(t, dhashes, rc)
+		fmt.Println("  === Run testCapped")
+		testCapped(t, dhashes, rc)
+		fmt.Println("  === Run testSubAggregate")
+		testSubAggregate(t, rc)
+		fmt.Println("  === Run testEncodings")
+		testEncodings(t, dhashes, rc)
+		fmt.Println("  === Run testJSONDocs")
+		testJSONDocs(t, rc)
+		fmt.Println("  === Run testGeo")
+		testGeo(t, rc)
+		fmt.Println("  === Run testSketches")
+		testSketches(t, rc)
+		fmt.Println("  === Run testBackup")
+		testBackup(t, dhashes, rc)
 	}
 	fmt.Println("  === Run testNextPrev")
 	testNextPrev(t, c)
@@ -658,6 +719,929 @@ func testSubClear(t *testing.T, c testClient) {
 	}
 }
 
+func testTTL(t *testing.T, c testClient) {
+	key := []byte("ttl")
+	value := []byte("value")
+	subTree := []byte("ttlTree")
+	c.SPutTTL(key, value, time.Millisecond*500)
+	if v, e := c.Get(key); bytes.Compare(value, v) != 0 || !e {
+		t.Errorf("should exist, but got %v => %v, %v", key, v, e)
+	}
+	for i := byte(0); i < 4; i++ {
+		c.SSubPutTTL(subTree, []byte{i}, []byte{i}, time.Millisecond*500)
+	}
+	c.SSubPut(subTree, []byte{4}, []byte{4})
+	if n := c.Count(subTree, nil, nil, true, true); n != 5 {
+		t.Errorf("wrong count, wanted %v but got %v", 5, n)
+	}
+	time.Sleep(time.Second)
+	if v, e := c.Get(key); v != nil || e {
+		t.Errorf("shouldn't exist, but got %v => %v, %v", key, v, e)
+	}
+	if v, e := c.SubGet(subTree, []byte{0}); v != nil || e {
+		t.Errorf("shouldn't exist, but got %v => %v, %v", []byte{0}, v, e)
+	}
+	if n := c.Count(subTree, nil, nil, true, true); n != 1 {
+		t.Errorf("wrong count, wanted %v but got %v", 1, n)
+	}
+	assertItems(t, c.Slice(subTree, nil, nil, true, true), []byte{4}, []byte{4})
+	c.SSubClear(subTree)
+}
+
+func testConditional(t *testing.T, c testClient) {
+	key := []byte("conditional")
+	if !c.SPutIfAbsent(key, []byte("a")) {
+		t.Errorf("should have put")
+	}
+	if c.SPutIfAbsent(key, []byte("b")) {
+		t.Errorf("shouldn't have put")
+	}
+	if c.SCompareAndSwap(key, []byte("b"), []byte("c")) {
+		t.Errorf("shouldn't have swapped")
+	}
+	if !c.SCompareAndSwap(key, []byte("a"), []byte("c")) {
+		t.Errorf("should have swapped")
+	}
+	if v, e := c.Get(key); bytes.Compare(v, []byte("c")) != 0 || !e {
+		t.Errorf("should exist, but got %v => %v, %v", key, v, e)
+	}
+	if c.SDelIfEqual(key, []byte("a")) {
+		t.Errorf("shouldn't have deleted")
+	}
+	if !c.SDelIfEqual(key, []byte("c")) {
+		t.Errorf("should have deleted")
+	}
+	if v, e := c.Get(key); v != nil || e {
+		t.Errorf("shouldn't exist, but got %v => %v, %v", key, v, e)
+	}
+	if c.SCompareAndSwap(key, []byte{}, []byte("d")) {
+		t.Errorf("shouldn't have swapped a missing value for an expected empty one")
+	}
+	c.SPut(key, []byte{})
+	if c.SPutIfAbsent(key, []byte("d")) {
+		t.Errorf("shouldn't have put over an empty value")
+	}
+	if !c.SCompareAndSwap(key, []byte{}, []byte("d")) {
+		t.Errorf("should have swapped an expected empty value")
+	}
+	c.SDel(key)
+}
+
+func testSubConditional(t *testing.T, c testClient) {
+	key := []byte("subConditional")
+	subKey := []byte("key")
+	if !c.SSubPutIfAbsent(key, subKey, []byte("a")) {
+		t.Errorf("should have put")
+	}
+	if c.SSubPutIfAbsent(key, subKey, []byte("b")) {
+		t.Errorf("shouldn't have put")
+	}
+	if c.SSubCompareAndSwap(key, subKey, []byte("b"), []byte("c")) {
+		t.Errorf("shouldn't have swapped")
+	}
+	if !c.SSubCompareAndSwap(key, subKey, []byte("a"), []byte("c")) {
+		t.Errorf("should have swapped")
+	}
+	if v, e := c.SubGet(key, subKey); bytes.Compare(v, []byte("c")) != 0 || !e {
+		t.Errorf("should exist, but got %v => %v, %v", subKey, v, e)
+	}
+	if c.SSubDelIfEqual(key, subKey, []byte("a")) {
+		t.Errorf("shouldn't have deleted")
+	}
+	if !c.SSubDelIfEqual(key, subKey, []byte("c")) {
+		t.Errorf("should have deleted")
+	}
+	if v, e := c.SubGet(key, subKey); v != nil || e {
+		t.Errorf("shouldn't exist, but got %v => %v, %v", subKey, v, e)
+	}
+}
+
+func testMultiPut(t *testing.T, c *client.Conn) {
+	var items []common.Item
+	for i := 0; i < 100; i++ {
+		items = append(items, common.Item{
+			Key:   murmur.HashString(fmt.Sprint("multi", i)),
+			Value: []byte(fmt.Sprint(i)),
+		})
+	}
+	subTree := []byte("multiSubPut")
+	items = append(items, common.Item{
+		Key:    subTree,
+		SubKey: []byte("x"),
+		Value:  []byte("y"),
+	})
+	for index, err := range c.SMultiPut(items) {
+		if err != nil {
+			t.Errorf("%v: %v", items[index], err)
+		}
+	}
+	for _, item := range items[:100] {
+		if v, e := c.Get(item.Key); bytes.Compare(v, item.Value) != 0 || !e {
+			t.Errorf("wanted %v, true but got %v, %v", item.Value, v, e)
+		}
+		c.SDel(item.Key)
+	}
+	if errs := c.SMultiSubPut(subTree, map[string][]byte{"a": []byte("1"), "b": []byte("2")}); errs != nil {
+		t.Errorf("wanted no errors but got %v", errs)
+	}
+	assertItems(t, c.Slice(subTree, nil, nil, true, true), []byte{'a', 'b', 'x'}, []byte{'1', '2', 'y'})
+	c.SSubClear(subTree)
+}
+
+func testConsistency(t *testing.T, dhashes []*Node, c *client.Conn) {
+	key := []byte("consistency")
+	value := []byte("value")
+	c.WithConsistency(client.One, client.All).Put(key, value)
+	holders := 0
+	for _, d := range dhashes {
+		if v, _, e := d.tree.Get(key); e && bytes.Compare(v, value) == 0 {
+			holders++
+		}
+	}
+	if holders != common.Redundancy {
+		t.Errorf("wanted %v nodes to have %v but %v had it", common.Redundancy, key, holders)
+	}
+	for _, consistency := range []client.Consistency{client.One, client.Quorum, client.All} {
+		if v, e := c.WithConsistency(consistency, consistency).Get(key); bytes.Compare(v, value) != 0 || !e {
+			t.Errorf("wanted %v, true but got %v, %v", value, v, e)
+		}
+	}
+	c.WithConsistency(client.Quorum, client.Quorum).Del(key)
+	if v, e := c.Get(key); v != nil || e {
+		t.Errorf("wanted nil, false but got %v, %v", v, e)
+	}
+}
+
+func assertChange(t *testing.T, changes chan common.Change, typ string, oldValue, newValue []byte) {
+	_, file, line, _ := runtime.Caller(1)
+	select {
+	case change := <-changes:
+		if change.Type != typ || bytes.Compare(change.OldValue, oldValue) != 0 || bytes.Compare(change.NewValue, newValue) != 0 {
+			t.Errorf("%v:%v: wanted %v, %v, %v but got %+v", file, line, typ, oldValue, newValue, change)
+		}
+	case <-time.After(time.Second * 2):
+		t.Errorf("%v:%v: wanted %v, %v, %v but got nothing", file, line, typ, oldValue, newValue)
+	}
+}
+
+func testWatch(t *testing.T, c *client.Conn) {
+	key := []byte("watched")
+	changes, stop := c.Watch(key)
+	defer stop()
+	c.SPut(key, []byte("a"))
+	c.SSubPut(key, []byte("sub"), []byte("x"))
+	c.SPut(key, []byte("b"))
+	c.SDel(key)
+	assertChange(t, changes, common.ChangePut, nil, []byte("a"))
+	assertChange(t, changes, common.ChangePut, []byte("a"), []byte("b"))
+	assertChange(t, changes, common.ChangeDel, []byte("b"), nil)
+	subChanges, subStop := c.WatchSub(key)
+	defer subStop()
+	c.SSubPut(key, []byte("sub"), []byte("y"))
+	c.SSubClear(key)
+	assertChange(t, subChanges, common.ChangePut, []byte("x"), []byte("y"))
+	assertChange(t, subChanges, common.ChangeClear, nil, nil)
+}
+
+func testReadRepair(t *testing.T, dhashes []*Node, c *client.Conn) {
+	key := []byte("readRepair")
+	c.SPut(key, []byte("old"))
+	var holders []*Node
+	var repairs int64
+	for _, d := range dhashes {
+		if _, _, e := d.tree.Get(key); e {
+			holders = append(holders, d)
+		}
+		repairs += d.Description().ReadRepairs
+	}
+	_, oldTimestamp, _ := holders[0].tree.Get(key)
+	expires := time.Now().Add(time.Hour).UnixNano()
+	holders[0].tree.PutExpires(key, []byte("new"), oldTimestamp+1, expires)
+	if v, e := c.Get(key); string(v) != "new" || !e {
+		t.Errorf("wanted new, true but got %v, %v", v, e)
+	}
+	common.AssertWithin(t, func() (string, bool) {
+		var newRepairs int64
+		for _, d := range dhashes {
+			newRepairs += d.Description().ReadRepairs
+		}
+		for _, d := range holders {
+			if v, _, exp, _ := d.tree.GetExpires(key); string(v) != "new" || exp != expires {
+				return fmt.Sprintf("%v has %s expiring at %v", d.GetBroadcastAddr(), v, exp), false
+			}
+		}
+		return fmt.Sprint(newRepairs - repairs), newRepairs-repairs == int64(len(holders)-1)
+	}, time.Second*5)
+	c.SDel(key)
+}
+
+func assertScan(t *testing.T, cursor *client.Cursor, keys map[string]bool, up bool, wanted int) {
+	var last []byte
+	found := 0
+	for cursor.Next() {
+		item := cursor.Item()
+		if last != nil && (bytes.Compare(last, item.Key) < 0) != up {
+			t.Errorf("wanted %v after %v", item.Key, last)
+		}
+		last = item.Key
+		if keys[string(item.Key)] {
+			found++
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		t.Errorf("scan failed: %v", err)
+	}
+	if found != wanted {
+		t.Errorf("wanted %v keys but found %v", wanted, found)
+	}
+}
+
+func testScan(t *testing.T, c *client.Conn) {
+	keys := make(map[string]bool)
+	var min, max []byte
+	for i := 0; i < 30; i++ {
+		key := murmur.HashString(fmt.Sprint("scan", i))
+		keys[string(key)] = true
+		c.SPut(key, []byte(fmt.Sprint(i)))
+		if i == 10 {
+			min = key
+		} else if i == 20 {
+			max = key
+		}
+	}
+	if bytes.Compare(min, max) > 0 {
+		min, max = max, min
+	}
+	between := 0
+	for key := range keys {
+		if bytes.Compare([]byte(key), min) > 0 && bytes.Compare([]byte(key), max) <= 0 {
+			between++
+		}
+	}
+	assertScan(t, c.Scan(nil, nil, nil, client.ScanOptions{PageSize: 4}), keys, true, 30)
+	assertScan(t, c.Scan(nil, nil, nil, client.ScanOptions{PageSize: 4, Reverse: true}), keys, false, 30)
+	assertScan(t, c.Scan(nil, min, max, client.ScanOptions{PageSize: 4, MaxInc: true}), keys, true, between)
+	assertScan(t, c.Scan(nil, min, max, client.ScanOptions{PageSize: 4, MaxInc: true, Reverse: true}), keys, false, between)
+	ring := common.NewRingNodes(c.Nodes())
+	ring.Add(common.Remote{Pos: murmur.HashString("scanDead"), Addr: "127.0.0.1:1"})
+	assertScan(t, client.NewConnRing(ring).Scan(nil, nil, nil, client.ScanOptions{PageSize: 4}), keys, true, 30)
+	for key := range keys {
+		c.SDel([]byte(key))
+	}
+	subTree := []byte("scanSub")
+	subKeys := make(map[string]bool)
+	for i := byte(0); i < 20; i++ {
+		subKeys[string([]byte{i})] = true
+		c.SSubPut(subTree, []byte{i}, []byte{i})
+	}
+	assertScan(t, c.Scan(subTree, nil, nil, client.ScanOptions{PageSize: 3}), subKeys, true, 20)
+	assertScan(t, c.Scan(subTree, []byte{3}, []byte{15}, client.ScanOptions{PageSize: 3, MinInc: true}), subKeys, true, 12)
+	assertScan(t, c.Scan(subTree, []byte{3}, []byte{15}, client.ScanOptions{PageSize: 3, MaxInc: true, Reverse: true}), subKeys, false, 12)
+	c.SSubClear(subTree)
+}
+
+func testEncodings(t *testing.T, dhashes []*Node, c *client.Conn) {
+	key := []byte("encodings")
+	c.SubAddConfiguration(key, common.KeyEncoding, common.EncodingString)
+	c.SubAddConfiguration(key, common.ValueEncoding, common.EncodingInt64)
+	if err := c.SSubPutChecked(key, []byte("a"), []byte("not an int64")); err == nil {
+		t.Errorf("wanted an error for a value that isn't an int64")
+	}
+	if err := c.SSubPutChecked(key, []byte{0xff}, setop.EncodeInt64(1)); err == nil {
+		t.Errorf("wanted an error for a key that isn't a string")
+	}
+	if err := c.SSubPutChecked(key, []byte("a"), setop.EncodeInt64(1)); err != nil {
+		t.Errorf("%v", err)
+	}
+	if n, err := c.SSubIncr(key, []byte("a"), 2); err != nil || n != 3 {
+		t.Errorf("wanted 3 but got %v, %v", n, err)
+	}
+	txn := c.Txn()
+	txn.SubPut(key, []byte("b"), []byte("not an int64"))
+	if err := txn.SCommit(); err == nil {
+		t.Errorf("wanted an error for a transaction writing a value that isn't an int64")
+	}
+	for _, d := range dhashes {
+		d.RegisterProcedure("badEncoding", func(proc *ProcedureContext, args []byte) ([]byte, error) {
+			return nil, proc.SubPut([]byte("c"), args)
+		})
+	}
+	if _, err := c.SInvoke("badEncoding", key, []byte("not an int64")); err == nil {
+		t.Errorf("wanted an error for a procedure writing a value that isn't an int64")
+	}
+	if err := c.GeoAdd(key, []byte("d"), 1, 2); err == nil {
+		t.Errorf("wanted an error for a geospatial member in a sub tree of int64s")
+	}
+	if n := c.SubSize(key); n != 1 {
+		t.Errorf("wanted 1 but got %v", n)
+	}
+	var res SubValueRes
+	if err := (*JSONApi)(dhashes[0]).SubGet(SubKeyReq{Key: key, SubKey: []byte("a")}, &res); err != nil || res.DecodedSubKey != "a" || res.DecodedValue != int64(3) {
+		t.Errorf("wanted a => 3 but got %+v, %v", res, err)
+	}
+	var slice []ValueRes
+	if err := (*JSONApi)(dhashes[1]).Slice(KeyRange{Key: key}, &slice); err != nil || len(slice) != 1 || slice[0].DecodedKey != "a" || slice[0].DecodedValue != int64(3) {
+		t.Errorf("wanted [a => 3] but got %+v, %v", slice, err)
+	}
+	c.SubAddConfiguration(key, common.ValueEncoding, common.EncodingString)
+	if _, err := c.SSubIncr(key, []byte("a"), 2); err == nil {
+		t.Errorf("wanted an error when incrementing strings")
+	}
+	c.SubAddConfiguration(key, common.ValueEncoding, common.EncodingJSON)
+	if _, err := c.SSubJSONSet(key, []byte{0xff}, "$.a", []byte("1")); err == nil {
+		t.Errorf("wanted an error for a JSON update of a key that isn't a string")
+	}
+	c.SSubClear(key)
+	c.SubAddConfiguration(key, common.KeyEncoding, "")
+	c.SubAddConfiguration(key, common.ValueEncoding, "")
+}
+
+func testSubAggregate(t *testing.T, c *client.Conn) {
+	key := []byte("aggregate")
+	for i := int64(1); i < 11; i++ {
+		c.SSubPut(key, []byte{byte(i)}, setop.EncodeInt64(i*i))
+	}
+	for _, test := range []struct {
+		fn     string
+		wanted []byte
+	}{
+		{common.AggregateSum, setop.EncodeInt64(4 + 9 + 16 + 25)},
+		{common.AggregateMin, setop.EncodeInt64(4)},
+		{common.AggregateMax, setop.EncodeInt64(25)},
+		{common.AggregateAvg, setop.EncodeFloat64(13.5)},
+	} {
+		if result, err := c.SubAggregate(key, []byte{2}, []byte{5}, true, true, test.fn, common.EncodingInt64); err != nil || !reflect.DeepEqual(result, test.wanted) {
+			t.Errorf("wanted %v of 2-5 to be %v but got %v, %v", test.fn, test.wanted, result, err)
+		}
+	}
+	if _, err := c.SubAggregate(key, nil, nil, true, true, common.AggregateSum, "string"); err == nil {
+		t.Errorf("wanted an error for an unknown encoding")
+	}
+	c.SSubClear(key)
+}
+
+func testCapped(t *testing.T, dhashes []*Node, c *client.Conn) {
+	key := []byte("capped")
+	c.SubAddConfiguration(key, "maxSize", "2")
+	for _, subKey := range []string{"a", "b", "c", "d"} {
+		c.SSubPut(key, []byte(subKey), []byte(subKey))
+	}
+	var found []string
+	for _, item := range c.SliceIndex(key, nil, nil) {
+		found = append(found, string(item.Value))
+	}
+	if wanted := []string{"c", "d"}; !reflect.DeepEqual(found, wanted) {
+		t.Errorf("wanted %v but got %v", wanted, found)
+	}
+	for _, d := range dhashes {
+		if size := d.tree.SubSize(key); size != 0 && size != 2 {
+			t.Errorf("%v should have 0 or 2 values in %s, but has %v", d, key, size)
+		}
+	}
+	c.SSubClear(key)
+	c.SubAddConfiguration(key, "maxSize", "")
+}
+
+func testInvoke(t *testing.T, dhashes []*Node, c *client.Conn) {
+	push := func(proc *ProcedureContext, args []byte) (result []byte, err error) {
+		var n int64
+		if value, existed := proc.Get(); existed {
+			if n, err = setop.DecodeInt64(value); err != nil {
+				return
+			}
+		}
+		n++
+		result = setop.EncodeInt64(n)
+		if err = proc.Put(result); err != nil {
+			return
+		}
+		if err = proc.SubPut(result, args); err != nil {
+			return
+		}
+		if proc.Tree().SubSize(proc.Key()) > 3 {
+			first, _, _, _ := proc.Tree().SubFirst(proc.Key())
+			err = proc.SubDel(first)
+		}
+		return
+	}
+	for _, d := range dhashes {
+		d.RegisterProcedure("push", push)
+		d.RegisterProcedure("panic", func(proc *ProcedureContext, args []byte) ([]byte, error) {
+			panic("oops")
+		})
+	}
+	key := []byte("invoke")
+	for _, value := range []string{"a", "b", "c", "d", "e"} {
+		if _, err := c.SInvoke("push", key, []byte(value)); err != nil {
+			t.Errorf("%v", err)
+		}
+	}
+	if value, existed := c.Get(key); !existed || !reflect.DeepEqual(value, setop.EncodeInt64(5)) {
+		t.Errorf("wanted %v but got %v, %v", setop.EncodeInt64(5), value, existed)
+	}
+	var found []string
+	for _, item := range c.SliceIndex(key, nil, nil) {
+		found = append(found, string(item.Value))
+	}
+	if wanted := []string{"c", "d", "e"}; !reflect.DeepEqual(found, wanted) {
+		t.Errorf("wanted %v but got %v", wanted, found)
+	}
+	if _, err := c.SInvoke("missing", key, nil); err == nil {
+		t.Errorf("wanted an error for a missing procedure")
+	}
+	if _, err := c.SInvoke("panic", key, nil); err == nil {
+		t.Errorf("wanted an error for a panicking procedure")
+	}
+	if _, err := c.SInvoke("push", key, []byte("f")); err != nil {
+		t.Errorf("wanted invocations to work after a panic, got %v", err)
+	}
+	started, release := make(chan bool), make(chan bool)
+	for _, d := range dhashes {
+		d.RegisterProcedure("hold", func(proc *ProcedureContext, args []byte) ([]byte, error) {
+			started <- true
+			<-release
+			return nil, proc.Put([]byte("held"))
+		})
+	}
+	held, put := make(chan bool), make(chan bool)
+	go func() {
+		if _, err := c.SInvoke("hold", key, nil); err != nil {
+			t.Errorf("%v", err)
+		}
+		held <- true
+	}()
+	<-started
+	go func() {
+		c.SPut(key, []byte("plain"))
+		put <- true
+	}()
+	otherKey := []byte("invoke2")
+	if _, err := c.SInvoke("push", otherKey, []byte("a")); err != nil {
+		t.Errorf("wanted procedures for other keys to run while one is held, got %v", err)
+	}
+	select {
+	case <-put:
+		t.Errorf("wanted a plain put to wait for the running procedure for its key")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+	<-held
+	<-put
+	if value, _ := c.Get(key); string(value) != "plain" {
+		t.Errorf("wanted the plain put to be done after the procedure, but got %v", string(value))
+	}
+	c.SDel(key)
+	c.SSubClear(key)
+	c.SDel(otherKey)
+	c.SSubClear(otherKey)
+}
+
+func testSketches(t *testing.T, c *client.Conn) {
+	var elements1, elements2 [][]byte
+	for i := 0; i < 1000; i++ {
+		elements1 = append(elements1, []byte(fmt.Sprint(i)))
+		elements2 = append(elements2, []byte(fmt.Sprint(i+500)))
+	}
+	if changed, err := c.SPFAdd([]byte("visitors1"), elements1...); err != nil || !changed {
+		t.Errorf("wanted a change but got %v, %v", changed, err)
+	}
+	if changed, err := c.SPFAdd([]byte("visitors1"), elements1[:10]...); err != nil || changed {
+		t.Errorf("wanted no change but got %v, %v", changed, err)
+	}
+	c.SPFAdd([]byte("visitors2"), elements2...)
+	if count, err := c.PFCount([]byte("visitors1")); err != nil || count < 950 || count > 1050 {
+		t.Errorf("wanted about 1000 but got %v, %v", count, err)
+	}
+	if err := c.SPFMerge([]byte("visitors"), []byte("visitors1"), []byte("visitors2"), []byte("missing")); err != nil {
+		t.Errorf("%v", err)
+	}
+	if count, err := c.PFCount([]byte("visitors")); err != nil || count < 1425 || count > 1575 {
+		t.Errorf("wanted about 1500 but got %v, %v", count, err)
+	}
+	c.SPut([]byte("notASketch"), []byte("plain"))
+	if _, err := c.SPFAdd([]byte("notASketch"), []byte("a")); err == nil {
+		t.Errorf("wanted an error adding to a plain value")
+	}
+	if added, err := c.SBFAdd([]byte("seen"), []byte("a"), []byte("b"), []byte("a")); err != nil || added != 2 {
+		t.Errorf("wanted 2 but got %v, %v", added, err)
+	}
+	if found, err := c.BFExists([]byte("seen"), []byte("a"), []byte("c"), []byte("b")); err != nil || !reflect.DeepEqual(found, []bool{true, false, true}) {
+		t.Errorf("wanted [true false true] but got %v, %v", found, err)
+	}
+	if err := c.SBFReserve([]byte("seen"), 100, 0.01); err == nil {
+		t.Errorf("wanted an error reserving an existing filter")
+	}
+	if err := c.SBFReserve([]byte("seenSmall"), 100, 0.01); err != nil {
+		t.Errorf("%v", err)
+	}
+	if found, err := c.BFExists([]byte("seenSmall"), []byte("a")); err != nil || !reflect.DeepEqual(found, []bool{false}) {
+		t.Errorf("wanted [false] but got %v, %v", found, err)
+	}
+}
+
+func testBackup(t *testing.T, dhashes []*Node, c *client.Conn) {
+	clearAll(dhashes)
+	for i := 0; i < 20; i++ {
+		c.SPut([]byte(fmt.Sprint("backup", i)), []byte(fmt.Sprint(i)))
+	}
+	c.SubAddConfiguration([]byte("backupTree"), "mirrored", "yes")
+	c.SSubPut([]byte("backupTree"), []byte("a"), []byte("b"))
+	buffer := new(bytes.Buffer)
+	if err := c.Backup(buffer); err != nil {
+		t.Fatalf("%v", err)
+	}
+	nodeBuffer := new(bytes.Buffer)
+	if err := dhashes[0].Backup(nodeBuffer); err != nil {
+		t.Fatalf("%v", err)
+	}
+	backup := buffer.Bytes()
+	clearAll(dhashes)
+	if _, existed := c.Get([]byte("backup0")); existed {
+		t.Errorf("backup0 should be cleared")
+	}
+	if err := c.Restore(bytes.NewReader(backup)); err != nil {
+		t.Fatalf("%v", err)
+	}
+	for i := 0; i < 20; i++ {
+		if value, existed := c.Get([]byte(fmt.Sprint("backup", i))); !existed || string(value) != fmt.Sprint(i) {
+			t.Errorf("wanted backup%v to be restored as %v, but got %v, %v", i, i, string(value), existed)
+		}
+	}
+	if value, existed := c.SubGet([]byte("backupTree"), []byte("a")); !existed || string(value) != "b" {
+		t.Errorf("wanted the sub tree to be restored, but got %v, %v", string(value), existed)
+	}
+	configured := false
+	for _, node := range dhashes {
+		if conf, _ := node.tree.SubConfiguration([]byte("backupTree")); conf["mirrored"] == "yes" {
+			configured = true
+		}
+	}
+	if !configured {
+		t.Errorf("wanted the sub tree configuration to be restored")
+	}
+	c.SPut([]byte("backup0"), []byte("newer"))
+	if err := c.Restore(bytes.NewReader(backup)); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if value, _ := c.Get([]byte("backup0")); string(value) != "newer" {
+		t.Errorf("wanted the restore to keep the newer backup0, but got %v", string(value))
+	}
+	clearAll(dhashes)
+	if err := dhashes[0].Restore(nodeBuffer); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if dhashes[0].tree.Size() == 0 {
+		t.Errorf("wanted the node backup to be restored into the node")
+	}
+}
+
+func assertGeoMembers(t *testing.T, members []common.GeoMember, err error, ordered bool, wanted ...string) {
+	var found []string
+	for _, member := range members {
+		found = append(found, string(member.Member))
+	}
+	if !ordered {
+		sort.Strings(found)
+	}
+	if err != nil || !reflect.DeepEqual(found, wanted) {
+		t.Errorf("wanted %v but got %v, %v", wanted, found, err)
+	}
+}
+
+func testGeo(t *testing.T, c *client.Conn) {
+	key := []byte("geo")
+	for member, pos := range map[string][2]float64{
+		"paris":      {48.8566, 2.3522},
+		"london":     {51.5074, -0.1278},
+		"versailles": {48.8049, 2.1204},
+		"suva":       {-18.1416, 178.4419},
+		"apia":       {-13.8333, -171.7667},
+	} {
+		if err := c.SGeoAdd(key, []byte(member), pos[0], pos[1]); err != nil {
+			t.Errorf("%v", err)
+		}
+	}
+	members, err := c.GeoRadius(key, 48.8566, 2.3522, 50000)
+	assertGeoMembers(t, members, err, true, "paris", "versailles")
+	if len(members) == 2 && (members[0].Distance != 0 || members[1].Distance < 17000 || members[1].Distance > 18000) {
+		t.Errorf("wanted versailles to be about 17.5km from paris, but got %v", members)
+	}
+	members, err = c.GeoRadius(key, 48.8566, 2.3522, 500000)
+	assertGeoMembers(t, members, err, true, "paris", "versailles", "london")
+	members, err = c.GeoBox(key, -20, 170, -10, -170)
+	assertGeoMembers(t, members, err, false, "apia", "suva")
+	if err := c.SGeoAdd(key, []byte("london"), 48.86, 2.35); err != nil {
+		t.Errorf("%v", err)
+	}
+	members, err = c.GeoBox(key, 48, 2, 49, 3)
+	assertGeoMembers(t, members, err, false, "london", "paris", "versailles")
+	members, err = c.GeoBox(key, 51, -1, 52, 0)
+	assertGeoMembers(t, members, err, false)
+	if !c.SGeoDel(key, []byte("london")) {
+		t.Errorf("wanted london to be deleted")
+	}
+	if c.SGeoDel(key, []byte("london")) {
+		t.Errorf("wanted london to already be deleted")
+	}
+	members, err = c.GeoRadius(key, 48.8566, 2.3522, 50000)
+	assertGeoMembers(t, members, err, true, "paris", "versailles")
+	if err := c.SGeoAdd(key, []byte("nowhere"), 91, 0); err == nil {
+		t.Errorf("wanted an error adding a member at latitude 91")
+	}
+}
+
+func assertJSONDoc(t *testing.T, doc []byte, err error, wanted string) {
+	if err != nil || string(doc) != wanted {
+		t.Errorf("wanted %v but got %v, %v", wanted, string(doc), err)
+	}
+}
+
+func testJSONDocs(t *testing.T, c *client.Conn) {
+	key := []byte("jsonDocs")
+	doc, err := c.SJSONSet(key, "$.a.b", []byte("1"))
+	assertJSONDoc(t, doc, err, `{"a":{"b":1}}`)
+	doc, err = c.SJSONSet(key, "$.c", []byte("[1,2]"))
+	assertJSONDoc(t, doc, err, `{"a":{"b":1},"c":[1,2]}`)
+	if value, found, err := c.JSONGet(key, "$.c.1"); err != nil || !found || string(value) != "2" {
+		t.Errorf("wanted 2 but got %v, %v, %v", string(value), found, err)
+	}
+	if value, found, err := c.JSONGet(key, "$.a.x"); err != nil || found {
+		t.Errorf("wanted nothing but got %v, %v, %v", string(value), found, err)
+	}
+	doc, err = c.SJSONMerge(key, []byte(`{"a":null,"d":{"e":"f"}}`))
+	assertJSONDoc(t, doc, err, `{"c":[1,2],"d":{"e":"f"}}`)
+	if value, _ := c.Get(key); string(value) != `{"c":[1,2],"d":{"e":"f"}}` {
+		t.Errorf("wanted the merged document but got %v", string(value))
+	}
+	subKey := []byte("doc")
+	doc, err = c.SSubJSONSet(key, subKey, "$.x", []byte(`"y"`))
+	assertJSONDoc(t, doc, err, `{"x":"y"}`)
+	doc, err = c.SSubJSONMerge(key, subKey, []byte(`{"z":true}`))
+	assertJSONDoc(t, doc, err, `{"x":"y","z":true}`)
+	if value, found, err := c.SubJSONGet(key, subKey, "$"); err != nil || !found || string(value) != `{"x":"y","z":true}` {
+		t.Errorf("wanted the sub document but got %v, %v, %v", string(value), found, err)
+	}
+	c.SPut(key, []byte("not json"))
+	if _, err := c.SJSONSet(key, "$.a", []byte("1")); err == nil {
+		t.Errorf("wanted an error setting a field in a value that isn't JSON")
+	}
+	if value, _ := c.Get(key); string(value) != "not json" {
+		t.Errorf("wanted the value to be untouched but got %v", string(value))
+	}
+}
+
+func testSubDelRange(t *testing.T, c *client.Conn) {
+	key := []byte("subDelRange")
+	for _, subKey := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		c.SSubPut(key, []byte(subKey), []byte(subKey))
+	}
+	if n := c.SSubDelRange(key, []byte("b"), []byte("d"), true, false); n != 2 {
+		t.Errorf("wanted 2 but got %v", n)
+	}
+	one, three := 1, 3
+	if n := c.SSubDelRangeIndex(key, &one, &three); n != 3 {
+		t.Errorf("wanted 3 but got %v", n)
+	}
+	var found []string
+	for _, item := range c.SliceIndex(key, nil, nil) {
+		found = append(found, string(item.Value))
+	}
+	if wanted := []string{"a", "g"}; !reflect.DeepEqual(found, wanted) {
+		t.Errorf("wanted %v but got %v", wanted, found)
+	}
+	if n := c.SSubDelRange(key, nil, nil, true, true); n != 2 {
+		t.Errorf("wanted 2 but got %v", n)
+	}
+	if n := c.SubSize(key); n != 0 {
+		t.Errorf("wanted 0 but got %v", n)
+	}
+}
+
+func testIndexSlice(t *testing.T, c *client.Conn) {
+	var subTrees [][]byte
+	for i := 0; i < 3; i++ {
+		subTree := murmur.HashString(fmt.Sprint("indexSlice", i))
+		subTrees = append(subTrees, subTree)
+		c.SubAddConfiguration(subTree, "index.email", "jsonpath:$.email")
+		c.SSubPut(subTree, []byte("a"), []byte(fmt.Sprintf(`{"email":"a%v@x"}`, i)))
+		c.SSubPut(subTree, []byte("b"), []byte(fmt.Sprintf(`{"email":"b%v@x"}`, i)))
+		c.SSubPut(subTree, []byte("c"), []byte(`{"name":"c"}`))
+	}
+	assertFields := func(result []common.IndexItem, wanted ...string) {
+		var found []string
+		for _, item := range result {
+			found = append(found, string(item.Field))
+		}
+		if !reflect.DeepEqual(found, wanted) {
+			t.Errorf("wanted %v but got %v", wanted, found)
+		}
+	}
+	assertFields(c.IndexSlice("email", nil, nil, true, true), "a0@x", "a1@x", "a2@x", "b0@x", "b1@x", "b2@x")
+	assertFields(c.IndexSlice("email", []byte("a1@x"), []byte("b1@x"), false, true), "a2@x", "b0@x", "b1@x")
+	c.SSubPut(subTrees[0], []byte("a"), []byte(`{"email":"c0@x"}`))
+	c.SSubDel(subTrees[1], []byte("b"))
+	assertFields(c.IndexSlice("email", []byte("b"), nil, true, true), "b0@x", "b2@x", "c0@x")
+	for _, subTree := range subTrees {
+		c.SSubClear(subTree)
+	}
+	assertFields(c.IndexSlice("email", nil, nil, true, true))
+}
+
+func testMultiGet(t *testing.T, c *client.Conn) {
+	var keys [][]byte
+	for i := 0; i < 50; i++ {
+		key := murmur.HashString(fmt.Sprint("multiGet", i))
+		keys = append(keys, key)
+		if i%2 == 0 {
+			c.SPut(key, []byte(fmt.Sprint(i)))
+		}
+	}
+	result := c.MultiGet(keys)
+	if len(result) != 25 {
+		t.Errorf("wanted 25 values but got %v", result)
+	}
+	for i, key := range keys {
+		if v, found := result[string(key)]; i%2 == 0 && string(v) != fmt.Sprint(i) {
+			t.Errorf("wanted %v under %v but got %v", i, key, v)
+		} else if i%2 == 1 && found {
+			t.Errorf("wanted nothing under %v but got %v", key, v)
+		}
+		c.SDel(key)
+	}
+	subTree := []byte("multiSubGet")
+	c.SSubPut(subTree, []byte("a"), []byte("1"))
+	c.SSubPut(subTree, []byte("b"), []byte("2"))
+	if result = c.MultiSubGet(subTree, [][]byte{[]byte("a"), []byte("b"), []byte("c")}); len(result) != 2 || string(result["a"]) != "1" || string(result["b"]) != "2" {
+		t.Errorf("wanted a: 1, b: 2 but got %v", result)
+	}
+	c.SSubClear(subTree)
+}
+
+func testTxn(t *testing.T, dhashes []*Node, c *client.Conn) {
+	user := []byte("txnUser")
+	index := []byte("txnIndex")
+	c.SPut(user, []byte("a"))
+	txn1 := c.Txn()
+	txn2 := c.Txn()
+	if v, e := txn1.Get(user); bytes.Compare(v, []byte("a")) != 0 || !e {
+		t.Errorf("wanted a, true but got %v, %v", v, e)
+	}
+	if v, e := txn2.Get(user); bytes.Compare(v, []byte("a")) != 0 || !e {
+		t.Errorf("wanted a, true but got %v, %v", v, e)
+	}
+	txn1.Put(user, []byte("b"))
+	txn1.SubPut(index, []byte("b"), user)
+	if v, e := txn1.Get(user); bytes.Compare(v, []byte("b")) != 0 || !e {
+		t.Errorf("wanted b, true but got %v, %v", v, e)
+	}
+	if err := txn1.SCommit(); err != nil {
+		t.Errorf("wanted nil but got %v", err)
+	}
+	txn2.Put(user, []byte("c"))
+	txn2.SubPut(index, []byte("c"), user)
+	if err := txn2.SCommit(); err != client.ErrTxnConflict {
+		t.Errorf("wanted %v but got %v", client.ErrTxnConflict, err)
+	}
+	if v, e := c.Get(user); bytes.Compare(v, []byte("b")) != 0 || !e {
+		t.Errorf("wanted b, true but got %v, %v", v, e)
+	}
+	if v, e := c.SubGet(index, []byte("b")); bytes.Compare(v, user) != 0 || !e {
+		t.Errorf("wanted %v, true but got %v, %v", user, v, e)
+	}
+	if v, e := c.SubGet(index, []byte("c")); v != nil || e {
+		t.Errorf("wanted nil, false but got %v, %v", v, e)
+	}
+	txn3 := c.Txn()
+	txn3.Del(user)
+	txn3.SubDel(index, []byte("b"))
+	if err := txn3.SCommit(); err != nil {
+		t.Errorf("wanted nil but got %v", err)
+	}
+	if v, e := c.Get(user); v != nil || e {
+		t.Errorf("wanted nil, false but got %v, %v", v, e)
+	}
+	if v, e := c.SubGet(index, []byte("b")); v != nil || e {
+		t.Errorf("wanted nil, false but got %v, %v", v, e)
+	}
+	key := []byte("txnInDoubt")
+	var owner *Node
+	for _, d := range dhashes {
+		if d.node.GetSuccessorFor(key).Addr == d.node.GetBroadcastAddr() {
+			owner = d
+		}
+	}
+	resolve := func(value string) (committed bool) {
+		txn := common.Txn{
+			Id:     []byte("txnInDoubt" + value),
+			Writes: []common.TxnOp{common.TxnOp{Key: key, Value: []byte(value)}},
+			Sync:   true,
+		}
+		var prepared bool
+		if err := owner.PrepareTxn(txn, &prepared); err != nil || !prepared {
+			t.Errorf("wanted true, nil but got %v, %v", prepared, err)
+		}
+		c.SPut(key, []byte("plain"))
+		if v, _ := c.Get(key); string(v) == "plain" {
+			t.Errorf("%v should have rejected a plain put of a prepared key", owner)
+		}
+		if c.SPutIfAbsent(key, []byte("plain")) {
+			t.Errorf("%v should have rejected a conditional put of a prepared key", owner)
+		}
+		if errs := c.SMultiPut([]common.Item{common.Item{Key: key, Value: []byte("plain")}}); errs[0] == nil {
+			t.Errorf("%v should have rejected a multi put of a prepared key", owner)
+		}
+		if _, err := c.SIncr(key, 1); err == nil {
+			t.Errorf("%v should have rejected an increment of a prepared key", owner)
+		}
+		if value == "a" {
+			if err := owner.DecideTxn(txn.Id, &committed); err != nil || !committed {
+				t.Errorf("wanted true, nil but got %v, %v", committed, err)
+			}
+		}
+		owner.txnLock.Lock()
+		owner.preparedTxns[string(txn.Id)].deadline = 0
+		owner.txnLock.Unlock()
+		owner.cleanTxns()
+		if err := owner.DecideTxn(txn.Id, &committed); err != nil {
+			t.Errorf("wanted nil but got %v", err)
+		}
+		return
+	}
+	if !resolve("a") {
+		t.Errorf("the in doubt transaction should have been committed")
+	}
+	if v, e := c.Get(key); string(v) != "a" || !e {
+		t.Errorf("wanted a, true but got %v, %v", v, e)
+	}
+	if resolve("b") {
+		t.Errorf("the undecided in doubt transaction should have been aborted")
+	}
+	if v, e := c.Get(key); string(v) != "a" || !e {
+		t.Errorf("wanted a, true but got %v, %v", v, e)
+	}
+	c.SPut(key, []byte("c"))
+	if v, e := c.Get(key); string(v) != "c" || !e {
+		t.Errorf("wanted c, true but got %v, %v", v, e)
+	}
+	c.SDel(key)
+}
+
+func testIncr(t *testing.T, c testClient) {
+	key := []byte("incr")
+	if n, err := c.SIncr(key, 3); err != nil || n != 3 {
+		t.Errorf("wanted 3, nil but got %v, %v", n, err)
+	}
+	if n, err := c.SIncr(key, -5); err != nil || n != -2 {
+		t.Errorf("wanted -2, nil but got %v, %v", n, err)
+	}
+	if v, e := c.Get(key); bytes.Compare(v, setop.EncodeInt64(-2)) != 0 || !e {
+		t.Errorf("wanted %v, true but got %v, %v", setop.EncodeInt64(-2), v, e)
+	}
+	c.SDel(key)
+}
+
+func testSubIncr(t *testing.T, c testClient) {
+	key := []byte("subIncr")
+	subKey := []byte("counter")
+	if n, err := c.SSubIncr(key, subKey, 3); err != nil || n != 3 {
+		t.Errorf("wanted 3, nil but got %v, %v", n, err)
+	}
+	if n, err := c.SSubIncr(key, subKey, 4); err != nil || n != 7 {
+		t.Errorf("wanted 7, nil but got %v, %v", n, err)
+	}
+	if v, e := c.SubGet(key, subKey); bytes.Compare(v, setop.EncodeInt64(7)) != 0 || !e {
+		t.Errorf("wanted %v, true but got %v, %v", setop.EncodeInt64(7), v, e)
+	}
+	c.SSubClear(key)
+}
+
+func testSubPrefix(t *testing.T, c testClient) {
+	key := []byte("subPrefix")
+	for _, subKey := range []string{"a", "ab", "abc", "abd", "b", "ba"} {
+		c.SSubPut(key, []byte(subKey), []byte(subKey))
+	}
+	var found []string
+	for _, item := range c.SubPrefixSlice(key, []byte("ab")) {
+		found = append(found, string(item.Value))
+	}
+	if wanted := []string{"ab", "abc", "abd"}; !reflect.DeepEqual(found, wanted) {
+		t.Errorf("wanted %v but got %v", wanted, found)
+	}
+	if n := c.SubPrefixCount(key, []byte("a")); n != 4 {
+		t.Errorf("wanted 4 but got %v", n)
+	}
+	if n := c.SSubPrefixDel(key, []byte("ab")); n != 3 {
+		t.Errorf("wanted 3 but got %v", n)
+	}
+	if n := c.SubPrefixCount(key, []byte("a")); n != 1 {
+		t.Errorf("wanted 1 but got %v", n)
+	}
+	if n := c.SubSize(key); n != 3 {
+		t.Errorf("wanted 3 but got %v", n)
+	}
+	c.SSubClear(key)
+}
+
 func testGetPutDel(t *testing.T, c testClient) {
 	var key []byte
 	var value []byte
@@ -10,6 +10,7 @@ import (
 	"github.com/zond/god/common"
 	"github.com/zond/god/discord"
 	"github.com/zond/god/murmur"
+	"github.com/zond/god/persistence"
 	"github.com/zond/god/radix"
 	"github.com/zond/god/timenet"
 )
@@ -65,6 +66,7 @@ type Node struct {
 	lastSync         int64
 	lastMigrate      int64
 	lastReroute      int64
+	readRepairs      int64
 	state            int32
 	lock             *sync.RWMutex
 	syncListeners    []SyncListener
@@ -75,6 +77,21 @@ type Node struct {
 	node             *discord.Node
 	timer            *timenet.Timer
 	tree             *radix.Tree
+	txnLock          *sync.Mutex
+	preparedTxns     map[string]*preparedTxn
+	txnKeys          map[string]string
+	txnDecisions     map[string]*txnDecision
+	txnLogger        *persistence.Logger
+	changeLock       *sync.Mutex
+	changeCond       *sync.Cond
+	changes          []common.Change
+	changeSeq        int64
+	procedures       map[string]Procedure
+	procKeys         map[string]*ProcedureContext // the running procedures by key, guarded by txnLock
+	procCond         *sync.Cond                   // broadcast on txnLock when a procedure finishes
+	geoLock          *sync.Mutex
+	snapshotLock     *sync.Mutex
+	snapshots        map[string]*backupSnapshot
 }
 
 func NewNode(listenAddr, broadcastAddr string) *Node {
@@ -83,12 +100,39 @@ func NewNode(listenAddr, broadcastAddr string) *Node {
 
 // NewNodeDir will return a dhash.Node publishing itself on the given address.
 func NewNodeDir(listenAddr, broadcastAddr, dir string) (result *Node) {
+	return NewNodeDirFsync(listenAddr, broadcastAddr, dir, persistence.FsyncPolicy{})
+}
+
+// NewNodeDirFsync will return a dhash.Node publishing itself on the given address, fsyncing its log in dir according to fsync.
+func NewNodeDirFsync(listenAddr, broadcastAddr, dir string, fsync persistence.FsyncPolicy) (result *Node) {
+	var logger *persistence.Logger
+	if dir != "" {
+		logger = persistence.NewLogger(dir).Fsync(fsync)
+	}
+	return NewNodeLogger(listenAddr, broadcastAddr, logger)
+}
+
+// NewNodeLogger will return a dhash.Node publishing itself on the given address, logging using logger unless it is nil.
+// Prepared transactions and transaction decisions are logged in a separate directory inside that of logger.
+func NewNodeLogger(listenAddr, broadcastAddr string, logger *persistence.Logger) (result *Node) {
 	result = &Node{
 		node:          discord.NewNode(listenAddr, broadcastAddr),
 		lock:          new(sync.RWMutex),
 		commListeners: make(map[*commListenerContainer]bool),
 		state:         created,
+		txnLock:       new(sync.Mutex),
+		preparedTxns:  make(map[string]*preparedTxn),
+		txnKeys:       make(map[string]string),
+		txnDecisions:  make(map[string]*txnDecision),
+		changeLock:    new(sync.Mutex),
+		procedures:    make(map[string]Procedure),
+		procKeys:      make(map[string]*ProcedureContext),
+		geoLock:       new(sync.Mutex),
+		snapshotLock:  new(sync.Mutex),
+		snapshots:     make(map[string]*backupSnapshot),
 	}
+	result.changeCond = sync.NewCond(result.changeLock)
+	result.procCond = sync.NewCond(result.txnLock)
 	result.node.AddCommListener(func(source, dest common.Remote, typ string) bool {
 		if result.hasState(started) {
 			if result.hasCommListeners() {
@@ -107,9 +151,13 @@ func NewNodeDir(listenAddr, broadcastAddr, dir string) (result *Node) {
 	})
 	result.timer = timenet.NewTimer((*dhashPeerProducer)(result))
 	result.tree = radix.NewTreeTimer(result.timer)
-	if dir != "" {
-		result.tree.Log(dir).Restore()
+	if logger != nil {
+		result.tree.LogWith(logger).Restore()
+		result.txnLogger = logger.Sub(txnLogDir)
+		result.txnLogger.Play(result.restoreTxn)
+		<-result.txnLogger.Record()
 	}
+	result.tree.SetChangeListener(result.recordChange)
 	result.node.Export("Timenet", (*timerServer)(result.timer))
 	result.node.Export("DHash", (*dhashServer)(result))
 	result.node.Export("HashTree", (*hashTreeServer)(result))
@@ -365,6 +413,8 @@ func (self *Node) triggerCleanListeners(source, dest common.Remote, cleaned, pus
 	self.cleanListeners = newListeners
 }
 func (self *Node) clean() {
+	self.tree.Expire()
+	self.cleanTxns()
 	selfRemote := self.node.Remote()
 	var cleaned int
 	var pushed int
@@ -2,6 +2,7 @@ package dhash
 
 import (
 	"github.com/zond/god/common"
+	"github.com/zond/god/persistence"
 	"github.com/zond/setop"
 )
 
@@ -20,12 +21,24 @@ func (self *dhashServer) SlaveSubClear(data common.Item, x *int) error {
 func (self *dhashServer) SlaveSubDel(data common.Item, x *int) error {
 	return (*Node)(self).subDel(data)
 }
+func (self *dhashServer) SlaveSubPrefixDel(data common.Item, x *int) error {
+	return (*Node)(self).subPrefixDel(data, x)
+}
+func (self *dhashServer) SlaveSubDelRange(op common.RangeOp, x *int) error {
+	return (*Node)(self).subDelRange(op, x)
+}
 func (self *dhashServer) SlaveDel(data common.Item, x *int) error {
 	return (*Node)(self).del(data)
 }
 func (self *dhashServer) SlavePut(data common.Item, x *int) error {
 	return (*Node)(self).put(data)
 }
+func (self *dhashServer) SlaveMultiPut(items []common.Item, x *int) error {
+	return (*Node)(self).multiPut(items)
+}
+func (self *dhashServer) MultiPut(items []common.Item, errs *[]string) error {
+	return (*Node)(self).MultiPut(items, errs)
+}
 func (self *dhashServer) SubDel(data common.Item, x *int) error {
 	return (*Node)(self).SubDel(data)
 }
@@ -41,6 +54,92 @@ func (self *dhashServer) Del(data common.Item, x *int) error {
 func (self *dhashServer) Put(data common.Item, x *int) error {
 	return (*Node)(self).Put(data)
 }
+func (self *dhashServer) CompareAndSwap(data common.Item, swapped *bool) error {
+	return (*Node)(self).CompareAndSwap(data, swapped)
+}
+func (self *dhashServer) DelIfEqual(data common.Item, deleted *bool) error {
+	return (*Node)(self).DelIfEqual(data, deleted)
+}
+func (self *dhashServer) SubCompareAndSwap(data common.Item, swapped *bool) error {
+	return (*Node)(self).SubCompareAndSwap(data, swapped)
+}
+func (self *dhashServer) SubDelIfEqual(data common.Item, deleted *bool) error {
+	return (*Node)(self).SubDelIfEqual(data, deleted)
+}
+func (self *dhashServer) SubPrefixDel(data common.Item, deleted *int) error {
+	return (*Node)(self).SubPrefixDel(data, deleted)
+}
+func (self *dhashServer) JSONGet(op common.JSONOp, result *common.Item) error {
+	return (*Node)(self).JSONGet(op, result)
+}
+func (self *dhashServer) JSONSet(op common.JSONOp, result *[]byte) error {
+	return (*Node)(self).JSONSet(op, result)
+}
+func (self *dhashServer) JSONMerge(op common.JSONOp, result *[]byte) error {
+	return (*Node)(self).JSONMerge(op, result)
+}
+func (self *dhashServer) GeoAdd(op common.GeoOp, x *int) error {
+	return (*Node)(self).GeoAdd(op)
+}
+func (self *dhashServer) GeoDel(op common.GeoOp, deleted *bool) error {
+	return (*Node)(self).GeoDel(op, deleted)
+}
+func (self *dhashServer) GeoRadius(q common.GeoRadiusQuery, result *[]common.GeoMember) error {
+	return (*Node)(self).GeoRadius(q, result)
+}
+func (self *dhashServer) GeoBox(q common.GeoBoxQuery, result *[]common.GeoMember) error {
+	return (*Node)(self).GeoBox(q, result)
+}
+func (self *dhashServer) PFAdd(op common.SketchOp, changed *bool) error {
+	return (*Node)(self).PFAdd(op, changed)
+}
+func (self *dhashServer) PFCount(op common.SketchOp, result *int) error {
+	return (*Node)(self).PFCount(op, result)
+}
+func (self *dhashServer) PFMerge(op common.SketchOp, x *int) error {
+	return (*Node)(self).PFMerge(op, x)
+}
+func (self *dhashServer) BFReserve(op common.SketchOp, x *int) error {
+	return (*Node)(self).BFReserve(op, x)
+}
+func (self *dhashServer) BFAdd(op common.SketchOp, added *int) error {
+	return (*Node)(self).BFAdd(op, added)
+}
+func (self *dhashServer) BFExists(op common.SketchOp, result *[]bool) error {
+	return (*Node)(self).BFExists(op, result)
+}
+func (self *dhashServer) Invoke(inv common.Invocation, result *[]byte) error {
+	return (*Node)(self).Invoke(inv, result)
+}
+func (self *dhashServer) SubDelRange(op common.RangeOp, deleted *int) error {
+	return (*Node)(self).SubDelRange(op, deleted)
+}
+func (self *dhashServer) PrepareTxn(txn common.Txn, prepared *bool) error {
+	return (*Node)(self).PrepareTxn(txn, prepared)
+}
+func (self *dhashServer) CommitTxn(id []byte, x *int) error {
+	return (*Node)(self).CommitTxn(id)
+}
+func (self *dhashServer) AbortTxn(id []byte, x *int) error {
+	(*Node)(self).AbortTxn(id)
+	return nil
+}
+func (self *dhashServer) DecideTxn(id []byte, committed *bool) error {
+	return (*Node)(self).DecideTxn(id, committed)
+}
+func (self *dhashServer) ResolveTxn(id []byte, committed *bool) error {
+	return (*Node)(self).ResolveTxn(id, committed)
+}
+func (self *dhashServer) SlaveDecideTxn(data common.Item, x *int) error {
+	(*Node)(self).SlaveDecideTxn(data)
+	return nil
+}
+func (self *dhashServer) Incr(data common.Item, result *int64) error {
+	return (*Node)(self).Incr(data, result)
+}
+func (self *dhashServer) SubIncr(data common.Item, result *int64) error {
+	return (*Node)(self).SubIncr(data, result)
+}
 func (self *dhashServer) RingHash(x int, result *[]byte) error {
 	return (*Node)(self).RingHash(x, result)
 }
@@ -50,6 +149,15 @@ func (self *dhashServer) MirrorCount(r common.Range, result *int) error {
 func (self *dhashServer) Count(r common.Range, result *int) error {
 	return (*Node)(self).Count(r, result)
 }
+func (self *dhashServer) SubAggregate(a common.Aggregation, result *[]byte) error {
+	return (*Node)(self).SubAggregate(a, result)
+}
+func (self *dhashServer) SubPrefixCount(r common.Range, result *int) error {
+	return (*Node)(self).SubPrefixCount(r, result)
+}
+func (self *dhashServer) SubPrefixSlice(r common.Range, result *[]common.Item) error {
+	return (*Node)(self).SubPrefixSlice(r, result)
+}
 func (self *dhashServer) Next(data common.Item, result *common.Item) error {
 	return (*Node)(self).Next(data, result)
 }
@@ -62,6 +170,24 @@ func (self *dhashServer) SubGet(data common.Item, result *common.Item) error {
 func (self *dhashServer) Get(data common.Item, result *common.Item) error {
 	return (*Node)(self).Get(data, result)
 }
+func (self *dhashServer) Repair(data common.Item, x *int) error {
+	return (*Node)(self).Repair(data)
+}
+func (self *dhashServer) SubRepair(data common.Item, x *int) error {
+	return (*Node)(self).SubRepair(data)
+}
+func (self *dhashServer) Watch(w common.Watch, result *common.WatchResult) error {
+	return (*Node)(self).Watch(w, result)
+}
+func (self *dhashServer) IndexSlice(r common.Range, result *[]common.IndexItem) error {
+	return (*Node)(self).IndexSlice(r, result)
+}
+func (self *dhashServer) MultiGet(items []common.Item, result *[]common.Item) error {
+	return (*Node)(self).MultiGet(items, result)
+}
+func (self *dhashServer) MultiSubGet(items []common.Item, result *[]common.Item) error {
+	return (*Node)(self).MultiSubGet(items, result)
+}
 func (self *dhashServer) Size(x int, result *int) error {
 	*result = (*Node)(self).Size()
 	return nil
@@ -165,6 +291,12 @@ func (self *dhashServer) SliceLen(r common.Range, result *[]common.Item) error {
 func (self *dhashServer) ReverseSliceLen(r common.Range, result *[]common.Item) error {
 	return (*Node)(self).ReverseSliceLen(r, result)
 }
+func (self *dhashServer) ScanLen(r common.Range, result *[]common.Item) error {
+	return (*Node)(self).ScanLen(r, result)
+}
+func (self *dhashServer) ReverseScanLen(r common.Range, result *[]common.Item) error {
+	return (*Node)(self).ReverseScanLen(r, result)
+}
 func (self *dhashServer) SetExpression(expr setop.SetExpression, items *[]setop.SetOpResult) error {
 	return (*Node)(self).SetExpression(expr, items)
 }
@@ -191,3 +323,18 @@ func (self *dhashServer) SubConfiguration(key []byte, result *common.Conf) error
 	(*result).Data, (*result).Timestamp = (*Node)(self).tree.SubConfiguration(key)
 	return nil
 }
+func (self *dhashServer) Snapshot(name []byte, x *int) error {
+	(*Node)(self).Snapshot(name)
+	return nil
+}
+func (self *dhashServer) ReleaseSnapshot(name []byte, x *int) error {
+	(*Node)(self).ReleaseSnapshot(name)
+	return nil
+}
+func (self *dhashServer) BackupPage(r common.SnapshotRange, result *[]persistence.Op) error {
+	return (*Node)(self).BackupPage(r, result)
+}
+func (self *dhashServer) Restore(ops []persistence.Op, x *int) error {
+	(*Node)(self).RestoreOps(ops)
+	return nil
+}
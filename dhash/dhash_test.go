@@ -133,3 +133,60 @@ func TestDHash(t *testing.T) {
 	testPut(t, dhashes)
 	testMigrate(t, dhashes)
 }
+
+func TestWatchBuffer(t *testing.T) {
+	node := NewNodeLogger("127.0.0.1:29000", "127.0.0.1:29000", nil)
+	for i := 0; i < maxBufferedChanges+10; i++ {
+		node.recordChange(common.Change{Key: []byte(fmt.Sprint(i))})
+	}
+	var seqs []int64
+	node.eachChange(0, func(change common.Change) {
+		if string(change.Key) != fmt.Sprint(change.Seq-1) {
+			t.Errorf("change %v should have key %v", change, change.Seq-1)
+		}
+		seqs = append(seqs, change.Seq)
+	})
+	if len(seqs) != maxBufferedChanges || seqs[0] != 11 || seqs[len(seqs)-1] != maxBufferedChanges+10 {
+		t.Errorf("wanted %v changes from %v to %v, but got %v from %v to %v", maxBufferedChanges, 11, maxBufferedChanges+10, len(seqs), seqs[0], seqs[len(seqs)-1])
+	}
+	seqs = nil
+	node.eachChange(maxBufferedChanges+5, func(change common.Change) {
+		seqs = append(seqs, change.Seq)
+	})
+	if len(seqs) != 5 || seqs[0] != maxBufferedChanges+6 {
+		t.Errorf("wanted 5 changes from %v, but got %v", maxBufferedChanges+6, seqs)
+	}
+}
+
+func TestTxnLog(t *testing.T) {
+	dir := "txnlog"
+	os.RemoveAll(dir)
+	defer os.RemoveAll(dir)
+	node := NewNodeDir("127.0.0.1:29002", "127.0.0.1:29002", dir)
+	node.txnLock.Lock()
+	node.lockTxn(&preparedTxn{
+		txn:      common.Txn{Id: []byte("a"), Writes: []common.TxnOp{common.TxnOp{Key: []byte("k1"), Value: []byte("v")}}},
+		deadline: 1,
+	})
+	node.lockTxn(&preparedTxn{
+		txn:      common.Txn{Id: []byte("b"), Writes: []common.TxnOp{common.TxnOp{Key: []byte("k2"), Value: []byte("v")}}},
+		deadline: 1,
+	})
+	node.releaseTxn(node.preparedTxns["b"])
+	node.recordDecision("c", &txnDecision{commit: true, deadline: 2})
+	node.txnLock.Unlock()
+	node.txnLogger.Stop()
+	restarted := NewNodeDir("127.0.0.1:29004", "127.0.0.1:29004", dir)
+	if prepared, found := restarted.preparedTxns["a"]; !found || prepared.deadline != 1 || string(prepared.txn.Writes[0].Value) != "v" {
+		t.Errorf("wanted the prepared transaction a, but got %+v", prepared)
+	}
+	if id := restarted.txnKeys[txnLockKey(common.TxnOp{Key: []byte("k1")})]; id != "a" {
+		t.Errorf("wanted k1 locked by a, but got %#v", id)
+	}
+	if _, found := restarted.preparedTxns["b"]; found || len(restarted.txnKeys) != 1 {
+		t.Errorf("wanted b to be released, but got %v", restarted.txnKeys)
+	}
+	if decision, found := restarted.txnDecisions["c"]; !found || !decision.commit || decision.deadline != 2 {
+		t.Errorf("wanted c to be committed, but got %+v", decision)
+	}
+}
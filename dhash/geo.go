@@ -0,0 +1,184 @@
+package dhash
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/zond/god/common"
+)
+
+const (
+	geoPositionPrefix = 'g'
+	geoMemberPrefix   = 'm'
+)
+
+// geoPositionKey returns the sub key of member at hash, which sorts geohash first so that nearby members are stored near each other.
+func geoPositionKey(hash uint64, member []byte) (result []byte) {
+	result = make([]byte, 9, 9+len(member))
+	result[0] = geoPositionPrefix
+	binary.BigEndian.PutUint64(result[1:], hash)
+	return append(result, member...)
+}
+
+// geoMemberKey returns the sub key that contains the geohash of member, so that its position key can be found when it moves or is removed.
+func geoMemberKey(member []byte) []byte {
+	return append([]byte{geoMemberPrefix}, member...)
+}
+
+func geoEncode(lat, lon float64) (result []byte) {
+	result = make([]byte, 16)
+	binary.BigEndian.PutUint64(result, math.Float64bits(lat))
+	binary.BigEndian.PutUint64(result[8:], math.Float64bits(lon))
+	return
+}
+
+func geoDecode(b []byte) (lat, lon float64) {
+	if len(b) == 16 {
+		lat = math.Float64frombits(binary.BigEndian.Uint64(b))
+		lon = math.Float64frombits(binary.BigEndian.Uint64(b[8:]))
+	}
+	return
+}
+
+// GeoAdd will put op.Member at op.Lat and op.Lon in the geospatial sub tree op.Key, moving it if it already was there, and replicate the change.
+// Nothing is changed if the sub keys or values of the change aren't encoded like the configuration of the sub tree requires.
+func (self *Node) GeoAdd(op common.GeoOp) (err error) {
+	var x int
+	var f bool
+	if f, err = self.forwardUnlessOwner("DHash.GeoAdd", op.Key, op, &x); f {
+		return
+	}
+	if err = common.ValidateGeo(op.Lat, op.Lon); err != nil {
+		return
+	}
+	self.geoLock.Lock()
+	defer self.geoLock.Unlock()
+	data := common.Item{
+		Key:       op.Key,
+		Sync:      op.Sync,
+		Acks:      op.Acks,
+		TTL:       self.node.Redundancy(),
+		Timestamp: self.timer.ContinuousTime(),
+	}
+	hash := common.GeoHash(op.Lat, op.Lon)
+	position := data
+	position.SubKey, position.Value = geoPositionKey(hash, op.Member), geoEncode(op.Lat, op.Lon)
+	member := data
+	member.SubKey, member.Value = geoMemberKey(op.Member), make([]byte, 8)
+	binary.BigEndian.PutUint64(member.Value, hash)
+	var moved *common.Item
+	if oldHash, _, existed := self.tree.SubGet(op.Key, member.SubKey); existed && len(oldHash) == 8 {
+		if oldPositionKey := geoPositionKey(binary.BigEndian.Uint64(oldHash), op.Member); !bytes.Equal(oldPositionKey, position.SubKey) {
+			old := data
+			old.SubKey = oldPositionKey
+			moved = &old
+		}
+	}
+	ops := []common.TxnOp{itemTxnOp(position), itemTxnOp(member)}
+	if moved != nil {
+		ops = append(ops, delTxnOp(*moved))
+	}
+	if err = self.unlessPrepared(func() {
+		if moved != nil {
+			self.tree.SubFakeDel(moved.Key, moved.SubKey, moved.Timestamp)
+		}
+		self.tree.SubPutExpires(position.Key, position.SubKey, position.Value, position.Timestamp, expires(position))
+		self.tree.SubPutExpires(member.Key, member.SubKey, member.Value, member.Timestamp, expires(member))
+	}, ops...); err != nil {
+		return
+	}
+	if moved != nil {
+		self.replicate(*moved, "DHash.SlaveSubDel")
+	}
+	self.replicate(position, "DHash.SlaveSubPut")
+	self.replicate(member, "DHash.SlaveSubPut")
+	return
+}
+
+// GeoDel will remove op.Member from the geospatial sub tree op.Key, replicate the change, and set deleted to whether it was there.
+func (self *Node) GeoDel(op common.GeoOp, deleted *bool) (err error) {
+	var f bool
+	if f, err = self.forwardUnlessOwner("DHash.GeoDel", op.Key, op, deleted); f {
+		return
+	}
+	self.geoLock.Lock()
+	defer self.geoLock.Unlock()
+	data := common.Item{
+		Key:       op.Key,
+		Sync:      op.Sync,
+		Acks:      op.Acks,
+		TTL:       self.node.Redundancy(),
+		Timestamp: self.timer.ContinuousTime(),
+	}
+	var hash []byte
+	if hash, _, *deleted = self.tree.SubGet(op.Key, geoMemberKey(op.Member)); *deleted {
+		if len(hash) == 8 {
+			data.SubKey = geoPositionKey(binary.BigEndian.Uint64(hash), op.Member)
+			if err = self.subDelUnlessPrepared(data, nil); err != nil {
+				return
+			}
+		}
+		data.SubKey = geoMemberKey(op.Member)
+		err = self.subDelUnlessPrepared(data, nil)
+	}
+	return
+}
+
+// geoScan will call f with each member whose geohash is inside ranges in the geospatial sub tree key.
+func (self *Node) geoScan(key []byte, ranges []common.GeoHashRange, f func(member common.GeoMember)) {
+	for _, r := range ranges {
+		self.tree.SubEachBetween(key, geoPositionKey(r.Min, nil), geoPositionKey(r.Max, nil), true, false, func(subKey, value []byte, timestamp int64) bool {
+			member := common.GeoMember{
+				Member: subKey[9:],
+			}
+			member.Lat, member.Lon = geoDecode(value)
+			f(member)
+			return true
+		})
+	}
+}
+
+type geoMembers []common.GeoMember
+
+func (self geoMembers) Len() int           { return len(self) }
+func (self geoMembers) Less(i, j int) bool { return self[i].Distance < self[j].Distance }
+func (self geoMembers) Swap(i, j int)      { self[i], self[j] = self[j], self[i] }
+
+// GeoRadius will set result to the members within q.Radius meters of q.Lat and q.Lon in the geospatial sub tree q.Key, nearest first.
+func (self *Node) GeoRadius(q common.GeoRadiusQuery, result *[]common.GeoMember) (err error) {
+	if err = common.ValidateGeo(q.Lat, q.Lon); err != nil {
+		return
+	}
+	if !(q.Radius >= 0) {
+		return fmt.Errorf("%v is not a valid radius", q.Radius)
+	}
+	self.geoScan(q.Key, common.GeoRadiusCover(q.Lat, q.Lon, q.Radius), func(member common.GeoMember) {
+		if member.Distance = common.GeoDistance(q.Lat, q.Lon, member.Lat, member.Lon); member.Distance <= q.Radius {
+			*result = append(*result, member)
+		}
+	})
+	sort.Stable(geoMembers(*result))
+	return
+}
+
+// GeoBox will set result to the members between q.MinLat and q.MaxLat, and between q.MinLon and q.MaxLon, in the geospatial sub tree q.Key.
+func (self *Node) GeoBox(q common.GeoBoxQuery, result *[]common.GeoMember) (err error) {
+	if err = common.ValidateGeo(q.MinLat, q.MinLon); err != nil {
+		return
+	}
+	if err = common.ValidateGeo(q.MaxLat, q.MaxLon); err != nil {
+		return
+	}
+	if q.MinLat > q.MaxLat {
+		return fmt.Errorf("%v is greater than %v", q.MinLat, q.MaxLat)
+	}
+	self.geoScan(q.Key, common.GeoBoxCover(q.MinLat, q.MinLon, q.MaxLat, q.MaxLon), func(member common.GeoMember) {
+		if common.GeoBoxContains(q.MinLat, q.MinLon, q.MaxLat, q.MaxLon, member.Lat, member.Lon) {
+			*result = append(*result, member)
+		}
+	})
+	return
+}
@@ -14,6 +14,8 @@ type HashTreeItem struct {
 	Expected  int64
 	Value     []byte
 	Exists    bool
+	Expires   int64
+	Mergeable bool
 }
 
 type hashTreeServer Node
@@ -44,7 +46,7 @@ func (self *hashTreeServer) GetTimestamp(key []radix.Nibble, result *HashTreeIte
 }
 func (self *hashTreeServer) PutTimestamp(data HashTreeItem, changed *bool) error {
 	atomic.StoreInt64(&(*Node)(self).lastSync, time.Now().UnixNano())
-	*changed = (*Node)(self).tree.PutTimestamp(data.Key, data.Value, data.Exists, data.Expected, data.Timestamp)
+	*changed = (*Node)(self).tree.PutTimestamp(data.Key, data.Value, data.Exists, data.Expected, data.Timestamp, data.Expires, data.Mergeable)
 	return nil
 }
 func (self *hashTreeServer) DelTimestamp(data HashTreeItem, changed *bool) error {
@@ -64,7 +66,7 @@ func (self *hashTreeServer) SubGetTimestamp(data HashTreeItem, result *HashTreeI
 }
 func (self *hashTreeServer) SubPutTimestamp(data HashTreeItem, changed *bool) error {
 	atomic.StoreInt64(&(*Node)(self).lastSync, time.Now().UnixNano())
-	*changed = (*Node)(self).tree.SubPutTimestamp(data.Key, data.SubKey, data.Value, data.Exists, data.Expected, data.Timestamp)
+	*changed = (*Node)(self).tree.SubPutTimestamp(data.Key, data.SubKey, data.Value, data.Exists, data.Expected, data.Timestamp, data.Expires)
 	return nil
 }
 func (self *hashTreeServer) SubDelTimestamp(data HashTreeItem, changed *bool) error {
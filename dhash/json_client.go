@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os/exec"
+	"time"
 
 	"github.com/zond/god/common"
 	"github.com/zond/setop"
@@ -85,6 +86,141 @@ func (self JSONClient) Put(key, value []byte) {
 	}
 	self.call("Put", item, &x)
 }
+func (self JSONClient) SSubPutTTL(key, subKey, value []byte, ttl time.Duration) {
+	var x Nothing
+	item := SubValueOp{
+		Key:    key,
+		SubKey: subKey,
+		Value:  value,
+		Sync:   true,
+		TTL:    ttl,
+	}
+	self.call("SubPut", item, &x)
+}
+func (self JSONClient) SubPutTTL(key, subKey, value []byte, ttl time.Duration) {
+	var x Nothing
+	item := SubValueOp{
+		Key:    key,
+		SubKey: subKey,
+		Value:  value,
+		TTL:    ttl,
+	}
+	self.call("SubPut", item, &x)
+}
+func (self JSONClient) SPutTTL(key, value []byte, ttl time.Duration) {
+	var x Nothing
+	item := ValueOp{
+		Key:   key,
+		Value: value,
+		Sync:  true,
+		TTL:   ttl,
+	}
+	self.call("Put", item, &x)
+}
+func (self JSONClient) PutTTL(key, value []byte, ttl time.Duration) {
+	var x Nothing
+	item := ValueOp{
+		Key:   key,
+		Value: value,
+		TTL:   ttl,
+	}
+	self.call("Put", item, &x)
+}
+func (self JSONClient) SSubIncr(key, subKey []byte, delta int64) (result int64, err error) {
+	item := SubIncrOp{
+		Key:    key,
+		SubKey: subKey,
+		Delta:  delta,
+		Sync:   true,
+	}
+	self.call("SubIncr", item, &result)
+	return
+}
+func (self JSONClient) SIncr(key []byte, delta int64) (result int64, err error) {
+	item := IncrOp{
+		Key:   key,
+		Delta: delta,
+		Sync:  true,
+	}
+	self.call("Incr", item, &result)
+	return
+}
+func (self JSONClient) SSubCompareAndSwap(key, subKey, expected, value []byte) (result bool) {
+	item := SubCASOp{
+		Key:      key,
+		SubKey:   subKey,
+		Expected: expected,
+		Value:    value,
+		Sync:     true,
+	}
+	self.call("SubCompareAndSwap", item, &result)
+	return
+}
+func (self JSONClient) SSubPutIfAbsent(key, subKey, value []byte) (result bool) {
+	item := SubValueOp{
+		Key:    key,
+		SubKey: subKey,
+		Value:  value,
+		Sync:   true,
+	}
+	self.call("SubPutIfAbsent", item, &result)
+	return
+}
+func (self JSONClient) SSubDelIfEqual(key, subKey, expected []byte) (result bool) {
+	item := SubCASOp{
+		Key:      key,
+		SubKey:   subKey,
+		Expected: expected,
+		Sync:     true,
+	}
+	self.call("SubDelIfEqual", item, &result)
+	return
+}
+func (self JSONClient) SubPrefixDel(key, prefix []byte) (result int) {
+	item := SubKeyOp{
+		Key:    key,
+		SubKey: prefix,
+	}
+	self.call("SubPrefixDel", item, &result)
+	return
+}
+func (self JSONClient) SSubPrefixDel(key, prefix []byte) (result int) {
+	item := SubKeyOp{
+		Key:    key,
+		SubKey: prefix,
+		Sync:   true,
+	}
+	self.call("SubPrefixDel", item, &result)
+	return
+}
+func (self JSONClient) SCompareAndSwap(key, expected, value []byte) (result bool) {
+	item := CASOp{
+		Key:      key,
+		Expected: expected,
+		Value:    value,
+		Sync:     true,
+	}
+	self.call("CompareAndSwap", item, &result)
+	return
+}
+func (self JSONClient) SPutIfAbsent(key, value []byte) (result bool) {
+	item := ValueOp{
+		Key:   key,
+		Value: value,
+		Sync:  true,
+	}
+	self.call("PutIfAbsent", item, &result)
+	return
+}
+func (self JSONClient) SDelIfEqual(key, expected []byte) (result bool) {
+	item := CASOp{
+		Key:      key,
+		Expected: expected,
+		Sync:     true,
+	}
+	self.call("DelIfEqual", item, &result)
+	return
+}
 func (self JSONClient) SubClear(key []byte) {
 	var x Nothing
 	item := KeyOp{
@@ -206,6 +342,14 @@ func (self JSONClient) Count(key, min, max []byte, mininc, maxinc bool) (result
 	self.call("Count", item, &result)
 	return result
 }
+func (self JSONClient) SubPrefixCount(key, prefix []byte) (result int) {
+	item := SubKeyReq{
+		Key:    key,
+		SubKey: prefix,
+	}
+	self.call("SubPrefixCount", item, &result)
+	return result
+}
 func (self JSONClient) MirrorNextIndex(key []byte, index int) (foundKey, foundValue []byte, foundIndex int, existed bool) {
 	item := SubIndex{
 		Key:   key,
@@ -342,6 +486,14 @@ func (self JSONClient) Slice(key, min, max []byte, mininc, maxinc bool) (result
 	self.call("Slice", item, &result)
 	return result
 }
+func (self JSONClient) SubPrefixSlice(key, prefix []byte) (result []common.Item) {
+	item := SubKeyReq{
+		Key:    key,
+		SubKey: prefix,
+	}
+	self.call("SubPrefixSlice", item, &result)
+	return result
+}
 func (self JSONClient) SliceLen(key, min []byte, mininc bool, maxRes int) (result []common.Item) {
 	item := PageRange{
 		Key:     key,
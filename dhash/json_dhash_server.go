@@ -1,29 +1,37 @@
 package dhash
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/zond/god/common"
 	"github.com/zond/setop"
 )
 
 type Nothing struct{}
 type SubValueRes struct {
-	Key    []byte
-	SubKey []byte
-	Value  []byte
-	Exists bool
+	Key           []byte
+	SubKey        []byte
+	Value         []byte
+	Exists        bool
+	DecodedSubKey interface{} // SubKey decoded using the keyEncoding of the sub tree, if it has one
+	DecodedValue  interface{} // Value decoded using the valueEncoding of the sub tree, if it has one
 }
 type SubValueIndexRes struct {
-	Key    []byte
-	SubKey []byte
-	Value  []byte
-	Index  int
-	Exists bool
+	Key           []byte
+	SubKey        []byte
+	Value         []byte
+	Index         int
+	Exists        bool
+	DecodedSubKey interface{} // SubKey decoded using the keyEncoding of the sub tree, if it has one
+	DecodedValue  interface{} // Value decoded using the valueEncoding of the sub tree, if it has one
 }
 type SubValueOp struct {
 	Key    []byte
 	SubKey []byte
 	Value  []byte
 	Sync   bool
+	TTL    time.Duration
 }
 type SubKeyOp struct {
 	Key    []byte
@@ -42,11 +50,40 @@ type ValueOp struct {
 	Key   []byte
 	Value []byte
 	Sync  bool
+	TTL   time.Duration
 }
-type ValueRes struct {
+type SubCASOp struct {
+	Key      []byte
+	SubKey   []byte
+	Expected []byte
+	Value    []byte
+	Sync     bool
+	TTL      time.Duration
+}
+type CASOp struct {
+	Key      []byte
+	Expected []byte
+	Value    []byte
+	Sync     bool
+	TTL      time.Duration
+}
+type SubIncrOp struct {
 	Key    []byte
-	Value  []byte
-	Exists bool
+	SubKey []byte
+	Delta  int64
+	Sync   bool
+}
+type IncrOp struct {
+	Key   []byte
+	Delta int64
+	Sync  bool
+}
+type ValueRes struct {
+	Key          []byte
+	Value        []byte
+	Exists       bool
+	DecodedKey   interface{} // Key decoded using the keyEncoding of the sub tree, if it is a sub key and the sub tree has one
+	DecodedValue interface{} // Value decoded using the valueEncoding of the sub tree, if it is a sub value and the sub tree has one
 }
 type KeyOp struct {
 	Key  []byte
@@ -82,6 +119,55 @@ type Conf struct {
 	Key   string
 	Value string
 }
+type JSONPathReq struct {
+	Key  []byte
+	Path string
+}
+type SubJSONPathReq struct {
+	Key    []byte
+	SubKey []byte
+	Path   string
+}
+type JSONPathOp struct {
+	Key   []byte
+	Path  string
+	Value json.RawMessage
+	Sync  bool
+}
+type SubJSONPathOp struct {
+	Key    []byte
+	SubKey []byte
+	Path   string
+	Value  json.RawMessage
+	Sync   bool
+}
+type JSONPatchOp struct {
+	Key   []byte
+	Patch json.RawMessage
+	Sync  bool
+}
+type SubJSONPatchOp struct {
+	Key    []byte
+	SubKey []byte
+	Patch  json.RawMessage
+	Sync   bool
+}
+type GeoAddOp struct {
+	Key    []byte
+	Member []byte
+	Lat    float64
+	Lon    float64
+	Sync   bool
+}
+type GeoDelOp struct {
+	Key    []byte
+	Member []byte
+	Sync   bool
+}
+type JSONRes struct {
+	Value  json.RawMessage
+	Exists bool
+}
 
 type JSONApi Node
 
@@ -94,6 +180,29 @@ func (self *JSONApi) convert(items []common.Item, result *[]ValueRes) {
 		})
 	}
 }
+func (self *JSONApi) convertSub(key []byte, items []common.Item, result *[]ValueRes) {
+	keyEncoding, valueEncoding := self.subEncodings(key)
+	for _, item := range items {
+		*result = append(*result, ValueRes{
+			Key:          item.Key,
+			Value:        item.Value,
+			Exists:       true,
+			DecodedKey:   common.DecodeValue(keyEncoding, item.Key),
+			DecodedValue: common.DecodeValue(valueEncoding, item.Value),
+		})
+	}
+}
+
+// subEncodings returns the keyEncoding and valueEncoding of the sub tree key, as known by its owner.
+func (self *JSONApi) subEncodings(key []byte) (keyEncoding, valueEncoding string) {
+	var conf common.Conf
+	if f, err := self.forwardUnlessMe("DHash.SubConfiguration", key, key, &conf); !f {
+		conf.Data, _ = (*Node)(self).tree.SubConfiguration(key)
+	} else if err != nil {
+		return
+	}
+	return conf.Data[common.KeyEncoding], conf.Data[common.ValueEncoding]
+}
 func (self *JSONApi) forwardUnlessMe(cmd string, key []byte, in, out interface{}) (forwarded bool, err error) {
 	succ := (*Node)(self).node.GetSuccessorFor(key)
 	if succ.Addr != (*Node)(self).node.GetBroadcastAddr() {
@@ -138,10 +247,11 @@ func (self *JSONApi) SubClear(d SubKeyOp, n *Nothing) (err error) {
 }
 func (self *JSONApi) SubPut(d SubValueOp, n *Nothing) (err error) {
 	data := common.Item{
-		Key:    d.Key,
-		SubKey: d.SubKey,
-		Value:  d.Value,
-		Sync:   d.Sync,
+		Key:      d.Key,
+		SubKey:   d.SubKey,
+		Value:    d.Value,
+		Sync:     d.Sync,
+		Lifetime: int64(d.TTL),
 	}
 	var x int
 	var f bool
@@ -164,9 +274,10 @@ func (self *JSONApi) Del(d KeyOp, n *Nothing) (err error) {
 }
 func (self *JSONApi) Put(d ValueOp, n *Nothing) (err error) {
 	data := common.Item{
-		Key:   d.Key,
-		Value: d.Value,
-		Sync:  d.Sync,
+		Key:      d.Key,
+		Value:    d.Value,
+		Sync:     d.Sync,
+		Lifetime: int64(d.TTL),
 	}
 	var x int
 	var f bool
@@ -175,6 +286,92 @@ func (self *JSONApi) Put(d ValueOp, n *Nothing) (err error) {
 	}
 	return
 }
+func (self *JSONApi) SubCompareAndSwap(d SubCASOp, swapped *bool) (err error) {
+	data := common.Item{
+		Key:         d.Key,
+		SubKey:      d.SubKey,
+		Expected:    d.Expected,
+		HasExpected: d.Expected != nil,
+		Value:       d.Value,
+		Sync:        d.Sync,
+		Lifetime:    int64(d.TTL),
+	}
+	return (*Node)(self).SubCompareAndSwap(data, swapped)
+}
+func (self *JSONApi) SubPutIfAbsent(d SubValueOp, swapped *bool) (err error) {
+	data := common.Item{
+		Key:      d.Key,
+		SubKey:   d.SubKey,
+		Value:    d.Value,
+		Sync:     d.Sync,
+		Lifetime: int64(d.TTL),
+	}
+	return (*Node)(self).SubCompareAndSwap(data, swapped)
+}
+func (self *JSONApi) SubDelIfEqual(d SubCASOp, deleted *bool) (err error) {
+	data := common.Item{
+		Key:         d.Key,
+		SubKey:      d.SubKey,
+		Expected:    d.Expected,
+		HasExpected: d.Expected != nil,
+		Sync:        d.Sync,
+	}
+	return (*Node)(self).SubDelIfEqual(data, deleted)
+}
+func (self *JSONApi) SubPrefixDel(d SubKeyOp, deleted *int) (err error) {
+	data := common.Item{
+		Key:    d.Key,
+		SubKey: d.SubKey,
+		Sync:   d.Sync,
+	}
+	return (*Node)(self).SubPrefixDel(data, deleted)
+}
+func (self *JSONApi) CompareAndSwap(d CASOp, swapped *bool) (err error) {
+	data := common.Item{
+		Key:         d.Key,
+		Expected:    d.Expected,
+		HasExpected: d.Expected != nil,
+		Value:       d.Value,
+		Sync:        d.Sync,
+		Lifetime:    int64(d.TTL),
+	}
+	return (*Node)(self).CompareAndSwap(data, swapped)
+}
+func (self *JSONApi) PutIfAbsent(d ValueOp, swapped *bool) (err error) {
+	data := common.Item{
+		Key:      d.Key,
+		Value:    d.Value,
+		Sync:     d.Sync,
+		Lifetime: int64(d.TTL),
+	}
+	return (*Node)(self).CompareAndSwap(data, swapped)
+}
+func (self *JSONApi) DelIfEqual(d CASOp, deleted *bool) (err error) {
+	data := common.Item{
+		Key:         d.Key,
+		Expected:    d.Expected,
+		HasExpected: d.Expected != nil,
+		Sync:        d.Sync,
+	}
+	return (*Node)(self).DelIfEqual(data, deleted)
+}
+func (self *JSONApi) SubIncr(d SubIncrOp, result *int64) (err error) {
+	data := common.Item{
+		Key:    d.Key,
+		SubKey: d.SubKey,
+		Value:  setop.EncodeInt64(d.Delta),
+		Sync:   d.Sync,
+	}
+	return (*Node)(self).SubIncr(data, result)
+}
+func (self *JSONApi) Incr(d IncrOp, result *int64) (err error) {
+	data := common.Item{
+		Key:   d.Key,
+		Value: setop.EncodeInt64(d.Delta),
+		Sync:  d.Sync,
+	}
+	return (*Node)(self).Incr(data, result)
+}
 func (self *JSONApi) MirrorCount(kr KeyRange, result *int) (err error) {
 	r := common.Range{
 		Key:    kr.Key,
@@ -203,6 +400,17 @@ func (self *JSONApi) Count(kr KeyRange, result *int) (err error) {
 	}
 	return
 }
+func (self *JSONApi) SubPrefixCount(kr SubKeyReq, result *int) (err error) {
+	r := common.Range{
+		Key: kr.Key,
+		Min: kr.SubKey,
+	}
+	var f bool
+	if f, err = self.forwardUnlessMe("DHash.SubPrefixCount", r.Key, r, result); !f {
+		err = (*Node)(self).SubPrefixCount(r, result)
+	}
+	return
+}
 func (self *JSONApi) Next(kr KeyReq, result *ValueRes) (err error) {
 	k, v, e := (*Node)(self).client().Next(kr.Key)
 	*result = ValueRes{
@@ -231,11 +439,14 @@ func (self *JSONApi) SubGet(k SubKeyReq, result *SubValueRes) (err error) {
 	if f, err = self.forwardUnlessMe("DHash.SubGet", data.Key, data, &item); !f {
 		err = (*Node)(self).SubGet(data, &item)
 	}
+	keyEncoding, valueEncoding := self.subEncodings(data.Key)
 	*result = SubValueRes{
-		Key:    item.Key,
-		SubKey: item.SubKey,
-		Value:  item.Value,
-		Exists: item.Exists,
+		Key:           item.Key,
+		SubKey:        item.SubKey,
+		Value:         item.Value,
+		Exists:        item.Exists,
+		DecodedSubKey: common.DecodeValue(keyEncoding, item.SubKey),
+		DecodedValue:  common.DecodeValue(valueEncoding, item.Value),
 	}
 	return
 }
@@ -255,6 +466,101 @@ func (self *JSONApi) Get(k KeyReq, result *ValueRes) (err error) {
 	}
 	return
 }
+func (self *JSONApi) jsonGet(op common.JSONOp, result *JSONRes) (err error) {
+	var item common.Item
+	if err = (*Node)(self).JSONGet(op, &item); err == nil {
+		*result = JSONRes{
+			Value:  item.Value,
+			Exists: item.Exists,
+		}
+	}
+	return
+}
+func (self *JSONApi) JSONGet(r JSONPathReq, result *JSONRes) (err error) {
+	return self.jsonGet(common.JSONOp{
+		Key:  r.Key,
+		Path: r.Path,
+	}, result)
+}
+func (self *JSONApi) SubJSONGet(r SubJSONPathReq, result *JSONRes) (err error) {
+	return self.jsonGet(common.JSONOp{
+		Key:    r.Key,
+		SubKey: r.SubKey,
+		Sub:    true,
+		Path:   r.Path,
+	}, result)
+}
+func (self *JSONApi) JSONSet(d JSONPathOp, result *json.RawMessage) (err error) {
+	return (*Node)(self).JSONSet(common.JSONOp{
+		Key:   d.Key,
+		Path:  d.Path,
+		Value: d.Value,
+		Sync:  d.Sync,
+	}, (*[]byte)(result))
+}
+func (self *JSONApi) SubJSONSet(d SubJSONPathOp, result *json.RawMessage) (err error) {
+	return (*Node)(self).JSONSet(common.JSONOp{
+		Key:    d.Key,
+		SubKey: d.SubKey,
+		Sub:    true,
+		Path:   d.Path,
+		Value:  d.Value,
+		Sync:   d.Sync,
+	}, (*[]byte)(result))
+}
+func (self *JSONApi) JSONMerge(d JSONPatchOp, result *json.RawMessage) (err error) {
+	return (*Node)(self).JSONMerge(common.JSONOp{
+		Key:   d.Key,
+		Value: d.Patch,
+		Sync:  d.Sync,
+	}, (*[]byte)(result))
+}
+func (self *JSONApi) SubJSONMerge(d SubJSONPatchOp, result *json.RawMessage) (err error) {
+	return (*Node)(self).JSONMerge(common.JSONOp{
+		Key:    d.Key,
+		SubKey: d.SubKey,
+		Sub:    true,
+		Value:  d.Patch,
+		Sync:   d.Sync,
+	}, (*[]byte)(result))
+}
+func (self *JSONApi) GeoAdd(d GeoAddOp, n *Nothing) (err error) {
+	return (*Node)(self).GeoAdd(common.GeoOp{
+		Key:    d.Key,
+		Member: d.Member,
+		Lat:    d.Lat,
+		Lon:    d.Lon,
+		Sync:   d.Sync,
+	})
+}
+func (self *JSONApi) GeoDel(d GeoDelOp, deleted *bool) (err error) {
+	return (*Node)(self).GeoDel(common.GeoOp{
+		Key:    d.Key,
+		Member: d.Member,
+		Sync:   d.Sync,
+	}, deleted)
+}
+func (self *JSONApi) GeoRadius(q common.GeoRadiusQuery, result *[]common.GeoMember) (err error) {
+	var f bool
+	if f, err = self.forwardUnlessMe("DHash.GeoRadius", q.Key, q, result); !f {
+		err = (*Node)(self).GeoRadius(q, result)
+	}
+	return
+}
+func (self *JSONApi) GeoBox(q common.GeoBoxQuery, result *[]common.GeoMember) (err error) {
+	var f bool
+	if f, err = self.forwardUnlessMe("DHash.GeoBox", q.Key, q, result); !f {
+		err = (*Node)(self).GeoBox(q, result)
+	}
+	return
+}
+func (self *JSONApi) Watch(w common.Watch, result *common.WatchResult) (err error) {
+	var f bool
+	if f, err = self.forwardUnlessMe("DHash.Watch", w.Key, w, result); !f {
+		err = (*Node)(self).Watch(w, result)
+	}
+	return
+}
 func (self *JSONApi) Size(x Nothing, result *int) (err error) {
 	*result = (*Node)(self).Size()
 	return nil
@@ -289,12 +595,15 @@ func (self *JSONApi) PrevIndex(i SubIndex, result *SubValueIndexRes) (err error)
 	if f, err = self.forwardUnlessMe("DHash.PrevIndex", data.Key, data, &item); !f {
 		err = (*Node)(self).PrevIndex(data, &item)
 	}
+	keyEncoding, valueEncoding := self.subEncodings(data.Key)
 	*result = SubValueIndexRes{
-		Key:    item.Key,
-		SubKey: item.SubKey,
-		Value:  item.Value,
-		Index:  item.Index,
-		Exists: item.Exists,
+		Key:           item.Key,
+		SubKey:        item.SubKey,
+		Value:         item.Value,
+		Index:         item.Index,
+		Exists:        item.Exists,
+		DecodedSubKey: common.DecodeValue(keyEncoding, item.SubKey),
+		DecodedValue:  common.DecodeValue(valueEncoding, item.Value),
 	}
 	return
 }
@@ -346,12 +655,15 @@ func (self *JSONApi) NextIndex(i SubIndex, result *SubValueIndexRes) (err error)
 	if f, err = self.forwardUnlessMe("DHash.NextIndex", data.Key, data, &item); !f {
 		err = (*Node)(self).NextIndex(data, &item)
 	}
+	keyEncoding, valueEncoding := self.subEncodings(data.Key)
 	*result = SubValueIndexRes{
-		Key:    item.Key,
-		SubKey: item.SubKey,
-		Value:  item.Value,
-		Index:  item.Index,
-		Exists: item.Exists,
+		Key:           item.Key,
+		SubKey:        item.SubKey,
+		Value:         item.Value,
+		Index:         item.Index,
+		Exists:        item.Exists,
+		DecodedSubKey: common.DecodeValue(keyEncoding, item.SubKey),
+		DecodedValue:  common.DecodeValue(valueEncoding, item.Value),
 	}
 	return
 }
@@ -445,11 +757,14 @@ func (self *JSONApi) SubPrev(k SubKeyReq, result *SubValueRes) (err error) {
 	if f, err = self.forwardUnlessMe("DHash.SubPrev", data.Key, data, &item); !f {
 		err = (*Node)(self).SubPrev(data, &item)
 	}
+	keyEncoding, valueEncoding := self.subEncodings(data.Key)
 	*result = SubValueRes{
-		Key:    item.Key,
-		SubKey: item.SubKey,
-		Value:  item.Value,
-		Exists: item.Exists,
+		Key:           item.Key,
+		SubKey:        item.SubKey,
+		Value:         item.Value,
+		Exists:        item.Exists,
+		DecodedSubKey: common.DecodeValue(keyEncoding, item.SubKey),
+		DecodedValue:  common.DecodeValue(valueEncoding, item.Value),
 	}
 	return
 }
@@ -463,11 +778,14 @@ func (self *JSONApi) SubNext(k SubKeyReq, result *SubValueRes) (err error) {
 	if f, err = self.forwardUnlessMe("DHash.SubNext", data.Key, data, &item); !f {
 		err = (*Node)(self).SubNext(data, &item)
 	}
+	keyEncoding, valueEncoding := self.subEncodings(data.Key)
 	*result = SubValueRes{
-		Key:    item.Key,
-		SubKey: item.SubKey,
-		Value:  item.Value,
-		Exists: item.Exists,
+		Key:           item.Key,
+		SubKey:        item.SubKey,
+		Value:         item.Value,
+		Exists:        item.Exists,
+		DecodedSubKey: common.DecodeValue(keyEncoding, item.SubKey),
+		DecodedValue:  common.DecodeValue(valueEncoding, item.Value),
 	}
 	return
 }
@@ -514,11 +832,14 @@ func (self *JSONApi) First(k KeyReq, result *SubValueRes) (err error) {
 	if f, err = self.forwardUnlessMe("DHash.First", data.Key, data, &item); !f {
 		err = (*Node)(self).First(data, &item)
 	}
+	keyEncoding, valueEncoding := self.subEncodings(data.Key)
 	*result = SubValueRes{
-		Key:    item.Key,
-		SubKey: item.SubKey,
-		Value:  item.Value,
-		Exists: item.Exists,
+		Key:           item.Key,
+		SubKey:        item.SubKey,
+		Value:         item.Value,
+		Exists:        item.Exists,
+		DecodedSubKey: common.DecodeValue(keyEncoding, item.SubKey),
+		DecodedValue:  common.DecodeValue(valueEncoding, item.Value),
 	}
 	return
 }
@@ -531,11 +852,14 @@ func (self *JSONApi) Last(k KeyReq, result *SubValueRes) (err error) {
 	if f, err = self.forwardUnlessMe("DHash.Last", data.Key, data, &item); !f {
 		err = (*Node)(self).Last(data, &item)
 	}
+	keyEncoding, valueEncoding := self.subEncodings(data.Key)
 	*result = SubValueRes{
-		Key:    item.Key,
-		SubKey: item.SubKey,
-		Value:  item.Value,
-		Exists: item.Exists,
+		Key:           item.Key,
+		SubKey:        item.SubKey,
+		Value:         item.Value,
+		Exists:        item.Exists,
+		DecodedSubKey: common.DecodeValue(keyEncoding, item.SubKey),
+		DecodedValue:  common.DecodeValue(valueEncoding, item.Value),
 	}
 	return
 }
@@ -662,7 +986,7 @@ func (self *JSONApi) ReverseSlice(kr KeyRange, result *[]ValueRes) (err error) {
 	if f, err = self.forwardUnlessMe("DHash.ReverseSlice", r.Key, r, &items); !f {
 		err = (*Node)(self).ReverseSlice(r, &items)
 	}
-	self.convert(items, result)
+	self.convertSub(r.Key, items, result)
 	return
 }
 func (self *JSONApi) Slice(kr KeyRange, result *[]ValueRes) (err error) {
@@ -678,7 +1002,20 @@ func (self *JSONApi) Slice(kr KeyRange, result *[]ValueRes) (err error) {
 	if f, err = self.forwardUnlessMe("DHash.Slice", r.Key, r, &items); !f {
 		err = (*Node)(self).Slice(r, &items)
 	}
-	self.convert(items, result)
+	self.convertSub(r.Key, items, result)
+	return
+}
+func (self *JSONApi) SubPrefixSlice(kr SubKeyReq, result *[]ValueRes) (err error) {
+	r := common.Range{
+		Key: kr.Key,
+		Min: kr.SubKey,
+	}
+	var items []common.Item
+	var f bool
+	if f, err = self.forwardUnlessMe("DHash.SubPrefixSlice", r.Key, r, &items); !f {
+		err = (*Node)(self).SubPrefixSlice(r, &items)
+	}
+	self.convertSub(r.Key, items, result)
 	return
 }
 func (self *JSONApi) SliceIndex(ir IndexRange, result *[]ValueRes) (err error) {
@@ -702,7 +1039,7 @@ func (self *JSONApi) SliceIndex(ir IndexRange, result *[]ValueRes) (err error) {
 	if f, err = self.forwardUnlessMe("DHash.SliceIndex", r.Key, r, &items); !f {
 		err = (*Node)(self).SliceIndex(r, &items)
 	}
-	self.convert(items, result)
+	self.convertSub(r.Key, items, result)
 	return
 }
 func (self *JSONApi) ReverseSliceIndex(ir IndexRange, result *[]ValueRes) (err error) {
@@ -726,7 +1063,7 @@ func (self *JSONApi) ReverseSliceIndex(ir IndexRange, result *[]ValueRes) (err e
 	if f, err = self.forwardUnlessMe("DHash.ReverseSliceIndex", r.Key, r, &items); !f {
 		err = (*Node)(self).ReverseSliceIndex(r, &items)
 	}
-	self.convert(items, result)
+	self.convertSub(r.Key, items, result)
 	return
 }
 func (self *JSONApi) SliceLen(pr PageRange, result *[]ValueRes) (err error) {
@@ -741,7 +1078,7 @@ func (self *JSONApi) SliceLen(pr PageRange, result *[]ValueRes) (err error) {
 	if f, err = self.forwardUnlessMe("DHash.SliceLen", r.Key, r, &items); !f {
 		err = (*Node)(self).SliceLen(r, &items)
 	}
-	self.convert(items, result)
+	self.convertSub(r.Key, items, result)
 	return
 }
 func (self *JSONApi) ReverseSliceLen(pr PageRange, result *[]ValueRes) (err error) {
@@ -756,7 +1093,7 @@ func (self *JSONApi) ReverseSliceLen(pr PageRange, result *[]ValueRes) (err erro
 	if f, err = self.forwardUnlessMe("DHash.ReverseSliceLen", r.Key, r, &items); !f {
 		err = (*Node)(self).ReverseSliceLen(r, &items)
 	}
-	self.convert(items, result)
+	self.convertSub(r.Key, items, result)
 	return
 }
 func (self *JSONApi) SetExpression(expr setop.SetExpression, items *[]setop.SetOpResult) (err error) {
@@ -0,0 +1,193 @@
+package dhash
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zond/god/common"
+)
+
+// jsonPath returns the parts of path, which must be of the form '$.a.b'.
+func jsonPath(path string) (parts []string, err error) {
+	if path == "$" {
+		return
+	}
+	if !strings.HasPrefix(path, "$.") {
+		return nil, fmt.Errorf("%#v is not a path of the form '$.a.b'", path)
+	}
+	return strings.Split(path[2:], "."), nil
+}
+
+// decodeJSON returns the JSON document in b, keeping numbers as json.Number to not lose precision.
+func decodeJSON(b []byte) (doc interface{}, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	err = decoder.Decode(&doc)
+	return
+}
+
+// jsonGet returns the field at parts in doc, where numeric parts index arrays.
+func jsonGet(doc interface{}, parts []string) (field interface{}, found bool) {
+	field = doc
+	for _, part := range parts {
+		switch container := field.(type) {
+		case map[string]interface{}:
+			if field, found = container[part]; !found {
+				return
+			}
+		case []interface{}:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(container) {
+				return nil, false
+			}
+			field = container[index]
+		default:
+			return nil, false
+		}
+	}
+	return field, true
+}
+
+// jsonSet returns doc with the field at parts set to value. Missing objects on the way will be created, but arrays will not be extended.
+func jsonSet(doc interface{}, parts []string, value interface{}) (result interface{}, err error) {
+	if len(parts) == 0 {
+		return value, nil
+	}
+	switch container := doc.(type) {
+	case nil:
+		var field interface{}
+		if field, err = jsonSet(nil, parts[1:], value); err == nil {
+			result = map[string]interface{}{parts[0]: field}
+		}
+	case map[string]interface{}:
+		if container[parts[0]], err = jsonSet(container[parts[0]], parts[1:], value); err == nil {
+			result = container
+		}
+	case []interface{}:
+		index, e := strconv.Atoi(parts[0])
+		if e != nil || index < 0 || index >= len(container) {
+			return nil, fmt.Errorf("%#v is not an index of an array of length %v", parts[0], len(container))
+		}
+		if container[index], err = jsonSet(container[index], parts[1:], value); err == nil {
+			result = container
+		}
+	default:
+		err = fmt.Errorf("%v has no field %#v", doc, parts[0])
+	}
+	return
+}
+
+// jsonMerge returns doc patched with patch, as defined by RFC 7386.
+func jsonMerge(doc, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	docObject, ok := doc.(map[string]interface{})
+	if !ok {
+		docObject = make(map[string]interface{})
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(docObject, key)
+		} else {
+			docObject[key] = jsonMerge(docObject[key], value)
+		}
+	}
+	return docObject
+}
+
+// JSONGet will set result.Value to the JSON encoded field at op.Path of the JSON document under op.Key (or under op.SubKey in the sub tree op.Key, if op.Sub),
+// and result.Exists to whether the field exists.
+func (self *Node) JSONGet(op common.JSONOp, result *common.Item) (err error) {
+	var f bool
+	if f, err = self.forwardUnlessOwner("DHash.JSONGet", op.Key, op, result); f {
+		return
+	}
+	var parts []string
+	if parts, err = jsonPath(op.Path); err != nil {
+		return
+	}
+	var value []byte
+	var existed bool
+	if op.Sub {
+		value, _, existed = self.tree.SubGet(op.Key, op.SubKey)
+	} else {
+		value, _, existed = self.tree.Get(op.Key)
+	}
+	if !existed {
+		return
+	}
+	var doc interface{}
+	if doc, err = decodeJSON(value); err != nil {
+		return
+	}
+	if doc, result.Exists = jsonGet(doc, parts); result.Exists {
+		result.Value, err = json.Marshal(doc)
+	}
+	return
+}
+
+// JSONSet will set the field at op.Path of the JSON document under op.Key (or under op.SubKey in the sub tree op.Key, if op.Sub) to the JSON encoded op.Value,
+// replicate the new document like Put (or SubPut) does, and set result to it. A missing document will be created.
+func (self *Node) JSONSet(op common.JSONOp, result *[]byte) (err error) {
+	var parts []string
+	if parts, err = jsonPath(op.Path); err != nil {
+		return
+	}
+	var value interface{}
+	if value, err = decodeJSON(op.Value); err != nil {
+		return
+	}
+	return self.jsonUpdate("DHash.JSONSet", op, result, func(doc interface{}) (interface{}, error) {
+		return jsonSet(doc, parts, value)
+	})
+}
+
+// JSONMerge will apply the JSON merge patch in op.Value to the JSON document under op.Key (or under op.SubKey in the sub tree op.Key, if op.Sub),
+// replicate the new document like Put (or SubPut) does, and set result to it. A missing document will be created.
+func (self *Node) JSONMerge(op common.JSONOp, result *[]byte) (err error) {
+	var patch interface{}
+	if patch, err = decodeJSON(op.Value); err != nil {
+		return
+	}
+	return self.jsonUpdate("DHash.JSONMerge", op, result, func(doc interface{}) (interface{}, error) {
+		return jsonMerge(doc, patch), nil
+	})
+}
+
+// jsonUpdate will forward op to operation on the owner of op.Key, unless this node is the owner, in which case it will atomically
+// replace the JSON document op refers to with what f returns for it, and replicate the new document.
+func (self *Node) jsonUpdate(operation string, op common.JSONOp, result *[]byte, f func(doc interface{}) (interface{}, error)) (err error) {
+	var forwarded bool
+	if forwarded, err = self.forwardUnlessOwner(operation, op.Key, op, result); forwarded {
+		return
+	}
+	if op.Sub {
+		if conf, _ := self.tree.SubConfiguration(op.Key); conf[common.ValueEncoding] != "" && conf[common.ValueEncoding] != common.EncodingJSON {
+			return fmt.Errorf("%#v has values encoded as %v, and can't be updated as JSON", string(op.Key), conf[common.ValueEncoding])
+		}
+	}
+	data := common.Item{
+		Key:    op.Key,
+		SubKey: op.SubKey,
+		Sync:   op.Sync,
+		Acks:   op.Acks,
+	}
+	*result, err = self.update(data, op.Sub, func(oldBytes []byte, existed bool) (newBytes []byte, err error) {
+		var doc interface{}
+		if existed {
+			if doc, err = decodeJSON(oldBytes); err != nil {
+				return
+			}
+		}
+		if doc, err = f(doc); err != nil {
+			return
+		}
+		return json.Marshal(doc)
+	})
+	return
+}
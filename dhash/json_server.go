@@ -15,6 +15,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -27,6 +28,23 @@ type socketMessage struct {
 	Data interface{} `json:"data"`
 }
 
+// socket serializes the messages sent to a websocket from the listeners and watches of its connection, since concurrent sends would interleave.
+type socket struct {
+	ws   *websocket.Conn
+	lock sync.Mutex
+}
+
+func (self *socket) send(message string) error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	return websocket.Message.Send(self.ws, message)
+}
+func (self *socket) sendJSON(message socketMessage) error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	return websocket.JSON.Send(self.ws, message)
+}
+
 var prefPattern = regexp.MustCompile("^([^\\s;]+)(;q=([\\d.]+))?$")
 
 func mostAccepted(r *http.Request, def, name string) string {
@@ -143,6 +161,47 @@ func (self *Node) jsonDescription() string {
 	return string(b)
 }
 
+// watchSocket will send all changes matching w, found by the owner of w.Key, to sock, until sending fails or closed is closed.
+// When the ownership of w.Key moves, it will continue watching the new owner from the timestamp of the last change seen.
+func (self *Node) watchSocket(sock *socket, w common.Watch, closed chan bool) {
+	var owner common.Remote
+	for {
+		owner = self.node.GetSuccessorFor(w.Key)
+		var result common.WatchResult
+		if err := owner.Call("DHash.Watch", common.Watch{Seq: -1}, &result); err != nil {
+			self.node.RemoveNode(owner)
+		} else {
+			w.Seq, w.After = result.Seq, result.Time
+			break
+		}
+	}
+	for {
+		select {
+		case <-closed:
+			return
+		default:
+		}
+		if succ := self.node.GetSuccessorFor(w.Key); succ.Addr != owner.Addr {
+			owner = succ
+			w.Seq = 0
+		}
+		var result common.WatchResult
+		if err := owner.Call("DHash.Watch", w, &result); err != nil {
+			self.node.RemoveNode(owner)
+			continue
+		}
+		for _, change := range result.Changes {
+			if sock.sendJSON(socketMessage{
+				Type: "Change",
+				Data: change,
+			}) != nil {
+				return
+			}
+		}
+		w.Seq, w.After = result.Seq, result.Time
+	}
+}
+
 func (self *Node) startJson() {
 	var nodeAddr *net.TCPAddr
 	var err error
@@ -157,11 +216,12 @@ func (self *Node) startJson() {
 	router := mux.NewRouter()
 	router.Methods("POST").Path("/rpc/{method}").MatcherFunc(wantsJSON).Handler(jsonServer)
 	web.Route(func(ws *websocket.Conn) {
-		if websocket.Message.Send(ws, self.jsonDescription()) == nil {
+		sock := &socket{ws: ws}
+		if sock.send(self.jsonDescription()) == nil {
 			go func() {
 				for {
 					time.Sleep(updateInterval)
-					if websocket.Message.Send(ws, self.jsonDescription()) != nil {
+					if sock.send(self.jsonDescription()) != nil {
 						break
 					}
 				}
@@ -180,7 +240,7 @@ func (self *Node) startJson() {
 				if err != nil {
 					panic(err)
 				}
-				return websocket.Message.Send(ws, string(b)) == nil
+				return sock.send(string(b)) == nil
 			})
 			self.AddChangeListener(func(ring *common.Ring) bool {
 				b, err := json.Marshal(socketMessage{
@@ -193,7 +253,7 @@ func (self *Node) startJson() {
 				if err != nil {
 					panic(err)
 				}
-				return websocket.Message.Send(ws, string(b)) == nil
+				return sock.send(string(b)) == nil
 			})
 			self.AddSyncListener(func(source, dest common.Remote, pulled, pushed int) bool {
 				b, err := json.Marshal(socketMessage{
@@ -208,7 +268,7 @@ func (self *Node) startJson() {
 				if err != nil {
 					panic(err)
 				}
-				return websocket.Message.Send(ws, string(b)) == nil
+				return sock.send(string(b)) == nil
 			})
 			self.AddCleanListener(func(source, dest common.Remote, cleaned, pushed int) bool {
 				b, err := json.Marshal(socketMessage{
@@ -223,14 +283,23 @@ func (self *Node) startJson() {
 				if err != nil {
 					panic(err)
 				}
-				return websocket.Message.Send(ws, string(b)) == nil
+				return sock.send(string(b)) == nil
 			})
+			closed := make(chan bool)
 			var mess string
 			for {
 				if err = websocket.Message.Receive(ws, &mess); err != nil {
 					break
 				}
+				var watchMess struct {
+					Type string
+					Data common.Watch
+				}
+				if json.Unmarshal([]byte(mess), &watchMess) == nil && watchMess.Type == "Watch" {
+					go self.watchSocket(sock, watchMess.Data, closed)
+				}
 			}
+			close(closed)
 		}
 	}, router)
 	mux := http.NewServeMux()
@@ -0,0 +1,162 @@
+package dhash
+
+import (
+	"fmt"
+
+	"github.com/zond/god/common"
+	"github.com/zond/god/radix"
+)
+
+// Procedure is a function registered on a Node using RegisterProcedure, and run on the owner of a key using client.Conn.Invoke.
+// It reads and writes the value and the sub tree under the key using proc, and returns a result to the caller.
+type Procedure func(proc *ProcedureContext, args []byte) (result []byte, err error)
+
+// ProcedureContext gives a running Procedure access to the value and the sub tree under the key it was invoked for.
+//
+// Writes done through the ProcedureContext are replicated like Put, Del, SubPut and SubDel.
+type ProcedureContext struct {
+	node *Node
+	key  []byte
+	sync bool
+	acks int
+}
+
+// Key returns the key the Procedure was invoked for.
+func (self *ProcedureContext) Key() []byte {
+	return self.key
+}
+
+// Tree returns the radix.Tree of the Node, for reading. Any writes must be done through the ProcedureContext to be replicated.
+func (self *ProcedureContext) Tree() *radix.Tree {
+	return self.node.tree
+}
+
+// Get returns the value under the key.
+func (self *ProcedureContext) Get() (value []byte, existed bool) {
+	value, _, existed = self.node.tree.Get(self.key)
+	return
+}
+
+// SubGet returns the value under subKey in the sub tree under the key.
+func (self *ProcedureContext) SubGet(subKey []byte) (value []byte, existed bool) {
+	value, _, existed = self.node.tree.SubGet(self.key, subKey)
+	return
+}
+
+func (self *ProcedureContext) item(subKey, value []byte) common.Item {
+	return common.Item{
+		Key:       self.key,
+		SubKey:    subKey,
+		Value:     value,
+		Sync:      self.sync,
+		Acks:      self.acks,
+		TTL:       self.node.node.Redundancy(),
+		Timestamp: self.node.timer.ContinuousTime(),
+	}
+}
+
+// Put will put value under the key.
+func (self *ProcedureContext) Put(value []byte) error {
+	return self.node.putUnlessPrepared(self.item(nil, value), self)
+}
+
+// Del will delete the value under the key.
+func (self *ProcedureContext) Del() error {
+	return self.node.delUnlessPrepared(self.item(nil, nil), self)
+}
+
+// SubPut will put value under subKey in the sub tree under the key.
+func (self *ProcedureContext) SubPut(subKey, value []byte) error {
+	return self.node.subPutUnlessPrepared(self.item(subKey, value), self)
+}
+
+// SubDel will delete subKey from the sub tree under the key.
+func (self *ProcedureContext) SubDel(subKey []byte) error {
+	return self.node.subDelUnlessPrepared(self.item(subKey, nil), self)
+}
+
+// SubClear will remove all values from the sub tree under the key.
+func (self *ProcedureContext) SubClear() error {
+	return self.node.subClearUnlessPrepared(self.item(nil, nil), self)
+}
+
+// RegisterProcedure will make p callable by name using client.Conn.Invoke. A Procedure registered under the same name will be replaced.
+//
+// All Nodes in the cluster must register the same procedures, since an invocation runs on whichever Node owns its key.
+func (self *Node) RegisterProcedure(name string, p Procedure) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.procedures[name] = p
+}
+
+// UnregisterProcedure will remove the Procedure registered under name.
+func (self *Node) UnregisterProcedure(name string) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	delete(self.procedures, name)
+}
+func (self *Node) getProcedure(name string) (result Procedure, found bool) {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	result, found = self.procedures[name]
+	return
+}
+
+// waitForProcedures will wait until no Procedure other than proc is running for key. Must be called with txnLock held.
+func (self *Node) waitForProcedures(proc *ProcedureContext, key []byte) {
+	for {
+		if running, found := self.procKeys[string(key)]; !found || running == proc {
+			return
+		}
+		self.procCond.Wait()
+	}
+}
+
+// lockProcedure will wait until no other Procedure is running for the key of proc, and then lock the key for proc.
+func (self *Node) lockProcedure(proc *ProcedureContext) {
+	self.txnLock.Lock()
+	defer self.txnLock.Unlock()
+	self.waitForProcedures(proc, proc.key)
+	self.procKeys[string(proc.key)] = proc
+}
+
+// unlockProcedure will unlock the key of proc, and wake up everything waiting for it.
+func (self *Node) unlockProcedure(proc *ProcedureContext) {
+	self.txnLock.Lock()
+	defer self.txnLock.Unlock()
+	delete(self.procKeys, string(proc.key))
+	self.procCond.Broadcast()
+}
+
+// Invoke will run the Procedure registered under inv.Name with inv.Args on the value and the sub tree under inv.Key, and set result to what it returns.
+//
+// Procedures for the same key run one at a time, and writes to the key and its sub tree by anything but the running Procedure wait until it is done,
+// so a Procedure can read and then write without anything interleaving. Procedures for different keys run concurrently.
+// Transactions using the key can't be prepared while a Procedure for it is running.
+//
+// A Procedure that panics will make Invoke return an error instead of crashing the Node.
+func (self *Node) Invoke(inv common.Invocation, result *[]byte) (err error) {
+	var f bool
+	if f, err = self.forwardUnlessOwner("DHash.Invoke", inv.Key, inv, result); f {
+		return
+	}
+	proc, found := self.getProcedure(inv.Name)
+	if !found {
+		return fmt.Errorf("%v has no procedure named %#v", self.node, inv.Name)
+	}
+	ctx := &ProcedureContext{
+		node: self,
+		key:  inv.Key,
+		sync: inv.Sync,
+		acks: inv.Acks,
+	}
+	self.lockProcedure(ctx)
+	defer self.unlockProcedure(ctx)
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("procedure %#v panicked: %v", inv.Name, e)
+		}
+	}()
+	*result, err = proc(ctx, inv.Args)
+	return
+}
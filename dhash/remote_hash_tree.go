@@ -59,13 +59,15 @@ func (self remoteHashTree) GetTimestamp(key []radix.Nibble) (value []byte, times
 	value, timestamp, present = result.Value, result.Timestamp, result.Exists
 	return
 }
-func (self remoteHashTree) PutTimestamp(key []radix.Nibble, value []byte, present bool, expected, timestamp int64) (changed bool) {
+func (self remoteHashTree) PutTimestamp(key []radix.Nibble, value []byte, present bool, expected, timestamp, expires int64, mergeable bool) (changed bool) {
 	data := HashTreeItem{
 		Key:       key,
 		Value:     value,
 		Exists:    present,
 		Expected:  expected,
 		Timestamp: timestamp,
+		Expires:   expires,
+		Mergeable: mergeable,
 	}
 	op := "HashTree.PutTimestamp"
 	if self.node.hasCommListeners() {
@@ -114,7 +116,7 @@ func (self remoteHashTree) SubGetTimestamp(key, subKey []radix.Nibble) (value []
 	value, timestamp, present = data.Value, data.Timestamp, data.Exists
 	return
 }
-func (self remoteHashTree) SubPutTimestamp(key, subKey []radix.Nibble, value []byte, present bool, subExpected, subTimestamp int64) (changed bool) {
+func (self remoteHashTree) SubPutTimestamp(key, subKey []radix.Nibble, value []byte, present bool, subExpected, subTimestamp, subExpires int64) (changed bool) {
 	data := HashTreeItem{
 		Key:       key,
 		SubKey:    subKey,
@@ -122,6 +124,7 @@ func (self remoteHashTree) SubPutTimestamp(key, subKey []radix.Nibble, value []b
 		Exists:    present,
 		Expected:  subExpected,
 		Timestamp: subTimestamp,
+		Expires:   subExpires,
 	}
 	op := "HashTree.SubPutTimestamp"
 	if self.node.hasCommListeners() {
@@ -5,6 +5,7 @@ import (
 	"github.com/zond/god/common"
 	"github.com/zond/god/radix"
 	"github.com/zond/setop"
+	"net/rpc"
 )
 
 const (
@@ -104,3 +105,96 @@ func (self *treeSkipper) treeRefill(min []byte, inc bool) error {
 	self.tree.SubEachBetween(self.key, min, nil, inc, false, filler)
 	return nil
 }
+
+// setOpKeys returns the keys of all sub trees op reads from.
+func setOpKeys(op *setop.SetOp) (result [][]byte) {
+	for _, source := range op.Sources {
+		if source.Key != nil {
+			result = append(result, source.Key)
+		} else {
+			result = append(result, setOpKeys(source.SetOp)...)
+		}
+	}
+	return
+}
+
+// pickedMerge returns whether op or any of its nested operations has a merge other than Append.
+func pickedMerge(op *setop.SetOp) bool {
+	if op.Merge != setop.Append {
+		return true
+	}
+	for _, source := range op.Sources {
+		if source.SetOp != nil && pickedMerge(source.SetOp) {
+			return true
+		}
+	}
+	return false
+}
+
+// setMerges will replace all Append merges in op and its nested operations with merge.
+func setMerges(op *setop.SetOp, merge setop.SetOpMerge) {
+	if op.Merge == setop.Append {
+		op.Merge = merge
+	}
+	for _, source := range op.Sources {
+		if source.SetOp != nil {
+			setMerges(source.SetOp, merge)
+		}
+	}
+}
+
+// subValueEncodings returns the valueEncoding in the configuration of each sub tree in keys, using the configuration known by this node
+// for sub trees it holds, and asking the owners of the rest in parallel.
+func (self *Node) subValueEncodings(keys [][]byte) (result []string, err error) {
+	result = make([]string, len(keys))
+	futures := make([]*rpc.Call, len(keys))
+	confs := make([]common.Conf, len(keys))
+	for index, key := range keys {
+		if conf, timestamp := self.tree.SubConfiguration(key); timestamp != 0 {
+			result[index] = conf[common.ValueEncoding]
+		} else if succ := self.node.GetSuccessorFor(key); succ.Addr != self.node.GetBroadcastAddr() {
+			futures[index] = succ.Go("DHash.SubConfiguration", key, &confs[index])
+		}
+	}
+	for index, future := range futures {
+		if future != nil {
+			<-future.Done
+			if future.Error != nil && err == nil {
+				err = future.Error
+			}
+			result[index] = confs[index].Data[common.ValueEncoding]
+		}
+	}
+	return
+}
+
+// defaultMerges will replace the merges in expr with the common.DefaultMerge of the valueEncoding of the sub trees expr reads from,
+// if they all have the same valueEncoding, and all merges in expr are Append. It returns an error if the valueEncodings can't be fetched.
+func (self *Node) defaultMerges(expr setop.SetExpression) (err error) {
+	if expr.Op == nil || pickedMerge(expr.Op) {
+		return
+	}
+	seen := make(map[string]bool)
+	var keys [][]byte
+	for _, key := range setOpKeys(expr.Op) {
+		if !seen[string(key)] {
+			seen[string(key)] = true
+			keys = append(keys, key)
+		}
+	}
+	encodings, err := self.subValueEncodings(keys)
+	if err != nil {
+		return
+	}
+	for _, encoding := range encodings {
+		if encoding != encodings[0] {
+			return
+		}
+	}
+	if len(encodings) > 0 {
+		if merge, found := common.DefaultMerge(encodings[0]); found {
+			setMerges(expr.Op, merge)
+		}
+	}
+	return
+}
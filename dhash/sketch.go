@@ -0,0 +1,171 @@
+package dhash
+
+import (
+	"fmt"
+
+	"github.com/zond/god/common"
+)
+
+// PFAdd will add op.Elements to the HyperLogLog under op.Key, creating it if missing, replicate it, and set changed to whether it changed.
+func (self *Node) PFAdd(op common.SketchOp, changed *bool) (err error) {
+	var f bool
+	if f, err = self.forwardUnlessOwner("DHash.PFAdd", op.Key, op, changed); f {
+		return
+	}
+	_, err = self.update(common.Item{Key: op.Key, Sync: op.Sync, Acks: op.Acks, Mergeable: true}, false, func(oldBytes []byte, existed bool) (newBytes []byte, err error) {
+		hll := common.NewHyperLogLog()
+		if existed {
+			if hll, err = common.ParseHyperLogLog(oldBytes); err != nil {
+				return
+			}
+			hll = append(common.HyperLogLog{}, hll...)
+		}
+		*changed = !existed
+		for _, element := range op.Elements {
+			if hll.Add(element) {
+				*changed = true
+			}
+		}
+		if *changed {
+			newBytes = hll
+		}
+		return
+	})
+	return
+}
+
+// PFCount will set result to the estimated number of distinct elements added to the HyperLogLog under op.Key.
+func (self *Node) PFCount(op common.SketchOp, result *int) (err error) {
+	var f bool
+	if f, err = self.forwardUnlessOwner("DHash.PFCount", op.Key, op, result); f {
+		return
+	}
+	if value, _, existed := self.tree.Get(op.Key); existed {
+		var hll common.HyperLogLog
+		if hll, err = common.ParseHyperLogLog(value); err != nil {
+			return
+		}
+		*result = hll.Count()
+	}
+	return
+}
+
+// PFMerge will merge the HyperLogLogs under op.Sources, fetched from their owners, into the HyperLogLog under op.Key, creating it if missing,
+// and replicate it.
+func (self *Node) PFMerge(op common.SketchOp, x *int) (err error) {
+	var f bool
+	if f, err = self.forwardUnlessOwner("DHash.PFMerge", op.Key, op, x); f {
+		return
+	}
+	var sources []common.HyperLogLog
+	for _, key := range op.Sources {
+		var item common.Item
+		var forwarded bool
+		if forwarded, err = self.forwardUnlessOwner("DHash.Get", key, common.Item{Key: key}, &item); err != nil {
+			return
+		}
+		if !forwarded {
+			item.Value, _, item.Exists = self.tree.Get(key)
+		}
+		if item.Exists {
+			var hll common.HyperLogLog
+			if hll, err = common.ParseHyperLogLog(item.Value); err != nil {
+				return
+			}
+			sources = append(sources, hll)
+		}
+	}
+	_, err = self.update(common.Item{Key: op.Key, Sync: op.Sync, Acks: op.Acks, Mergeable: true}, false, func(oldBytes []byte, existed bool) (newBytes []byte, err error) {
+		hll := common.NewHyperLogLog()
+		if existed {
+			if hll, err = common.ParseHyperLogLog(oldBytes); err != nil {
+				return
+			}
+			hll = append(common.HyperLogLog{}, hll...)
+		}
+		changed := !existed
+		for _, source := range sources {
+			var merged bool
+			if merged, err = hll.Merge(source); err != nil {
+				return
+			}
+			changed = changed || merged
+		}
+		if changed {
+			newBytes = hll
+		}
+		return
+	})
+	return
+}
+
+// BFReserve will create a Bloom filter under op.Key with a false positive rate of op.ErrorRate when it contains op.Capacity elements,
+// unless there already is a value under op.Key, and replicate it.
+func (self *Node) BFReserve(op common.SketchOp, x *int) (err error) {
+	var f bool
+	if f, err = self.forwardUnlessOwner("DHash.BFReserve", op.Key, op, x); f {
+		return
+	}
+	var bf common.BloomFilter
+	if bf, err = common.NewBloomFilter(op.Capacity, op.ErrorRate); err != nil {
+		return
+	}
+	_, err = self.update(common.Item{Key: op.Key, Sync: op.Sync, Acks: op.Acks, Mergeable: true}, false, func(oldBytes []byte, existed bool) (newBytes []byte, err error) {
+		if existed {
+			err = fmt.Errorf("%#v already has a value", string(op.Key))
+			return
+		}
+		return bf, nil
+	})
+	return
+}
+
+// BFAdd will add op.Elements to the Bloom filter under op.Key, creating one with common.BloomFilterCapacity and common.BloomFilterErrorRate if missing,
+// replicate it, and set added to the number of elements that were not probably there already.
+func (self *Node) BFAdd(op common.SketchOp, added *int) (err error) {
+	var f bool
+	if f, err = self.forwardUnlessOwner("DHash.BFAdd", op.Key, op, added); f {
+		return
+	}
+	_, err = self.update(common.Item{Key: op.Key, Sync: op.Sync, Acks: op.Acks, Mergeable: true}, false, func(oldBytes []byte, existed bool) (newBytes []byte, err error) {
+		var bf common.BloomFilter
+		if existed {
+			if bf, err = common.ParseBloomFilter(oldBytes); err != nil {
+				return
+			}
+			bf = append(common.BloomFilter{}, bf...)
+		} else if bf, err = common.NewBloomFilter(common.BloomFilterCapacity, common.BloomFilterErrorRate); err != nil {
+			return
+		}
+		*added = 0
+		for _, element := range op.Elements {
+			if bf.Add(element) {
+				*added++
+			}
+		}
+		if *added > 0 || !existed {
+			newBytes = bf
+		}
+		return
+	})
+	return
+}
+
+// BFExists will set result to whether each of op.Elements has probably been added to the Bloom filter under op.Key.
+func (self *Node) BFExists(op common.SketchOp, result *[]bool) (err error) {
+	var f bool
+	if f, err = self.forwardUnlessOwner("DHash.BFExists", op.Key, op, result); f {
+		return
+	}
+	*result = make([]bool, len(op.Elements))
+	if value, _, existed := self.tree.Get(op.Key); existed {
+		var bf common.BloomFilter
+		if bf, err = common.ParseBloomFilter(value); err != nil {
+			return
+		}
+		for index, element := range op.Elements {
+			(*result)[index] = bf.Exists(element)
+		}
+	}
+	return
+}
@@ -0,0 +1,420 @@
+package dhash
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zond/god/common"
+	"github.com/zond/god/persistence"
+)
+
+// txnTimeout is how long a prepared transaction will wait for a commit or abort before its node asks the coordinator of it what to do.
+const txnTimeout = 10 * time.Second
+
+// txnDecisionLifetime is how long the coordinator of a transaction will remember whether it was committed.
+const txnDecisionLifetime = time.Hour
+
+const (
+	txnLogDir          = "txns"     // the directory inside the log directory of a Node where its transaction log is kept
+	preparedTxnsLogKey = "prepared" // the key in the transaction log under which prepared transactions are logged, with their ids as sub keys
+	txnDecisionsLogKey = "decided"  // the key in the transaction log under which transaction decisions are logged, with their ids as sub keys
+)
+
+type preparedTxn struct {
+	txn      common.Txn
+	deadline int64
+}
+
+type txnDecision struct {
+	commit   bool
+	deadline int64
+}
+
+func txnLockKey(op common.TxnOp) string {
+	if op.Sub {
+		return fmt.Sprintf("%x.%x", op.Key, op.SubKey)
+	}
+	return fmt.Sprintf("%x", op.Key)
+}
+
+// itemTxnOp returns the TxnOp locking the same key as a put of item would, with the value it puts.
+func itemTxnOp(item common.Item) common.TxnOp {
+	return common.TxnOp{Key: item.Key, SubKey: item.SubKey, Sub: item.SubKey != nil, Value: item.Value}
+}
+
+// delTxnOp returns the TxnOp locking the same key as a delete of item would.
+func delTxnOp(item common.Item) common.TxnOp {
+	return common.TxnOp{Key: item.Key, SubKey: item.SubKey, Sub: item.SubKey != nil, Del: true}
+}
+
+// logTxn will dump op into the transaction log of this Node, if it has one.
+// Must be called with txnLock held, so that the log is in the same order as the changes.
+func (self *Node) logTxn(op persistence.Op) {
+	if self.txnLogger != nil && self.txnLogger.Recording() {
+		self.txnLogger.Dump(op)
+	}
+}
+
+// syncTxns will return when everything logged by logTxn before it was called is durable.
+func (self *Node) syncTxns() {
+	if self.txnLogger != nil {
+		self.txnLogger.Sync()
+	}
+}
+
+// restoreTxn will apply an op from the transaction log, recreating the prepared transactions and decisions logged before a restart.
+func (self *Node) restoreTxn(op persistence.Op) {
+	self.txnLock.Lock()
+	defer self.txnLock.Unlock()
+	switch string(op.Key) {
+	case preparedTxnsLogKey:
+		if prepared, found := self.preparedTxns[string(op.SubKey)]; found {
+			self.releaseTxn(prepared)
+		}
+		if op.Put {
+			var txn common.Txn
+			if err := gob.NewDecoder(bytes.NewBuffer(op.Value)).Decode(&txn); err != nil {
+				panic(err)
+			}
+			self.lockTxn(&preparedTxn{
+				txn:      txn,
+				deadline: op.Expires,
+			})
+		}
+	case txnDecisionsLogKey:
+		if op.Put {
+			self.txnDecisions[string(op.SubKey)] = &txnDecision{
+				commit:   len(op.Value) == 1 && op.Value[0] == 1,
+				deadline: op.Expires,
+			}
+		} else {
+			delete(self.txnDecisions, string(op.SubKey))
+		}
+	}
+}
+
+// lockTxn will remember prepared and lock its keys, and log that it is prepared. Must be called with txnLock held.
+func (self *Node) lockTxn(prepared *preparedTxn) {
+	id := string(prepared.txn.Id)
+	for _, ops := range [][]common.TxnOp{prepared.txn.Reads, prepared.txn.Writes} {
+		for _, op := range ops {
+			self.txnKeys[txnLockKey(op)] = id
+		}
+	}
+	self.preparedTxns[id] = prepared
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(prepared.txn); err != nil {
+		panic(err)
+	}
+	self.logTxn(persistence.Op{
+		Key:     []byte(preparedTxnsLogKey),
+		SubKey:  prepared.txn.Id,
+		Value:   buf.Bytes(),
+		Expires: prepared.deadline,
+		Put:     true,
+	})
+}
+
+// takeTxn will forget the prepared transaction with id, without releasing its keys, so that no other Node method will commit or abort it.
+// Must be called with txnLock held.
+func (self *Node) takeTxn(id string) (prepared *preparedTxn, ok bool) {
+	if prepared, ok = self.preparedTxns[id]; ok {
+		delete(self.preparedTxns, id)
+	}
+	return
+}
+
+// releaseTxn will forget prepared and release its keys, and log that it is no longer prepared. Must be called with txnLock held.
+func (self *Node) releaseTxn(prepared *preparedTxn) {
+	id := string(prepared.txn.Id)
+	for _, ops := range [][]common.TxnOp{prepared.txn.Reads, prepared.txn.Writes} {
+		for _, op := range ops {
+			if self.txnKeys[txnLockKey(op)] == id {
+				delete(self.txnKeys, txnLockKey(op))
+			}
+		}
+	}
+	if self.preparedTxns[id] == prepared {
+		delete(self.preparedTxns, id)
+	}
+	self.logTxn(persistence.Op{
+		Key:    []byte(preparedTxnsLogKey),
+		SubKey: prepared.txn.Id,
+	})
+}
+
+// recordDecision will remember decision about the transaction with id, and log it. Must be called with txnLock held.
+func (self *Node) recordDecision(id string, decision *txnDecision) {
+	self.txnDecisions[id] = decision
+	value := []byte{0}
+	if decision.commit {
+		value[0] = 1
+	}
+	self.logTxn(persistence.Op{
+		Key:     []byte(txnDecisionsLogKey),
+		SubKey:  []byte(id),
+		Value:   value,
+		Expires: decision.deadline,
+		Put:     true,
+	})
+}
+
+// cleanTxns will forget old transaction decisions, and resolve the prepared transactions that have waited too long for a commit or abort
+// by asking their coordinators whether they were committed. Transactions whose coordinators can't be reached stay prepared.
+func (self *Node) cleanTxns() {
+	self.txnLock.Lock()
+	now := time.Now().UnixNano()
+	for id, decision := range self.txnDecisions {
+		if decision.deadline < now {
+			delete(self.txnDecisions, id)
+			self.logTxn(persistence.Op{
+				Key:    []byte(txnDecisionsLogKey),
+				SubKey: []byte(id),
+			})
+		}
+	}
+	var inDoubt [][]byte
+	for _, prepared := range self.preparedTxns {
+		if prepared.deadline < now {
+			inDoubt = append(inDoubt, prepared.txn.Id)
+		}
+	}
+	self.txnLock.Unlock()
+	for _, id := range inDoubt {
+		var committed bool
+		if err := self.ResolveTxn(id, &committed); err == nil {
+			if committed {
+				self.CommitTxn(id)
+			} else {
+				self.AbortTxn(id)
+			}
+		}
+	}
+}
+
+// preparedError returns an error if a prepared transaction has locked op. Must be called with txnLock held.
+func (self *Node) preparedError(op common.TxnOp) error {
+	if id, found := self.txnKeys[txnLockKey(op)]; found {
+		return fmt.Errorf("%v has a prepared transaction %x using %v", self.node, id, txnLockKey(op))
+	}
+	return nil
+}
+
+// writeError returns an error if a prepared transaction has locked op, or if op isn't encoded like the configuration of its sub tree requires.
+// Must be called with txnLock held.
+func (self *Node) writeError(op common.TxnOp) (err error) {
+	if err = self.preparedError(op); err != nil {
+		return
+	}
+	return self.checkEncoding(op)
+}
+
+// unlessPrepared will call f with txnLock held, unless a prepared transaction has locked any of ops, or any of ops isn't encoded
+// like the configuration of its sub tree requires.
+// It will first wait for any running Procedure for the keys of ops to finish.
+func (self *Node) unlessPrepared(f func(), ops ...common.TxnOp) error {
+	return self.unlessPreparedFor(nil, f, ops...)
+}
+
+// unlessPreparedFor will do unlessPrepared, but not wait for proc, which is nil unless called by a running Procedure.
+func (self *Node) unlessPreparedFor(proc *ProcedureContext, f func(), ops ...common.TxnOp) (err error) {
+	self.txnLock.Lock()
+	defer self.txnLock.Unlock()
+	for _, op := range ops {
+		self.waitForProcedures(proc, op.Key)
+	}
+	for _, op := range ops {
+		if err = self.writeError(op); err != nil {
+			return
+		}
+	}
+	f()
+	return
+}
+
+// unlessPreparedTree will call f with txnLock held, unless a prepared transaction has locked any sub key in the sub tree key.
+// It will first wait for any running Procedure for key to finish.
+func (self *Node) unlessPreparedTree(key []byte, f func()) error {
+	return self.unlessPreparedTreeFor(nil, key, f)
+}
+
+// unlessPreparedTreeFor will do unlessPreparedTree, but not wait for proc, which is nil unless called by a running Procedure.
+func (self *Node) unlessPreparedTreeFor(proc *ProcedureContext, key []byte, f func()) error {
+	self.txnLock.Lock()
+	defer self.txnLock.Unlock()
+	self.waitForProcedures(proc, key)
+	prefix := fmt.Sprintf("%x.", key)
+	for lockKey, id := range self.txnKeys {
+		if strings.HasPrefix(lockKey, prefix) {
+			return fmt.Errorf("%v has a prepared transaction %x using %v", self.node, id, lockKey)
+		}
+	}
+	f()
+	return nil
+}
+
+// PrepareTxn will validate that no value read in txn has changed, and that no other prepared transaction uses the same keys,
+// and then lock the keys of txn against other transactions and plain writes until it is committed or aborted. prepared will be set to whether it succeeded.
+// The prepared transaction is logged, and will stay prepared if the node restarts.
+//
+// This node must own all keys in txn.
+func (self *Node) PrepareTxn(txn common.Txn, prepared *bool) (err error) {
+	if err = self.prepareTxn(txn, prepared); err == nil && *prepared {
+		self.syncTxns()
+	}
+	return
+}
+func (self *Node) prepareTxn(txn common.Txn, prepared *bool) (err error) {
+	self.txnLock.Lock()
+	defer self.txnLock.Unlock()
+	if _, found := self.preparedTxns[string(txn.Id)]; found {
+		return fmt.Errorf("%v already has a prepared transaction %x", self.node, txn.Id)
+	}
+	for _, ops := range [][]common.TxnOp{txn.Reads, txn.Writes} {
+		for _, op := range ops {
+			if succ := self.node.GetSuccessorFor(op.Key); succ.Addr != self.node.GetBroadcastAddr() {
+				return fmt.Errorf("%v is not the owner of %x, %v is", self.node, op.Key, succ)
+			}
+			if _, found := self.txnKeys[txnLockKey(op)]; found {
+				return
+			}
+			if _, found := self.procKeys[string(op.Key)]; found {
+				return
+			}
+		}
+	}
+	for _, op := range txn.Writes {
+		if err = self.checkEncoding(op); err != nil {
+			return
+		}
+	}
+	var timestamp int64
+	for _, op := range txn.Reads {
+		if op.Sub {
+			_, timestamp, _ = self.tree.SubGet(op.Key, op.SubKey)
+		} else {
+			_, timestamp, _ = self.tree.Get(op.Key)
+		}
+		if timestamp != op.Timestamp {
+			return
+		}
+	}
+	self.lockTxn(&preparedTxn{
+		txn:      txn,
+		deadline: time.Now().Add(txnTimeout).UnixNano(),
+	})
+	*prepared = true
+	return
+}
+
+// CommitTxn will perform the writes of the prepared transaction with id, and release its keys.
+// The keys stay locked while the writes are replicated, but txnLock is not held.
+func (self *Node) CommitTxn(id []byte) (err error) {
+	self.txnLock.Lock()
+	prepared, ok := self.takeTxn(string(id))
+	self.txnLock.Unlock()
+	if !ok {
+		return fmt.Errorf("%v has no prepared transaction %x", self.node, id)
+	}
+	for _, op := range prepared.txn.Writes {
+		data := common.Item{
+			Key:       op.Key,
+			SubKey:    op.SubKey,
+			Value:     op.Value,
+			Sync:      prepared.txn.Sync,
+			Acks:      prepared.txn.Acks,
+			TTL:       self.node.Redundancy(),
+			Timestamp: self.timer.ContinuousTime(),
+		}
+		if op.Sub {
+			if op.Del {
+				self.subDel(data)
+			} else {
+				self.subPut(data)
+			}
+		} else {
+			if op.Del {
+				self.del(data)
+			} else {
+				self.put(data)
+			}
+		}
+	}
+	self.txnLock.Lock()
+	defer self.txnLock.Unlock()
+	self.releaseTxn(prepared)
+	return
+}
+
+// AbortTxn will forget the prepared transaction with id, and release its keys.
+func (self *Node) AbortTxn(id []byte) {
+	self.txnLock.Lock()
+	defer self.txnLock.Unlock()
+	if prepared, ok := self.takeTxn(string(id)); ok {
+		self.releaseTxn(prepared)
+	}
+}
+
+// decideTxn will record that the transaction with id is committed if commit is set, or aborted otherwise, unless that is already decided,
+// and return whether it is committed. New decisions are logged and replicated before they are returned.
+func (self *Node) decideTxn(id []byte, commit bool) bool {
+	self.txnLock.Lock()
+	decision, found := self.txnDecisions[string(id)]
+	if !found {
+		decision = &txnDecision{
+			commit:   commit,
+			deadline: time.Now().Add(txnDecisionLifetime).UnixNano(),
+		}
+		self.recordDecision(string(id), decision)
+	}
+	self.txnLock.Unlock()
+	if !found {
+		self.syncTxns()
+		self.replicate(common.Item{
+			Key:    id,
+			Exists: commit,
+			TTL:    self.node.Redundancy(),
+			Sync:   true,
+		}, "DHash.SlaveDecideTxn")
+	}
+	return decision.commit
+}
+
+// DecideTxn will make the coordinator of the transaction with id, the owner of id, record that it is committed unless a prepared owner
+// has already made it record that it is aborted. committed will be set to whether it is committed.
+func (self *Node) DecideTxn(id []byte, committed *bool) (err error) {
+	var f bool
+	if f, err = self.forwardUnlessOwner("DHash.DecideTxn", id, id, committed); f {
+		return
+	}
+	*committed = self.decideTxn(id, true)
+	return
+}
+
+// ResolveTxn will make the coordinator of the transaction with id, the owner of id, record that it is aborted unless it is already committed.
+// committed will be set to whether it is committed.
+func (self *Node) ResolveTxn(id []byte, committed *bool) (err error) {
+	var f bool
+	if f, err = self.forwardUnlessOwner("DHash.ResolveTxn", id, id, committed); f {
+		return
+	}
+	*committed = self.decideTxn(id, false)
+	return
+}
+
+// SlaveDecideTxn will record and log the decision in data, made by the coordinator of the transaction data.Key, and replicate it.
+func (self *Node) SlaveDecideTxn(data common.Item) {
+	self.txnLock.Lock()
+	if _, found := self.txnDecisions[string(data.Key)]; !found {
+		self.recordDecision(string(data.Key), &txnDecision{
+			commit:   data.Exists,
+			deadline: time.Now().Add(txnDecisionLifetime).UnixNano(),
+		})
+	}
+	self.txnLock.Unlock()
+	self.syncTxns()
+	self.replicate(data, "DHash.SlaveDecideTxn")
+}
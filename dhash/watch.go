@@ -0,0 +1,79 @@
+package dhash
+
+import (
+	"time"
+
+	"github.com/zond/god/common"
+)
+
+const (
+	// maxBufferedChanges is the number of recent changes a dhash.Node remembers for its watchers.
+	maxBufferedChanges = 4096
+	// watchTimeout is how long a Watch will wait for changes before returning without any.
+	watchTimeout = time.Second * 5
+)
+
+// recordChange will remember change for the watchers of this node, and wake them up.
+// The changes are kept in a ring buffer, where the change with Seq s is at index (s - 1) % maxBufferedChanges.
+func (self *Node) recordChange(change common.Change) {
+	self.changeLock.Lock()
+	defer self.changeLock.Unlock()
+	self.changeSeq++
+	change.Seq = self.changeSeq
+	if len(self.changes) < maxBufferedChanges {
+		self.changes = append(self.changes, change)
+	} else {
+		self.changes[(change.Seq-1)%maxBufferedChanges] = change
+	}
+	self.changeCond.Broadcast()
+}
+
+// eachChange will call f with the remembered changes with Seq greater than after, oldest first.
+// Must be called with changeLock held.
+func (self *Node) eachChange(after int64, f func(change common.Change)) {
+	if oldest := self.changeSeq - int64(len(self.changes)); after < oldest {
+		after = oldest
+	}
+	for seq := after + 1; seq <= self.changeSeq; seq++ {
+		f(self.changes[(seq-1)%maxBufferedChanges])
+	}
+}
+
+// Watch will wait until there are changes matching w that w has not seen, or until watchTimeout has passed, and put them in result.
+// If w.Seq is -1 it will return at once, with only the current Seq and Time of this node in result.
+//
+// Only the last maxBufferedChanges changes are remembered, so watchers that fall too far behind will miss changes.
+func (self *Node) Watch(w common.Watch, result *common.WatchResult) error {
+	if w.Seq < 0 {
+		self.changeLock.Lock()
+		defer self.changeLock.Unlock()
+		result.Seq, result.Time = self.changeSeq, self.timer.ContinuousTime()
+		return nil
+	}
+	deadline := time.Now().Add(watchTimeout)
+	timer := time.AfterFunc(watchTimeout, func() {
+		self.changeLock.Lock()
+		defer self.changeLock.Unlock()
+		self.changeCond.Broadcast()
+	})
+	defer timer.Stop()
+	self.changeLock.Lock()
+	defer self.changeLock.Unlock()
+	if w.Seq > self.changeSeq {
+		w.Seq = 0
+	}
+	for {
+		self.eachChange(w.Seq, func(change common.Change) {
+			if (w.Seq > 0 || change.Timestamp > w.After) && w.Matches(change) {
+				result.Changes = append(result.Changes, change)
+			}
+		})
+		if len(result.Changes) > 0 || !time.Now().Before(deadline) {
+			break
+		}
+		w.Seq = self.changeSeq
+		self.changeCond.Wait()
+	}
+	result.Seq, result.Time = self.changeSeq, self.timer.ContinuousTime()
+	return nil
+}
@@ -12,8 +12,10 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/zond/god/client"
+	"github.com/zond/god/common"
 	"github.com/zond/setop"
 )
 
@@ -107,22 +109,45 @@ var actions = map[*actionSpec]action{
 	newActionSpec("slice \\S+ \\S+ \\S+"):                   slice,
 	newActionSpec("sliceLen \\S+ \\S+ \\d+"):                sliceLen,
 	newActionSpec("reverseSliceLen \\S+ \\S+ \\d+"):         reverseSliceLen,
+	newActionSpec("scan"):                                   scan,
 	newActionSpec("setOp .+"):                               setOp,
 	newActionSpec("dumpSetOp \\S+ .+"):                      dumpSetOp,
 	newActionSpec("put \\S+ \\S+"):                          put,
+	newActionSpec("putTTL \\S+ \\S+ \\S+"):                  putTTL,
+	newActionSpec("compareAndSwap \\S+ \\S+ \\S+"):          compareAndSwap,
+	newActionSpec("putIfAbsent \\S+ \\S+"):                  putIfAbsent,
+	newActionSpec("delIfEqual \\S+ \\S+"):                   delIfEqual,
+	newActionSpec("incr \\S+ -?\\d+"):                       incr,
 	newActionSpec("clear"):                                  clear,
+	newActionSpec("backup \\S+"):                            backup,
+	newActionSpec("restore \\S+"):                           restore,
 	newActionSpec("dump"):                                   dump,
 	newActionSpec("subDump \\S+"):                           subDump,
 	newActionSpec("subSize \\S+"):                           subSize,
 	newActionSpec("size"):                                   size,
 	newActionSpec("count \\S+ \\S+ \\S+"):                   count,
 	newActionSpec("mirrorCount \\S+ \\S+ \\S+"):             mirrorCount,
+	newActionSpec("subAggregate \\S+ \\S+ \\S+ \\S+ \\S+"):  subAggregate,
 	newActionSpec("get \\S+"):                               get,
 	newActionSpec("del \\S+"):                               del,
 	newActionSpec("subPut \\S+ \\S+ \\S+"):                  subPut,
+	newActionSpec("subPutTTL \\S+ \\S+ \\S+ \\S+"):          subPutTTL,
+	newActionSpec("subCompareAndSwap \\S+ \\S+ \\S+ \\S+"):  subCompareAndSwap,
+	newActionSpec("subPutIfAbsent \\S+ \\S+ \\S+"):          subPutIfAbsent,
+	newActionSpec("subDelIfEqual \\S+ \\S+ \\S+"):           subDelIfEqual,
+	newActionSpec("subIncr \\S+ \\S+ -?\\d+"):               subIncr,
 	newActionSpec("subGet \\S+ \\S+"):                       subGet,
 	newActionSpec("subDel \\S+ \\S+"):                       subDel,
 	newActionSpec("subClear \\S+"):                          subClear,
+	newActionSpec("subPrefixSlice \\S+ \\S+"):               subPrefixSlice,
+	newActionSpec("subPrefixCount \\S+ \\S+"):               subPrefixCount,
+	newActionSpec("subPrefixDel \\S+ \\S+"):                 subPrefixDel,
+	newActionSpec("subDelRangeIndex \\S+ \\d+ \\d+"):        subDelRangeIndex,
+	newActionSpec("subDelRange \\S+ \\S+ \\S+"):             subDelRange,
+	newActionSpec("geoAdd \\S+ \\S+ \\S+ \\S+"):             geoAdd,
+	newActionSpec("geoDel \\S+ \\S+"):                       geoDel,
+	newActionSpec("geoRadius \\S+ \\S+ \\S+ \\S+"):          geoRadius,
+	newActionSpec("geoBox \\S+ \\S+ \\S+ \\S+ \\S+"):        geoBox,
 	newActionSpec("describeAll"):                            describeAll,
 	newActionSpec("describe \\S+"):                          describe,
 	newActionSpec("describeTree \\S+"):                      describeTree,
@@ -151,6 +176,22 @@ var actions = map[*actionSpec]action{
 	newActionSpec("subConfigure \\S+ \\S+ \\S+"):            subConfigure,
 }
 
+func mustParseDuration(s string) time.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func mustParseFloat(s string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
 func mustAtoi(s string) *int {
 	i, err := strconv.Atoi(s)
 	if err != nil {
@@ -255,6 +296,88 @@ func reverseSliceLen(conn *client.Conn, args []string) {
 	}
 }
 
+func scan(conn *client.Conn, args []string) {
+	flags := flag.NewFlagSet("scan", flag.ExitOnError)
+	key := flags.String("key", "", "The sub tree to scan, or empty to scan the top level keys")
+	min := flags.String("min", "", "The key to start at (inclusive), or empty to start at the first key")
+	max := flags.String("max", "", "The key to stop at (exclusive), or empty to stop at the last key")
+	reverse := flags.Bool("reverse", false, "Whether to scan from max to min")
+	page := flags.Int("page", client.DefaultScanPageSize, "The number of items to fetch per request")
+	flags.Parse(args[1:])
+	var k, mi, ma []byte
+	if *key != "" {
+		k = []byte(*key)
+	}
+	if *min != "" {
+		mi = []byte(*min)
+	}
+	if *max != "" {
+		ma = []byte(*max)
+	}
+	cursor := conn.Scan(k, mi, ma, client.ScanOptions{
+		MinInc:   true,
+		Reverse:  *reverse,
+		PageSize: *page,
+	})
+	for cursor.Next() {
+		item := cursor.Item()
+		fmt.Printf("%v => %v\n", string(item.Key), decode(item.Value))
+	}
+	if err := cursor.Err(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func subPrefixSlice(conn *client.Conn, args []string) {
+	for i, item := range conn.SubPrefixSlice([]byte(args[1]), []byte(args[2])) {
+		fmt.Printf("%v: %v => %v\n", i, string(item.Key), decode(item.Value))
+	}
+}
+
+func subPrefixCount(conn *client.Conn, args []string) {
+	fmt.Println(conn.SubPrefixCount([]byte(args[1]), []byte(args[2])))
+}
+
+func subPrefixDel(conn *client.Conn, args []string) {
+	fmt.Println(conn.SSubPrefixDel([]byte(args[1]), []byte(args[2])))
+}
+
+func subDelRange(conn *client.Conn, args []string) {
+	fmt.Println(conn.SSubDelRange([]byte(args[1]), []byte(args[2]), []byte(args[3]), true, false))
+}
+
+func subDelRangeIndex(conn *client.Conn, args []string) {
+	fmt.Println(conn.SSubDelRangeIndex([]byte(args[1]), mustAtoi(args[2]), mustAtoi(args[3])))
+}
+
+func geoAdd(conn *client.Conn, args []string) {
+	if err := conn.SGeoAdd([]byte(args[1]), []byte(args[2]), mustParseFloat(args[3]), mustParseFloat(args[4])); err != nil {
+		fmt.Println(err)
+	}
+}
+
+func geoDel(conn *client.Conn, args []string) {
+	fmt.Println(conn.SGeoDel([]byte(args[1]), []byte(args[2])))
+}
+
+func printGeoMembers(members []common.GeoMember, err error) {
+	if err != nil {
+		fmt.Println(err)
+	}
+	for _, member := range members {
+		fmt.Printf("%v: %v,%v (%vm)\n", string(member.Member), member.Lat, member.Lon, member.Distance)
+	}
+}
+
+func geoRadius(conn *client.Conn, args []string) {
+	printGeoMembers(conn.GeoRadius([]byte(args[1]), mustParseFloat(args[2]), mustParseFloat(args[3]), mustParseFloat(args[4])))
+}
+
+func geoBox(conn *client.Conn, args []string) {
+	printGeoMembers(conn.GeoBox([]byte(args[1]), mustParseFloat(args[2]), mustParseFloat(args[3]), mustParseFloat(args[4]), mustParseFloat(args[5])))
+}
+
 func printSetOpRes(res setop.SetOpResult) {
 	var vals []string
 	for _, val := range res.Values {
@@ -321,6 +444,21 @@ func count(conn *client.Conn, args []string) {
 	fmt.Println(conn.Count([]byte(args[1]), []byte(args[2]), []byte(args[3]), true, false))
 }
 
+func subAggregate(conn *client.Conn, args []string) {
+	result, err := conn.SubAggregate([]byte(args[1]), []byte(args[2]), []byte(args[3]), true, false, args[4], args[5])
+	if err != nil {
+		fmt.Println(err)
+	} else if result == nil {
+		fmt.Println(result)
+	} else if args[5] == common.EncodingInt64 && args[4] != common.AggregateAvg {
+		i, _ := setop.DecodeInt64(result)
+		fmt.Println(i)
+	} else {
+		f, _ := setop.DecodeFloat64(result)
+		fmt.Println(f)
+	}
+}
+
 func mirrorPrevIndex(conn *client.Conn, args []string) {
 	if key, value, index, existed := conn.MirrorPrevIndex([]byte(args[1]), *(mustAtoi(args[2]))); existed {
 		fmt.Printf("%v: %v => %v\n", index, decode(key), string(value))
@@ -455,6 +593,30 @@ func clear(conn *client.Conn, args []string) {
 	conn.Clear()
 }
 
+func backup(conn *client.Conn, args []string) {
+	file, err := os.Create(args[1])
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer file.Close()
+	if err = conn.Backup(file); err != nil {
+		fmt.Println(err)
+	}
+}
+
+func restore(conn *client.Conn, args []string) {
+	file, err := os.Open(args[1])
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer file.Close()
+	if err = conn.Restore(file); err != nil {
+		fmt.Println(err)
+	}
+}
+
 func dump(conn *client.Conn, args []string) {
 	dump, wait := conn.Dump()
 	linedump(dump, wait)
@@ -491,10 +653,66 @@ func put(conn *client.Conn, args []string) {
 	conn.Put([]byte(args[1]), encode(args[2]))
 }
 
+func putTTL(conn *client.Conn, args []string) {
+	conn.PutTTL([]byte(args[1]), encode(args[2]), mustParseDuration(args[3]))
+}
+
+func compareAndSwap(conn *client.Conn, args []string) {
+	fmt.Println(conn.CompareAndSwap([]byte(args[1]), encode(args[2]), encode(args[3])))
+}
+
+func putIfAbsent(conn *client.Conn, args []string) {
+	fmt.Println(conn.PutIfAbsent([]byte(args[1]), encode(args[2])))
+}
+
+func delIfEqual(conn *client.Conn, args []string) {
+	fmt.Println(conn.DelIfEqual([]byte(args[1]), encode(args[2])))
+}
+
+func incr(conn *client.Conn, args []string) {
+	delta, err := strconv.ParseInt(args[2], 10, 64)
+	if err != nil {
+		panic(err)
+	}
+	if result, err := conn.Incr([]byte(args[1]), delta); err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(result)
+	}
+}
+
 func subPut(conn *client.Conn, args []string) {
 	conn.SubPut([]byte(args[1]), []byte(args[2]), encode(args[3]))
 }
 
+func subPutTTL(conn *client.Conn, args []string) {
+	conn.SubPutTTL([]byte(args[1]), []byte(args[2]), encode(args[3]), mustParseDuration(args[4]))
+}
+
+func subCompareAndSwap(conn *client.Conn, args []string) {
+	fmt.Println(conn.SubCompareAndSwap([]byte(args[1]), []byte(args[2]), encode(args[3]), encode(args[4])))
+}
+
+func subPutIfAbsent(conn *client.Conn, args []string) {
+	fmt.Println(conn.SubPutIfAbsent([]byte(args[1]), []byte(args[2]), encode(args[3])))
+}
+
+func subDelIfEqual(conn *client.Conn, args []string) {
+	fmt.Println(conn.SubDelIfEqual([]byte(args[1]), []byte(args[2]), encode(args[3])))
+}
+
+func subIncr(conn *client.Conn, args []string) {
+	delta, err := strconv.ParseInt(args[3], 10, 64)
+	if err != nil {
+		panic(err)
+	}
+	if result, err := conn.SubIncr([]byte(args[1]), []byte(args[2]), delta); err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(result)
+	}
+}
+
 func subClear(conn *client.Conn, args []string) {
 	conn.SubClear([]byte(args[1]))
 }
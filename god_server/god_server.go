@@ -5,7 +5,12 @@ import (
 	"fmt"
 	"github.com/zond/god/common"
 	"github.com/zond/god/dhash"
+	"github.com/zond/god/persistence"
+	"github.com/zond/god/radix"
+	"os"
 	"runtime"
+	"strconv"
+	"time"
 )
 
 const (
@@ -19,6 +24,24 @@ var joinIp = flag.String("joinIp", "", "IP address to join.")
 var joinPort = flag.Int("joinPort", 9191, "Port to join.")
 var verbose = flag.Bool("verbose", false, "Whether the server should be log verbosely to the console.")
 var dir = flag.String("dir", address, "Where to store logfiles and snapshots. Defaults to a directory named after the listening ip/port. The empty string will turn off persistence.")
+var fsync = flag.String("fsync", "never", "When the server should fsync its logfile: 'always', 'never', every duration (like '10ms') or every number of operations (like '100ops'). Unless 'never', sync writes are acknowledged only when durable.")
+var logLimit = flag.Int64("logLimit", 0, "The size in bytes the logfile may grow to before the server merges it with the latest snapshot into a new snapshot and starts a new logfile. 0 will let the logfile grow forever.")
+var compression = flag.String("compression", "none", "How the server should compress the snapshots it creates when the logfile grows past -logLimit: 'none' or 'gzip'.")
+var verify = flag.Bool("verify", false, "Whether the server should only verify the checksums of the logfiles and snapshots in -dir, print the result and exit, with status 1 if any of them are damaged.")
+var restoreUntil = flag.String("restoreUntil", "", "A time, in RFC3339 format or nanoseconds since the epoch, to restore the logfiles and snapshots in -dir to, forgetting everything logged after it, and then exit. Should be done on all nodes of the cluster, since they will otherwise synchronize the forgotten data back. The replaced files are moved into a new directory inside -dir.")
+var restoreForce = flag.Bool("restoreForce", false, "Whether -restoreUntil should restore what it can even if the latest snapshot in -dir was created after the time to restore to, which makes it unable to restore values replaced before the snapshot was created.")
+
+// parseTime returns the nanoseconds since the epoch of s, in RFC3339 format or nanoseconds since the epoch.
+func parseTime(s string) (result int64, err error) {
+	var t time.Time
+	if t, err = time.Parse(time.RFC3339Nano, s); err == nil {
+		return t.UnixNano(), nil
+	}
+	if result, err = strconv.ParseInt(s, 10, 64); err != nil {
+		err = fmt.Errorf("%#v is neither in RFC3339 format nor nanoseconds since the epoch", s)
+	}
+	return
+}
 
 func main() {
 	runtime.GOMAXPROCS(runtime.NumCPU())
@@ -26,7 +49,59 @@ func main() {
 	if *dir == address {
 		*dir = fmt.Sprintf("%v_%v", *broadcastIp, *port)
 	}
-	s := dhash.NewNodeDir(fmt.Sprintf("%v:%v", *listenIp, *port), fmt.Sprintf("%v:%v", *broadcastIp, *port), *dir)
+	if *verify {
+		intact := true
+		if *dir != "" {
+			for _, verification := range persistence.NewLogger(*dir).Verify() {
+				fmt.Println(verification)
+				intact = intact && verification.Intact()
+			}
+		}
+		if !intact {
+			os.Exit(1)
+		}
+		return
+	}
+	fsyncPolicy, err := persistence.ParseFsyncPolicy(*fsync)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	snapshotCompression, err := persistence.ParseCompression(*compression)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if *restoreUntil != "" {
+		until, err := parseTime(*restoreUntil)
+		if err == nil && *dir == "" {
+			err = fmt.Errorf("-restoreUntil needs a -dir")
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		// The Logger gets its limit only after restoring, since starting to record with it could snapshot the operations to forget.
+		logger := persistence.NewLogger(*dir).Fsync(fsyncPolicy).Compress(snapshotCompression)
+		tree := radix.NewTree().LogWith(logger)
+		moved, err := tree.RestoreUntil(until, *restoreForce)
+		logger.Stop()
+		if err == nil && *logLimit > 0 {
+			<-logger.Limit(*logLimit).Record()
+			logger.Stop()
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Printf("Restored %v keys in %v as of %v, and moved the replaced files to %v\n", tree.Size(), *dir, time.Unix(0, until), moved)
+		return
+	}
+	var logger *persistence.Logger
+	if *dir != "" {
+		logger = persistence.NewLogger(*dir).Fsync(fsyncPolicy).Limit(*logLimit).Compress(snapshotCompression)
+	}
+	s := dhash.NewNodeLogger(fmt.Sprintf("%v:%v", *listenIp, *port), fmt.Sprintf("%v:%v", *broadcastIp, *port), logger)
 	if *verbose {
 		s.AddChangeListener(func(ring *common.Ring) bool {
 			fmt.Println(s.Describe())
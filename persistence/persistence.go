@@ -1,15 +1,22 @@
 package persistence
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
 	"encoding/gob"
 	"fmt"
+	"hash/crc32"
 	"io"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -27,26 +34,122 @@ const (
 	snapSuffix       = "snap"
 	logSuffix        = "log"
 	unfinishedSuffix = "unfinished"
+	replacedPrefix   = "replaced"
 )
 
-// Op is a simple get/put/clear or configuration operation to log or replay.
+const (
+	logVersion           = 1
+	compressedLogVersion = 2 // the version header is followed by a Compression byte, and the records are compressed
+	frameHeaderSize      = 8 // the big endian uint32 length and crc32.ChecksumIEEE of each record
+	maxFrameSize         = 1 << 30
+)
+
+// logHeader starts each logfile and snapshot, to tell them from legacy files written without checksums.
+var logHeader = []byte{'g', 'o', 'd', 'l', 'o', 'g', logVersion}
+
+// Compression is a way to compress the records of snapshots and backups.
+type Compression byte
+
+const (
+	NoCompression Compression = iota
+	GzipCompression
+)
+
+// ParseCompression returns the Compression named s, which is 'none' or 'gzip'.
+func ParseCompression(s string) (result Compression, err error) {
+	switch s {
+	case "none":
+		result = NoCompression
+	case "gzip":
+		result = GzipCompression
+	default:
+		err = fmt.Errorf("%#v is not a known compression", s)
+	}
+	return
+}
+
+func (self Compression) String() string {
+	switch self {
+	case NoCompression:
+		return "none"
+	case GzipCompression:
+		return "gzip"
+	}
+	return fmt.Sprintf("Compression(%v)", byte(self))
+}
+
+// Op is a simple get/put/clear, range delete or configuration operation to log or replay.
 type Op struct {
 	Key           []byte
 	SubKey        []byte
 	Value         []byte
 	Timestamp     int64
+	Expires       int64
+	Mergeable     bool // if true, the put value was written by a sketch operation, and is merged with instead of replaced by other mergeable values when synchronized
 	Put           bool
 	Clear         bool
 	Configuration map[string]string
+	Range         bool // if true, this deletes all sub keys between Min and Max in the sub tree under Key
+	Min           []byte
+	Max           []byte
+	MinInc        bool
+	MaxInc        bool
+}
+
+// Covers returns whether subKey is between Min and Max of this Op.
+func (self Op) Covers(subKey []byte) bool {
+	if self.Min != nil {
+		if cmp := bytes.Compare(subKey, self.Min); cmp < 0 || (cmp == 0 && !self.MinInc) {
+			return false
+		}
+	}
+	if self.Max != nil {
+		if cmp := bytes.Compare(subKey, self.Max); cmp > 0 || (cmp == 0 && !self.MaxInc) {
+			return false
+		}
+	}
+	return true
+}
+
+// Verification is the result of checking the records of a logfile or snapshot.
+type Verification struct {
+	Filename    string
+	Ops         int   // the number of intact records
+	Valid       int64 // the number of bytes up to the end of the last intact record, after decompression if the file is compressed
+	Size        int64
+	Lost        int  // the approximate number of records after the last intact one, counting a torn one
+	Legacy      bool // if true, the file was written without checksums, and Valid and Lost are unknown
+	Compression Compression
+	Err         error
+}
+
+// Intact returns whether all records of the file were intact.
+func (self Verification) Intact() bool {
+	return self.Err == nil
+}
+
+func (self Verification) String() string {
+	filename := self.Filename
+	if self.Compression != NoCompression {
+		filename = fmt.Sprintf("%v (%v)", filename, self.Compression)
+	}
+	if self.Intact() {
+		return fmt.Sprintf("%v: %v ops, intact", filename, self.Ops)
+	}
+	return fmt.Sprintf("%v: %v ops, about %v ops lost after byte %v of %v: %v", filename, self.Ops, self.Lost, self.Valid, self.Size, self.Err)
 }
 
 type logfile struct {
-	timestamp time.Time
-	filename  string
-	suffix    string
-	file      *os.File
-	encoder   *gob.Encoder
-	decoder   *gob.Decoder
+	timestamp   time.Time
+	filename    string
+	suffix      string
+	compression Compression
+	file        *os.File
+	compressor  *gzip.Writer
+	writer      io.Writer // the file, or the compressor if the logfile is compressed
+	buffer      *bytes.Buffer
+	encoder     *gob.Encoder
+	unsynced    int // the number of records written since the last fsync
 }
 
 func createLogfile(dir, suffix string) (rval *logfile) {
@@ -74,34 +177,161 @@ func parseLogfile(file string) (rval *logfile, err error) {
 	return
 }
 
-func (self *logfile) play(operate Operate) {
+// play will replay all intact records of the logfile using operate, and truncate any torn or corrupt records at the end of it.
+// It returns the approximate number of records lost.
+func (self *logfile) play(operate Operate) (lost int) {
 	if self == nil {
 		return
 	}
-	self.read()
-	defer self.close()
-	var err error
+	verification := self.scan(operate)
+	if verification.Intact() {
+		return
+	}
+	if verification.Legacy {
+		log.Printf("%v, ignoring the rest of the legacy logfile", verification)
+	} else if verification.Compression != NoCompression {
+		log.Printf("%v, ignoring the rest of the compressed logfile", verification)
+	} else {
+		if err := os.Truncate(self.filename, verification.Valid); err != nil {
+			panic(err)
+		}
+		log.Printf("%v, truncated", verification)
+	}
+	return verification.Lost
+}
+
+// scan will call operate with each intact record of the logfile, until the end of the file or the first torn or corrupt record,
+// and return a Verification of the logfile.
+func (self *logfile) scan(operate Operate) (result Verification) {
+	result.Filename = self.filename
+	file, err := os.Open(self.filename)
+	if err != nil {
+		result.Err = err
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		result.Err = err
+		return
+	}
+	result.Size = info.Size()
+	if readRecords(bufio.NewReader(file), &result, operate) {
+		result.Lost = countFrames(file, result.Valid, result.Size)
+	}
+	return
+}
+
+// readRecords will call operate with each intact record read from reader, until the end or the first torn or corrupt record, and update result.
+// It returns whether the records after the first torn or corrupt one are uncompressed, so that result.Lost can be counted using countFrames.
+func readRecords(reader *bufio.Reader, result *Verification, operate Operate) (countLost bool) {
+	header, err := reader.Peek(len(logHeader))
+	if len(header) == 0 {
+		return
+	}
+	// the last byte of the header is the version, so only the bytes before it tell a legacy file
+	magic := len(header)
+	if magic > len(logHeader)-1 {
+		magic = len(logHeader) - 1
+	}
+	if !bytes.Equal(header[:magic], logHeader[:magic]) {
+		readLegacyRecords(reader, result, operate)
+		return
+	}
+	if err != nil {
+		result.Err = err
+		return
+	}
+	version := header[len(logHeader)-1]
+	reader.Discard(len(logHeader))
+	result.Valid = int64(len(logHeader))
+	var records io.Reader = reader
+	switch version {
+	case logVersion:
+	case compressedLogVersion:
+		var compression byte
+		if compression, err = reader.ReadByte(); err != nil {
+			result.Err = err
+			return
+		}
+		result.Compression, result.Valid = Compression(compression), 0
+		switch result.Compression {
+		case GzipCompression:
+			if records, err = gzip.NewReader(reader); err != nil {
+				result.Err, result.Lost = err, 1
+				return
+			}
+		default:
+			result.Err = fmt.Errorf("Unknown compression %v", result.Compression)
+			return
+		}
+	default:
+		result.Err = fmt.Errorf("Unknown logfile version %v", version)
+		return
+	}
+	buffer := new(bytes.Buffer)
+	decoder := gob.NewDecoder(buffer)
+	frameHeader := make([]byte, frameHeaderSize)
 	for {
+		if _, err = io.ReadFull(records, frameHeader); err != nil {
+			if err == io.EOF {
+				return
+			}
+			break
+		}
+		length := binary.BigEndian.Uint32(frameHeader)
+		if length > maxFrameSize {
+			err = fmt.Errorf("Record length %v is bigger than %v", length, maxFrameSize)
+			break
+		}
+		buffer.Reset()
+		if _, err = io.CopyN(buffer, records, int64(length)); err != nil {
+			break
+		}
+		if checksum := crc32.ChecksumIEEE(buffer.Bytes()); checksum != binary.BigEndian.Uint32(frameHeader[4:]) {
+			err = fmt.Errorf("Record checksum %v is not %v", checksum, binary.BigEndian.Uint32(frameHeader[4:]))
+			break
+		}
 		var op Op
-		err = self.decoder.Decode(&op)
-		if err != nil {
+		if err = decoder.Decode(&op); err != nil {
 			break
 		}
 		operate(op)
+		result.Ops++
+		result.Valid += frameHeaderSize + int64(length)
 	}
-	if err != io.EOF {
-		panic(err)
+	result.Err, result.Lost = err, 1
+	return result.Compression == NoCompression
+}
+
+// readLegacyRecords will call operate with each record read from reader, written without checksums, until the first error.
+func readLegacyRecords(reader io.Reader, result *Verification, operate Operate) {
+	result.Legacy = true
+	decoder := gob.NewDecoder(reader)
+	for {
+		var op Op
+		if err := decoder.Decode(&op); err != nil {
+			if err != io.EOF {
+				result.Err, result.Lost = err, 1
+			}
+			return
+		}
+		operate(op)
+		result.Ops++
 	}
 }
 
-func (self *logfile) read() *logfile {
-	var err error
-	self.file, err = os.Open(self.filename)
-	if err != nil {
-		panic(err)
+// countFrames returns the number of records, intact or not, the lengths in their headers say there are between offset and size in file.
+func countFrames(file *os.File, offset, size int64) (result int) {
+	frameHeader := make([]byte, frameHeaderSize)
+	for offset < size {
+		result++
+		if _, err := file.ReadAt(frameHeader, offset); err != nil {
+			return
+		}
+		offset += frameHeaderSize + int64(binary.BigEndian.Uint32(frameHeader))
 	}
-	self.decoder = gob.NewDecoder(self.file)
-	return self
+	return
 }
 
 func (self *logfile) write() *logfile {
@@ -110,14 +340,110 @@ func (self *logfile) write() *logfile {
 	if err != nil {
 		panic(err)
 	}
-	self.encoder = gob.NewEncoder(self.file)
+	if err = self.start(self.file); err != nil {
+		panic(err)
+	}
 	return self
 }
 
+// start will make the logfile write its header, and then its records, to w.
+func (self *logfile) start(w io.Writer) (err error) {
+	self.writer = w
+	if self.compression == NoCompression {
+		_, err = w.Write(logHeader)
+	} else {
+		header := append([]byte{}, logHeader...)
+		header[len(header)-1] = compressedLogVersion
+		_, err = w.Write(append(header, byte(self.compression)))
+		self.compressor = gzip.NewWriter(w)
+		self.writer = self.compressor
+	}
+	self.buffer = new(bytes.Buffer)
+	self.encoder = gob.NewEncoder(self.buffer)
+	return
+}
+
+// encode will write op as a record prefixed by its length and checksum, using a single write so that a crash can only tear the last record.
+func (self *logfile) encode(op Op) (err error) {
+	self.buffer.Reset()
+	self.buffer.Write(make([]byte, frameHeaderSize))
+	if err = self.encoder.Encode(op); err != nil {
+		return
+	}
+	frame := self.buffer.Bytes()
+	binary.BigEndian.PutUint32(frame, uint32(len(frame)-frameHeaderSize))
+	binary.BigEndian.PutUint32(frame[4:], crc32.ChecksumIEEE(frame[frameHeaderSize:]))
+	if _, err = self.writer.Write(frame); err == nil {
+		self.unsynced++
+	}
+	return
+}
+
+// sync will fsync the logfile, unless all records written to it already are.
+func (self *logfile) sync() {
+	if self.unsynced > 0 {
+		if self.compressor != nil {
+			if err := self.compressor.Flush(); err != nil {
+				panic(err)
+			}
+		}
+		if err := self.file.Sync(); err != nil {
+			panic(err)
+		}
+		self.unsynced = 0
+	}
+}
+
+// close will close the logfile, first finishing and fsyncing the compressed stream if it is compressed.
 func (self *logfile) close() {
+	if self.compressor != nil {
+		if err := self.compressor.Close(); err != nil {
+			panic(err)
+		}
+		if err := self.file.Sync(); err != nil {
+			panic(err)
+		}
+	}
 	self.file.Close()
 }
 
+// Writer writes Ops to an io.Writer in the format of snapshots, to create backups that can be read using Read.
+type Writer struct {
+	records *logfile
+}
+
+// NewWriter returns a Writer that writes Ops to w using compression.
+func NewWriter(w io.Writer, compression Compression) (result *Writer, err error) {
+	result = &Writer{
+		records: &logfile{
+			compression: compression,
+		},
+	}
+	err = result.records.start(w)
+	return
+}
+
+// Write will write op.
+func (self *Writer) Write(op Op) error {
+	return self.records.encode(op)
+}
+
+// Close will finish writing any compressed stream. It will not close the io.Writer.
+func (self *Writer) Close() (err error) {
+	if self.records.compressor != nil {
+		err = self.records.compressor.Close()
+	}
+	return
+}
+
+// Read will call operate with each Op read from r, written by a Writer or in a logfile or snapshot, until the end or the first torn or corrupt record.
+// It returns the number of Ops read, and an error if it found a torn or corrupt record.
+func Read(r io.Reader, operate Operate) (ops int, err error) {
+	var result Verification
+	readRecords(bufio.NewReader(r), &result, operate)
+	return result.Ops, result.Err
+}
+
 type logfiles []*logfile
 
 func (self logfiles) Len() int {
@@ -130,21 +456,62 @@ func (self logfiles) Swap(i, j int) {
 	self[i], self[j] = self[j], self[i]
 }
 
+// FsyncPolicy defines when a recording Logger will fsync its logfile to make the dumped operations durable.
+// The zero FsyncPolicy only fsyncs when a logfile is closed, and makes Logger.Sync return at once.
+type FsyncPolicy struct {
+	Always   bool          // if true, the users of the Logger will wait for Logger.Sync after every operation they dump, outside any locks they hold
+	Interval time.Duration // if > 0, the logfile will be fsynced at most Interval after an operation is dumped
+	Ops      int           // if > 0, the logfile will be fsynced when Ops operations have been dumped since the last fsync
+}
+
+// ParseFsyncPolicy returns the FsyncPolicy described by s, which is 'always', 'never', a duration like '100ms', or a number of operations like '1000ops'.
+func ParseFsyncPolicy(s string) (result FsyncPolicy, err error) {
+	switch {
+	case s == "always":
+		result.Always = true
+	case s == "never":
+	case strings.HasSuffix(s, "ops"):
+		if result.Ops, err = strconv.Atoi(strings.TrimSuffix(s, "ops")); err == nil && result.Ops < 1 {
+			err = fmt.Errorf("%#v is not a positive number of operations", s)
+		}
+	default:
+		if result.Interval, err = time.ParseDuration(s); err == nil && result.Interval <= 0 {
+			err = fmt.Errorf("%#v is not a positive duration", s)
+		}
+	}
+	return
+}
+
+func (self FsyncPolicy) String() string {
+	switch {
+	case self.Always:
+		return "always"
+	case self.Interval > 0:
+		return self.Interval.String()
+	case self.Ops > 0:
+		return fmt.Sprintf("%vops", self.Ops)
+	}
+	return "never"
+}
+
 // Operate is a function that operates on an Op, for replay purposes.
 // It is supposed to insert Ops with the Put flag, Clear data if the Clear flag is set, handle configuration changes or delete data.
 type Operate func(o Op)
 
 // Logger is something that can log or replay Ops.
 type Logger struct {
-	ops      chan Op
-	stops    chan chan bool
-	dir      string
-	state    int32
-	snapping int32
-	maxSize  int64
-	suffix   string
-	cond     *sync.Cond
-	lock     *sync.Mutex
+	ops         chan Op
+	syncs       chan chan bool
+	stops       chan chan bool
+	dir         string
+	state       int32
+	snapping    int32
+	maxSize     int64
+	fsync       FsyncPolicy
+	compression Compression
+	suffix      string
+	cond        *sync.Cond
+	lock        *sync.Mutex
 }
 
 // NewLogger will return a Logger that will dump data into dir, or replay data from dir.
@@ -155,6 +522,7 @@ func NewLogger(dir string) *Logger {
 	lock := new(sync.Mutex)
 	return &Logger{
 		ops:    make(chan Op),
+		syncs:  make(chan chan bool),
 		stops:  make(chan chan bool),
 		dir:    dir,
 		suffix: logSuffix,
@@ -183,6 +551,26 @@ func (self *Logger) Limit(maxSize int64) *Logger {
 	return self
 }
 
+// Fsync will make this Logger fsync its logfile according to policy. It has to be called before Record.
+// Regardless of policy, the logfile will be fsynced when the Logger stops or starts a new logfile.
+func (self *Logger) Fsync(policy FsyncPolicy) *Logger {
+	self.fsync = policy
+	return self
+}
+
+// Compress will make this Logger compress the snapshots it creates when its logfile gets bigger than its Limit.
+// Logfiles are not compressed, so that torn records at their ends can be truncated, and since they are merged into the next snapshot anyway.
+func (self *Logger) Compress(compression Compression) *Logger {
+	self.compression = compression
+	return self
+}
+
+// Sub will return a new Logger for the directory name inside the directory of this Logger, with the same limit, fsync policy and compression.
+// Its snapshots and logfiles are not played, moved or removed by this Logger.
+func (self *Logger) Sub(name string) *Logger {
+	return NewLogger(filepath.Join(self.dir, name)).Limit(self.maxSize).Fsync(self.fsync).Compress(self.compression)
+}
+
 func (self *Logger) logfiles() (result logfiles) {
 	dir, err := os.Open(self.dir)
 	if err != nil {
@@ -228,15 +616,53 @@ func (self *Logger) Recording() bool {
 }
 
 // Play will replay the latest snapshot and all logfiles created after it using the provided operate.
-func (self *Logger) Play(operate Operate) {
+// Torn or corrupt records at the end of a file, like those left by a crash during a write, are truncated.
+// It returns the approximate number of records lost that way.
+func (self *Logger) Play(operate Operate) (lost int) {
+	lost, _, _ = self.PlayUntil(math.MaxInt64, true, operate)
+	return
+}
+
+// PlayUntil will replay like Play, but skip all operations timestamped after t, to recreate the state at t.
+// Operations without timestamps, like removals of tombstones, are always replayed.
+// Since a snapshot only contains the latest operation for each key, a value replaced after t, before the latest snapshot was created, can't be recreated.
+// If the latest snapshot was created after t, it will therefore return an error without replaying anything, unless force is set.
+// It returns the approximate number of records lost, and the number of operations skipped.
+func (self *Logger) PlayUntil(t int64, force bool, operate Operate) (lost, skipped int, err error) {
 	if self.changeState(stopped, playing) {
 		defer self.changeState(playing, stopped)
 		snapshot, logs := self.latest()
-		snapshot.play(operate)
+		if snapshot != nil && snapshot.timestamp.UnixNano() > t {
+			if !force {
+				err = fmt.Errorf("%v was created after %v, values replaced before it was created can't be recreated", snapshot.filename, time.Unix(0, t))
+				return
+			}
+			log.Printf("%v was created after %v, values replaced before it was created can't be recreated", snapshot.filename, time.Unix(0, t))
+		}
+		until := func(op Op) {
+			if op.Timestamp > t {
+				skipped++
+			} else {
+				operate(op)
+			}
+		}
+		lost += snapshot.play(until)
 		for _, logf := range logs {
-			logf.play(operate)
+			lost += logf.play(until)
 		}
 	}
+	return
+}
+
+// Verify will check the records of all snapshots and logfiles in the directory of this Logger, without modifying them,
+// and return a Verification of each.
+func (self *Logger) Verify() (result []Verification) {
+	files := self.logfiles()
+	sort.Sort(files)
+	for _, logf := range files {
+		result = append(result, logf.scan(func(op Op) {}))
+	}
+	return
 }
 
 // Stop will stop this Logger. It will not return until all running recordings or snaphots are finished.
@@ -256,7 +682,8 @@ func (self *Logger) Stop() *Logger {
 	return self
 }
 
-func (self *Logger) clearOlderThan(t time.Time) {
+// ClearOlderThan will remove all snapshots and logfiles created before t.
+func (self *Logger) ClearOlderThan(t time.Time) {
 	for _, logf := range self.logfiles() {
 		if logf.timestamp.Before(t) {
 			if err := os.Remove(logf.filename); err != nil {
@@ -266,10 +693,27 @@ func (self *Logger) clearOlderThan(t time.Time) {
 	}
 }
 
+// MoveOlderThan will move all snapshots and logfiles older than t into a new directory inside the directory of this Logger, and return its path.
+// The moved files will not be played, but can be moved back to undo whatever replaced them.
+func (self *Logger) MoveOlderThan(t time.Time) (moved string, err error) {
+	moved = filepath.Join(self.dir, fmt.Sprintf("%v.%v", replacedPrefix, t.UnixNano()))
+	if err = os.Mkdir(moved, os.ModePerm); err != nil {
+		return
+	}
+	for _, logf := range self.logfiles() {
+		if logf.timestamp.Before(t) {
+			if err = os.Rename(logf.filename, filepath.Join(moved, filepath.Base(logf.filename))); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
 // Clear will stop this Logger and remove all snapshots or logfiles older than now.
 func (self *Logger) Clear() {
 	self.Stop()
-	self.clearOlderThan(time.Now())
+	self.ClearOlderThan(time.Now())
 	<-self.Record()
 }
 
@@ -298,6 +742,17 @@ func (self *Logger) snapshot(snap *logfile, files logfiles) {
 				}
 				subMap[string(op.SubKey)] = op
 			}
+		} else if op.Range {
+			if subMap, ok = treeCompressor[string(op.Key)]; ok {
+				for subKey := range subMap {
+					if op.Covers([]byte(subKey)) {
+						delete(subMap, subKey)
+					}
+				}
+				if len(subMap) == 0 {
+					delete(treeCompressor, string(op.Key))
+				}
+			}
 		} else {
 			if op.SubKey == nil {
 				if op.Clear {
@@ -344,7 +799,7 @@ func (self *Logger) snapshotAndDelete(oldrec *logfile, p chan *logfile, snapping
 	defer atomic.StoreInt32(snapping, 0)
 	defer self.cond.Broadcast()
 	latestSnapshot, logfiles := self.latest()
-	snapshotter := NewLogger(self.dir).setSuffix(unfinishedSuffix)
+	snapshotter := NewLogger(self.dir).setSuffix(unfinishedSuffix).Compress(self.compression)
 	snapshotfile := <-snapshotter.Record()
 	p <- snapshotfile
 	snapshotter.snapshot(latestSnapshot, logfiles)
@@ -352,7 +807,7 @@ func (self *Logger) snapshotAndDelete(oldrec *logfile, p chan *logfile, snapping
 	if err := os.Rename(snapshotfile.filename, filepath.Join(self.dir, fmt.Sprintf("%v.%v", snapshotfile.timestamp.UnixNano(), snapSuffix))); err != nil {
 		panic(err)
 	}
-	self.clearOlderThan(snapshotfile.timestamp)
+	self.ClearOlderThan(snapshotfile.timestamp)
 }
 
 func (self *Logger) swap(fi *os.FileInfo, err *error, rec *logfile) *logfile {
@@ -361,6 +816,7 @@ func (self *Logger) swap(fi *os.FileInfo, err *error, rec *logfile) *logfile {
 			panic(*err)
 		}
 		if (*fi).Size() > self.maxSize {
+			rec.sync()
 			rec.close()
 			started := make(chan *logfile)
 			atomic.StoreInt32(&self.snapping, 1)
@@ -388,11 +844,35 @@ func (self *Logger) record(p chan *logfile) {
 	var op Op
 	var fi os.FileInfo
 	var stop chan bool
+	var synced chan bool
+	var waiting []chan bool
+	var flush <-chan time.Time
 
 	rec := createLogfile(self.dir, self.suffix)
+	if self.suffix != logSuffix {
+		rec.compression = self.compression
+	}
 	rec.write()
 	p <- rec
-	defer rec.close()
+
+	encode := func(op Op) {
+		if err = rec.encode(op); err != nil {
+			panic(err)
+		}
+		if self.fsync.Ops > 0 && rec.unsynced >= self.fsync.Ops {
+			rec.sync()
+		} else if self.fsync.Interval > 0 && flush == nil {
+			flush = time.After(self.fsync.Interval)
+		}
+	}
+	stopRecording := func() {
+		rec.sync()
+		rec.close()
+		if !self.changeState(recording, stopped) {
+			panic(fmt.Errorf("%v unable to change state from recording to stopped", self))
+		}
+		stop <- true
+	}
 
 	for {
 		if self.maxSize != 0 {
@@ -401,28 +881,57 @@ func (self *Logger) record(p chan *logfile) {
 
 		select {
 		case op = <-self.ops:
-			if err = rec.encoder.Encode(op); err != nil {
-				panic(err)
+			encode(op)
+		case synced = <-self.syncs:
+			// Group commit: write all ops and collect all Syncs already waiting, and make them all durable using a single fsync.
+			waiting = append(waiting[:0], synced)
+		drain:
+			for {
+				select {
+				case op = <-self.ops:
+					encode(op)
+				case synced = <-self.syncs:
+					waiting = append(waiting, synced)
+				default:
+					break drain
+				}
 			}
-		case stop = <-self.stops:
-			if !self.changeState(recording, stopped) {
-				panic(fmt.Errorf("%v unable to change state from recording to stopped", self))
+			rec.sync()
+			for _, synced = range waiting {
+				synced <- true
 			}
-			stop <- true
+		case <-flush:
+			rec.sync()
+		case stop = <-self.stops:
+			stopRecording()
 			return
 		}
+		if rec.unsynced == 0 {
+			flush = nil
+		}
 		select {
 		case stop = <-self.stops:
-			if !self.changeState(recording, stopped) {
-				panic(fmt.Errorf("%v unable to change state from recording to stopped", self))
-			}
-			stop <- true
+			stopRecording()
 			return
 		default:
 		}
 	}
 }
 
+// Sync will return when all operations dumped into this Logger before it was called are durable, or at once if it never fsyncs.
+// Concurrent calls are served by a single fsync.
+func (self *Logger) Sync() {
+	if !self.hasState(recording) {
+		panic(fmt.Errorf("%v is not recording", self))
+	}
+	if self.fsync == (FsyncPolicy{}) {
+		return
+	}
+	synced := make(chan bool)
+	self.syncs <- synced
+	<-synced
+}
+
 // Dump will accept an operation if this Logger is recording, and dump it into a logfile.
 func (self *Logger) Dump(o Op) {
 	if !self.hasState(recording) {
@@ -430,3 +939,8 @@ func (self *Logger) Dump(o Op) {
 	}
 	self.ops <- o
 }
+
+// Policy returns the FsyncPolicy of this Logger.
+func (self *Logger) Policy() FsyncPolicy {
+	return self.fsync
+}
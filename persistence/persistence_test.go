@@ -1,11 +1,18 @@
 package persistence
 
 import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 type testmap struct {
@@ -83,6 +90,151 @@ func TestRecordPlay(t *testing.T) {
 	}
 }
 
+func recordOps(t *testing.T, dir string, n int) (ops []Op, filename string) {
+	os.RemoveAll(dir)
+	p := NewLogger(dir)
+	filename = (<-p.Record()).filename
+	for i := 0; i < n; i++ {
+		op := Op{
+			Key:       []byte(fmt.Sprint(i)),
+			Value:     []byte(fmt.Sprint(i)),
+			Timestamp: int64(i),
+			Put:       true,
+		}
+		p.Dump(op)
+		ops = append(ops, op)
+	}
+	p.Stop()
+	return
+}
+
+func TestPlayUntil(t *testing.T) {
+	ops, _ := recordOps(t, "test1", 10)
+	var ary []Op
+	if lost, skipped, err := NewLogger("test1").PlayUntil(4, false, operator(&ary)); lost != 0 || skipped != 5 || err != nil {
+		t.Errorf("wanted 0 lost and 5 skipped ops, but got %v and %v, %v", lost, skipped, err)
+	}
+	if !reflect.DeepEqual(ary, ops[:5]) {
+		t.Errorf("%+v should be %+v", ary, ops[:5])
+	}
+}
+
+func TestTornTail(t *testing.T) {
+	ops, filename := recordOps(t, "test1", 10)
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Truncate(filename, info.Size()-3); err != nil {
+		t.Fatal(err)
+	}
+	p := NewLogger("test1")
+	if verifications := p.Verify(); len(verifications) != 1 || verifications[0].Intact() || verifications[0].Ops != 9 || verifications[0].Lost != 1 {
+		t.Errorf("wanted 9 intact and 1 lost op, but got %+v", verifications)
+	}
+	var ary []Op
+	if lost := p.Play(operator(&ary)); lost != 1 {
+		t.Errorf("wanted 1 lost op, but got %v", lost)
+	}
+	if !reflect.DeepEqual(ary, ops[:9]) {
+		t.Errorf("%+v should be %+v", ary, ops[:9])
+	}
+	if verifications := p.Verify(); len(verifications) != 1 || !verifications[0].Intact() || verifications[0].Ops != 9 {
+		t.Errorf("wanted the torn tail to be truncated, but got %+v", verifications)
+	}
+}
+
+func TestCorruptRecord(t *testing.T) {
+	ops, filename := recordOps(t, "test1", 10)
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offset := int64(len(logHeader))
+	for i := 0; i < 5; i++ {
+		offset += frameHeaderSize + int64(binary.BigEndian.Uint32(b[offset:]))
+	}
+	// flip a byte in the payload of the 6th record
+	b[offset+frameHeaderSize+2] ^= 0xff
+	if err = ioutil.WriteFile(filename, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	p := NewLogger("test1")
+	if verification := p.Verify()[0]; verification.Intact() || verification.Ops != 5 || verification.Lost != 5 || verification.Valid != offset {
+		t.Errorf("wanted 5 intact and 5 lost ops, but got %+v", verification)
+	}
+	var ary []Op
+	p.Play(operator(&ary))
+	if !reflect.DeepEqual(ary, ops[:5]) {
+		t.Errorf("%+v should be %+v", ary, ops[:5])
+	}
+}
+
+func TestLegacyLogfile(t *testing.T) {
+	os.RemoveAll("test1")
+	p := NewLogger("test1")
+	file, err := os.Create(filepath.Join("test1", fmt.Sprintf("%v.%v", time.Now().UnixNano(), logSuffix)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := Op{
+		Key:   []byte("a"),
+		Value: []byte("1"),
+		Put:   true,
+	}
+	if err = gob.NewEncoder(file).Encode(op); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	var ary []Op
+	p.Play(operator(&ary))
+	if !reflect.DeepEqual(ary, []Op{op}) {
+		t.Errorf("%+v should be %+v", ary, []Op{op})
+	}
+	if verifications := p.Verify(); len(verifications) != 1 || !verifications[0].Intact() || !verifications[0].Legacy {
+		t.Errorf("wanted an intact legacy logfile, but got %+v", verifications)
+	}
+}
+
+func TestFsyncPolicy(t *testing.T) {
+	for s, policy := range map[string]FsyncPolicy{
+		"always": FsyncPolicy{Always: true},
+		"never":  FsyncPolicy{},
+		"10ms":   FsyncPolicy{Interval: 10 * time.Millisecond},
+		"100ops": FsyncPolicy{Ops: 100},
+	} {
+		if parsed, err := ParseFsyncPolicy(s); err != nil || parsed != policy || parsed.String() != s {
+			t.Errorf("wanted %#v to parse to %+v, but got %+v, %v", s, policy, parsed, err)
+		}
+	}
+	for _, s := range []string{"sometimes", "0ops", "-1s"} {
+		if _, err := ParseFsyncPolicy(s); err == nil {
+			t.Errorf("wanted %#v to be invalid", s)
+		}
+	}
+	for _, policy := range []FsyncPolicy{FsyncPolicy{Always: true}, FsyncPolicy{Interval: time.Millisecond}, FsyncPolicy{Ops: 3}, FsyncPolicy{}} {
+		os.RemoveAll("test1")
+		p := NewLogger("test1").Fsync(policy)
+		p.Record()
+		wg := new(sync.WaitGroup)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 10; j++ {
+					p.Dump(Op{Key: []byte(fmt.Sprint(i, j)), Value: []byte("v"), Put: true})
+					p.Sync()
+				}
+			}(i)
+		}
+		wg.Wait()
+		p.Stop()
+		if verifications := p.Verify(); len(verifications) != 1 || !verifications[0].Intact() || verifications[0].Ops != 100 {
+			t.Errorf("wanted 100 intact ops with %v, but got %+v", policy, verifications)
+		}
+	}
+}
+
 func TestSwap(t *testing.T) {
 	os.RemoveAll("test3")
 	tm := newTestmap()
@@ -129,3 +281,96 @@ func BenchmarkRecord(b *testing.B) {
 		p.Dump(op)
 	}
 }
+
+func TestOpCovers(t *testing.T) {
+	op := Op{
+		Min:    []byte("b"),
+		Max:    []byte("d"),
+		MinInc: true,
+		Range:  true,
+	}
+	for key, wanted := range map[string]bool{"a": false, "b": true, "c": true, "cc": true, "d": false, "e": false} {
+		if op.Covers([]byte(key)) != wanted {
+			t.Errorf("%+v should cover %v: %v", op, key, wanted)
+		}
+	}
+	op.Min = nil
+	if !op.Covers([]byte("a")) {
+		t.Errorf("%+v should cover a", op)
+	}
+}
+
+func TestCompressedSwap(t *testing.T) {
+	if compression, err := ParseCompression("gzip"); err != nil || compression != GzipCompression || compression.String() != "gzip" {
+		t.Errorf("wanted gzip to parse to GzipCompression, but got %v, %v", compression, err)
+	}
+	os.RemoveAll("test3")
+	tm := newTestmap()
+	tm.p.Compress(GzipCompression)
+	tm.record()
+	for i := 0; i < 1000; i++ {
+		tm.put(fmt.Sprint(i), fmt.Sprint(i))
+	}
+	for i := 0; i < 1000; i += 3 {
+		tm.del(fmt.Sprint(i))
+	}
+	tm.p.Stop()
+
+	compressed := 0
+	for _, verification := range tm.p.Verify() {
+		if !verification.Intact() {
+			t.Errorf("%v should be intact", verification)
+		}
+		if strings.HasSuffix(verification.Filename, snapSuffix) {
+			if verification.Compression != GzipCompression {
+				t.Errorf("%v should be compressed", verification)
+			}
+			compressed++
+		} else if verification.Compression != NoCompression {
+			t.Errorf("%v should not be compressed", verification)
+		}
+	}
+	if compressed != 1 {
+		t.Errorf("wanted one compressed snapshot, but got %v", compressed)
+	}
+
+	tm2 := newTestmap()
+	tm2.playback()
+	if !reflect.DeepEqual(tm.m, tm2.m) {
+		t.Errorf("%v should be equal to %v", tm2.m, tm.m)
+	}
+}
+
+func TestWriterRead(t *testing.T) {
+	for _, compression := range []Compression{NoCompression, GzipCompression} {
+		buffer := new(bytes.Buffer)
+		writer, err := NewWriter(buffer, compression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ops []Op
+		for i := 0; i < 10; i++ {
+			op := Op{
+				Key:       []byte(fmt.Sprint(i)),
+				Value:     []byte(fmt.Sprint(i)),
+				Timestamp: int64(i),
+				Put:       true,
+			}
+			if err = writer.Write(op); err != nil {
+				t.Fatal(err)
+			}
+			ops = append(ops, op)
+		}
+		if err = writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+		b := buffer.Bytes()
+		var ary []Op
+		if n, err := Read(bytes.NewReader(b), operator(&ary)); err != nil || n != 10 || !reflect.DeepEqual(ary, ops) {
+			t.Errorf("wanted %+v but got %v ops, %+v, %v", ops, n, ary, err)
+		}
+		if _, err := Read(bytes.NewReader(b[:len(b)-3]), func(op Op) {}); err == nil {
+			t.Errorf("wanted an error reading a torn %v stream", compression)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package radix
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/zond/god/common"
+)
+
+// Aggregate contains the number, sums, minimums and maximums of a set of 8 byte values, interpreted both as big endian int64 (like setop.EncodeInt64)
+// and as float64 (like setop.EncodeFloat64). Values of other lengths are ignored.
+type Aggregate struct {
+	Count    int
+	IntSum   int64
+	IntMin   int64
+	IntMax   int64
+	FloatSum float64
+	FloatMin float64
+	FloatMax float64
+}
+
+func (self *Aggregate) add(value []byte) {
+	if len(value) != 8 {
+		return
+	}
+	bits := binary.BigEndian.Uint64(value)
+	self.merge(&Aggregate{
+		Count:    1,
+		IntSum:   int64(bits),
+		IntMin:   int64(bits),
+		IntMax:   int64(bits),
+		FloatSum: math.Float64frombits(bits),
+		FloatMin: math.Float64frombits(bits),
+		FloatMax: math.Float64frombits(bits),
+	})
+}
+func (self *Aggregate) merge(other *Aggregate) {
+	if other.Count == 0 {
+		return
+	}
+	if self.Count == 0 {
+		*self = *other
+		return
+	}
+	self.Count += other.Count
+	self.IntSum += other.IntSum
+	self.FloatSum += other.FloatSum
+	if other.IntMin < self.IntMin {
+		self.IntMin = other.IntMin
+	}
+	if other.IntMax > self.IntMax {
+		self.IntMax = other.IntMax
+	}
+	if other.FloatMin < self.FloatMin {
+		self.FloatMin = other.FloatMin
+	}
+	if other.FloatMax > self.FloatMax {
+		self.FloatMax = other.FloatMax
+	}
+}
+
+// Result returns function (common.AggregateSum, common.AggregateMin, common.AggregateMax or common.AggregateAvg) of the values interpreted
+// using encoding (common.EncodingInt64 or common.EncodingFloat64), encoded the same way. Averages are always encoded as float64.
+// The min, max and avg of no values is nil.
+func (self Aggregate) Result(function, encoding string) (result []byte, err error) {
+	if encoding != common.EncodingInt64 && encoding != common.EncodingFloat64 {
+		return nil, fmt.Errorf("Unknown encoding %#v", encoding)
+	}
+	ints := encoding == common.EncodingInt64
+	var bits uint64
+	switch function {
+	case common.AggregateSum:
+		if ints {
+			bits = uint64(self.IntSum)
+		} else {
+			bits = math.Float64bits(self.FloatSum)
+		}
+	case common.AggregateMin:
+		if ints {
+			bits = uint64(self.IntMin)
+		} else {
+			bits = math.Float64bits(self.FloatMin)
+		}
+	case common.AggregateMax:
+		if ints {
+			bits = uint64(self.IntMax)
+		} else {
+			bits = math.Float64bits(self.FloatMax)
+		}
+	case common.AggregateAvg:
+		if ints {
+			bits = math.Float64bits(float64(self.IntSum) / float64(self.Count))
+		} else {
+			bits = math.Float64bits(self.FloatSum / float64(self.Count))
+		}
+	default:
+		return nil, fmt.Errorf("Unknown aggregate function %#v", function)
+	}
+	if self.Count == 0 && function != common.AggregateSum {
+		return
+	}
+	result = make([]byte, 8)
+	binary.BigEndian.PutUint64(result, bits)
+	return
+}
+
+// aggregateBetween will add the byte values between min and max, including each depending on mincmp and maxcmp, to result.
+// Like sizeBetween, it will use the cached aggregates of the children entirely inside the range, unless they contain values that have expired at now.
+func (self *node) aggregateBetween(prefix, min, max []Nibble, mincmp, maxcmp int, now int64, result *Aggregate) {
+	prefix = append(prefix, self.segment...)
+	if !self.empty && self.use&byteValue != 0 && !self.expiredValue(now) && (min == nil || nComp(prefix, min) > mincmp) && (max == nil || nComp(prefix, max) < maxcmp) {
+		result.add(self.byteValue)
+	}
+	for _, child := range self.children {
+		if child != nil {
+			childKey := make([]Nibble, len(prefix)+len(child.segment))
+			copy(childKey, prefix)
+			copy(childKey[len(prefix):], child.segment)
+			mmi := len(childKey)
+			if mmi > len(min) {
+				mmi = len(min)
+			}
+			mma := len(childKey)
+			if mma > len(max) {
+				mma = len(max)
+			}
+			mires := nComp(childKey[:mmi], min[:mmi])
+			mares := nComp(childKey[:mma], max[:mma])
+			if (min == nil || mires > -1) && (max == nil || mares < 1) {
+				if (min == nil || mires > 0) && (max == nil || mares < 0) && !child.hasExpired(now) {
+					result.merge(&child.aggregate)
+				} else {
+					child.aggregateBetween(prefix, min, max, mincmp, maxcmp, now, result)
+				}
+			}
+		}
+	}
+}
+
+// AggregateBetween returns the Aggregate of the byte values between min and max.
+func (self *Tree) AggregateBetween(min, max []byte, mininc, maxinc bool) (result Aggregate) {
+	if self == nil {
+		return
+	}
+	self.rLock()
+	defer self.lock.RUnlock()
+	mincmp, maxcmp := cmps(mininc, maxinc)
+	self.root.aggregateBetween(nil, Rip(min), Rip(max), mincmp, maxcmp, self.timer.ContinuousTime(), &result)
+	return
+}
+
+// SubAggregateBetween does AggregateBetween on the sub tree.
+func (self *Tree) SubAggregateBetween(key, min, max []byte, mininc, maxinc bool) (result Aggregate) {
+	self.rLock()
+	defer self.lock.RUnlock()
+	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
+		result = subTree.AggregateBetween(min, max, mininc, maxinc)
+	}
+	return
+}
@@ -0,0 +1,58 @@
+package radix
+
+import (
+	"strconv"
+
+	"github.com/zond/god/common"
+	"github.com/zond/god/persistence"
+)
+
+// evict will replace the lowest keys (or the highest, if the configuration has evict=highest) of the sub tree under key with tombstones,
+// until it is no bigger than the maxSize in its configuration.
+//
+// The tombstones get the timestamp of the operation that caused the eviction, so that all replicas evicting the same keys create identical tombstones,
+// unless the evicted value is newer than that, for example after being synchronized from a replica with a faster clock. Then the tombstone gets a
+// timestamp just after the value, so that it still replaces it everywhere.
+func (self *Tree) evict(key []byte, timestamp int64) {
+	ripped := Rip(key)
+	_, subTree, subTreeTimestamp, ex := self.root.get(ripped)
+	if ex&treeValue == 0 || subTree == nil {
+		return
+	}
+	conf, _ := subTree.Configuration()
+	max, err := strconv.Atoi(conf[maxSize])
+	if err != nil || max < 1 {
+		return
+	}
+	over := subTree.Size() - max
+	if over < 1 {
+		return
+	}
+	var subKeys [][]byte
+	var timestamps []int64
+	collector := func(subKey, value []byte, subTimestamp int64) bool {
+		tombstoneTimestamp := timestamp
+		if subTimestamp >= tombstoneTimestamp {
+			tombstoneTimestamp = subTimestamp + 1
+		}
+		subKeys = append(subKeys, subKey)
+		timestamps = append(timestamps, tombstoneTimestamp)
+		return len(subKeys) < over
+	}
+	if conf[evict] == highest {
+		subTree.ReverseEachBetween(nil, nil, true, true, collector)
+	} else {
+		subTree.EachBetween(nil, nil, true, true, collector)
+	}
+	for index, subKey := range subKeys {
+		if oldBytes, _, existed := subTree.FakeDel(subKey, timestamps[index]); existed {
+			self.index(key, subKey, subTree, oldBytes, nil, 0)
+			self.logChange(persistence.Op{
+				Key:       key,
+				SubKey:    subKey,
+				Timestamp: timestamps[index],
+			}, common.ChangeDel, oldBytes)
+		}
+	}
+	self.put(ripped, nil, subTree, treeValue, subTreeTimestamp)
+}
@@ -0,0 +1,186 @@
+package radix
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+const (
+	indexPrefix   = "index."
+	jsonPathStart = "jsonpath:$"
+)
+
+// IndexIterators iterate over secondary indexes, and see the indexed field, the key of the sub tree, and the sub key, value and timestamp of what they iterate over.
+// If they return false, the iteration will end.
+type IndexIterator func(field, key, subKey, value []byte, timestamp int64) (cont bool)
+
+// indexEscape will replace all 0 in b with 0, 1, to allow us to use 0, 0 as separator without changing the order of the escaped slices.
+func indexEscape(b []byte) (result []byte) {
+	result = make([]byte, 0, len(b))
+	for _, c := range b {
+		if c == 0 {
+			result = append(result, 0, 1)
+		} else {
+			result = append(result, c)
+		}
+	}
+	return
+}
+
+// indexKey returns the key in an index tree for field of the value under subKey in the sub tree under key.
+func indexKey(field, key, subKey []byte) (result []byte) {
+	result = append(indexEscape(field), 0, 0)
+	result = append(append(result, indexEscape(key)...), 0, 0)
+	return append(result, indexEscape(subKey)...)
+}
+
+// parseIndexKey returns the field, key and sub key encoded in an index tree key.
+func parseIndexKey(b []byte) (field, key, subKey []byte) {
+	parts := make([][]byte, 1, 3)
+	for i := 0; i < len(b); i++ {
+		if b[i] == 0 && i+1 < len(b) {
+			i++
+			if b[i] == 0 {
+				parts = append(parts, nil)
+				continue
+			}
+			parts[len(parts)-1] = append(parts[len(parts)-1], 0)
+		} else {
+			parts[len(parts)-1] = append(parts[len(parts)-1], b[i])
+		}
+	}
+	for len(parts) < 3 {
+		parts = append(parts, nil)
+	}
+	return parts[0], parts[1], parts[2]
+}
+
+// indexFields returns the index names defined in conf, mapped to the paths they index.
+func indexFields(conf map[string]string) (result map[string]string) {
+	for k, v := range conf {
+		if strings.HasPrefix(k, indexPrefix) && strings.HasPrefix(v, jsonPathStart) {
+			if result == nil {
+				result = make(map[string]string)
+			}
+			result[k[len(indexPrefix):]] = v[len(jsonPathStart):]
+		}
+	}
+	return
+}
+
+// extractField returns the field at path, of the form '.a.b', in the JSON encoded value.
+// Strings will be returned as they are, while other fields will be JSON encoded.
+func extractField(path string, value []byte) (field []byte, found bool) {
+	var doc interface{}
+	if err := json.Unmarshal(value, &doc); err != nil {
+		return
+	}
+	for _, part := range strings.Split(path, ".")[1:] {
+		m, ok := doc.(map[string]interface{})
+		if !ok {
+			return
+		}
+		if doc, ok = m[part]; !ok {
+			return
+		}
+	}
+	if s, ok := doc.(string); ok {
+		return []byte(s), true
+	}
+	field, err := json.Marshal(doc)
+	return field, err == nil
+}
+
+// index will replace the index entries for oldBytes with entries for newBytes, for the value under subKey in the sub tree under key,
+// for all indexes configured in subTree. A nil oldBytes or newBytes means that there was or will be no value.
+func (self *Tree) index(key, subKey []byte, subTree *Tree, oldBytes, newBytes []byte, timestamp int64) {
+	if subTree == nil {
+		return
+	}
+	conf, _ := subTree.Configuration()
+	for name, path := range indexFields(conf) {
+		if oldBytes != nil {
+			if field, found := extractField(path, oldBytes); found && self.indexes[name] != nil {
+				k := indexKey(field, key, subKey)
+				self.indexes[name].Del(k)
+				delete(self.indexed[string(key)], indexedKey(name, k))
+			}
+		}
+		if newBytes != nil {
+			if field, found := extractField(path, newBytes); found {
+				if self.indexes == nil {
+					self.indexes = make(map[string]*Tree)
+				}
+				if self.indexes[name] == nil {
+					self.indexes[name] = NewTreeTimer(self.timer)
+				}
+				k := indexKey(field, key, subKey)
+				self.indexes[name].Put(k, newBytes, timestamp)
+				if self.indexed == nil {
+					self.indexed = make(map[string]map[string][]byte)
+				}
+				if self.indexed[string(key)] == nil {
+					self.indexed[string(key)] = make(map[string][]byte)
+				}
+				self.indexed[string(key)][indexedKey(name, k)] = k
+			}
+		}
+	}
+}
+
+// indexedKey returns the key used in the indexed map for the entry k in the index name.
+func indexedKey(name string, k []byte) string {
+	return name + "\x00" + string(k)
+}
+
+// unindex will remove all index entries for the sub tree under key.
+func (self *Tree) unindex(key []byte) {
+	for entry, k := range self.indexed[string(key)] {
+		if indexTree := self.indexes[entry[:strings.Index(entry, "\x00")]]; indexTree != nil {
+			indexTree.Del(k)
+		}
+	}
+	delete(self.indexed, string(key))
+}
+
+// reindex will remove all index entries for the sub tree under key, and create new ones for the indexes configured in subTree.
+func (self *Tree) reindex(key []byte, subTree *Tree) {
+	self.unindex(key)
+	subTree.Each(func(subKey, value []byte, timestamp int64) bool {
+		self.index(key, subKey, subTree, nil, value, timestamp)
+		return true
+	})
+}
+
+// IndexEachBetween will iterate over the entries in the index name with fields between min and max, using f.
+// Entries whose values have changed without the index being updated, for example because they expired, will be skipped.
+func (self *Tree) IndexEachBetween(name string, min, max []byte, mininc, maxinc bool, f IndexIterator) {
+	self.rLock()
+	defer self.lock.RUnlock()
+	indexTree := self.indexes[name]
+	if indexTree == nil {
+		return
+	}
+	var minKey []byte
+	if min != nil {
+		minKey = indexEscape(min)
+	}
+	indexTree.EachBetween(minKey, nil, true, false, func(k, v []byte, timestamp int64) bool {
+		field, key, subKey := parseIndexKey(k)
+		if min != nil && !mininc && bytes.Compare(field, min) == 0 {
+			return true
+		}
+		if max != nil {
+			if cmp := bytes.Compare(field, max); cmp > 0 || (cmp == 0 && !maxinc) {
+				return false
+			}
+		}
+		if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
+			if current, _, existed := subTree.Get(subKey); existed && bytes.Compare(current, v) == 0 {
+				return f(field, key, subKey, v, timestamp)
+			}
+		}
+		return true
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"github.com/zond/god/murmur"
+	"github.com/zond/setop"
 	"strings"
 	"time"
 )
@@ -31,18 +32,22 @@ type nodeIterator func(key, byteValue []byte, treeValue *Tree, use int, timestam
 // node.use != 0 && node.empty => node is invalid?
 // node.empty && node.timestamp == 0 => node is invalid?
 type node struct {
-	segment   []Nibble // the bit of the key for this node that separates it from its parent
-	byteValue []byte
-	byteHash  []byte // cached hash of the byteValue
-	treeValue *Tree
-	timestamp int64  // only used in regard to byteValues. treeValues ignore them (since they have their own timestamps inside them). a timestamp of 0 will be considered REALLY empty
-	hash      []byte // cached hash of the entire node
-	children  []*node
-	empty     bool // this node only serves a structural purpose (ie remove it if it is no longer useful for that)
-	use       int  // the values in this node that are to be considered 'present'. even if this is a zero, do not remove the node if empty is false - it is still a tombstone.
-	treeSize  int  // size of the tree in this node and those of all of its children
-	byteSize  int  // number of byte values in this node and all of its children
-	realSize  int  // number of actual values, including tombstones
+	segment    []Nibble // the bit of the key for this node that separates it from its parent
+	byteValue  []byte
+	byteHash   []byte // cached hash of the byteValue
+	treeValue  *Tree
+	timestamp  int64  // only used in regard to byteValues. treeValues ignore them (since they have their own timestamps inside them). a timestamp of 0 will be considered REALLY empty
+	hash       []byte // cached hash of the entire node
+	children   []*node
+	empty      bool      // this node only serves a structural purpose (ie remove it if it is no longer useful for that)
+	use        int       // the values in this node that are to be considered 'present'. even if this is a zero, do not remove the node if empty is false - it is still a tombstone.
+	treeSize   int       // size of the tree in this node and those of all of its children
+	byteSize   int       // number of byte values in this node and all of its children
+	realSize   int       // number of actual values, including tombstones
+	aggregate  Aggregate // aggregate of the byte values in this node and all of its children
+	expires    int64     // if not 0, the byteValue will be replaced with a tombstone with this timestamp when the time passes it
+	nextExpiry int64     // the earliest expires of this node and all of its children and inner trees, or 0 if none of them expire
+	mergeable  bool      // the byteValue was written by a sketch operation, and a Sync will merge it with other mergeable values instead of replacing it
 }
 
 func newNode(segment []Nibble, byteValue []byte, treeValue *Tree, timestamp int64, empty bool, use int) *node {
@@ -66,6 +71,22 @@ func (self *node) setSegment(part []Nibble) {
 	self.segment = new_segment
 }
 
+// clone returns a copy of this node and its children, sharing their byteValues but with snapshots of their treeValues.
+func (self *node) clone() (result *node) {
+	if self == nil {
+		return
+	}
+	copied := *self
+	result = &copied
+	result.hash = append([]byte(nil), self.hash...)
+	result.treeValue = self.treeValue.snapshot()
+	result.children = make([]*node, len(self.children))
+	for index, child := range self.children {
+		result.children[index] = child.clone()
+	}
+	return
+}
+
 // rehash will recount the size of this node by summing the sizes of its own data and
 // the data of its children.
 //
@@ -77,18 +98,29 @@ func (self *node) rehash(key []Nibble, now int64) {
 	self.treeSize = 0
 	self.byteSize = 0
 	self.realSize = 0
+	self.aggregate = Aggregate{}
+	self.nextExpiry = 0
 	self.realSize += self.treeValue.RealSize()
 	if self.timestamp != 0 {
 		self.realSize++
 	}
 	if self.use&treeValue != 0 {
 		self.treeSize = self.treeValue.Size()
+		self.nextExpiry = self.treeValue.nextExpiry()
 	}
 	if self.use&byteValue != 0 {
 		self.byteSize = 1
+		self.aggregate.add(self.byteValue)
+		self.nextExpiry = minExpiry(self.nextExpiry, self.expires)
 	}
 	h := murmur.NewBytes(toBytes(key))
 	h.Write(self.byteHash)
+	if self.use&byteValue != 0 && self.expires != 0 {
+		h.Write(setop.EncodeInt64(self.expires))
+	}
+	if self.use&byteValue != 0 && self.mergeable {
+		h.Write([]byte{1})
+	}
 	h.Write(self.treeValue.Hash())
 
 	var child *node
@@ -100,12 +132,117 @@ func (self *node) rehash(key []Nibble, now int64) {
 			self.treeSize += child.treeSize
 			self.byteSize += child.byteSize
 			self.realSize += child.realSize
+			self.aggregate.merge(&child.aggregate)
+			self.nextExpiry = minExpiry(self.nextExpiry, child.nextExpiry)
 			h.Write(child.hash)
 		}
 	}
 	h.Extrude(self.hash)
 }
 
+// minExpiry returns the earliest of two expiry times, where 0 means never.
+func minExpiry(a, b int64) int64 {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// maxExpiry returns the latest of two expiry times, where 0 means never.
+func maxExpiry(a, b int64) int64 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	if b > a {
+		return b
+	}
+	return a
+}
+
+// expiryListener is a function getting the key, sub key, old value and tombstone timestamp of values replaced with tombstones when they expired.
+type expiryListener func(key, subKey, oldBytes []byte, timestamp int64)
+
+// hasExpired returns whether this node, its children or its inner trees contain values that have expired at now, but not yet been replaced with tombstones.
+func (self *node) hasExpired(now int64) bool {
+	return self != nil && self.nextExpiry != 0 && self.nextExpiry <= now
+}
+
+// expiredValue returns whether the byteValue of this node has expired at now, but not yet been replaced with a tombstone.
+func (self *node) expiredValue(now int64) bool {
+	return self.use&byteValue != 0 && self.expires != 0 && self.expires <= now
+}
+
+// expireValue will replace the byteValue of this node with a tombstone timestamped with the expiry time, if that time has passed.
+func (self *node) expireValue(now int64) (oldBytes []byte, expired bool) {
+	if self.expiredValue(now) {
+		oldBytes, expired = self.byteValue, true
+		self.byteValue, self.byteHash, self.use, self.timestamp, self.expires, self.mergeable = nil, murmur.HashBytes(nil), self.use&^byteValue, self.expires, 0, false
+	}
+	return
+}
+
+// expire will replace all expired byteValues in this node, its children and its inner trees with tombstones, and call f with each of them.
+// It will only descend into nodes that have expired values in them.
+func (self *node) expire(prefix []Nibble, now int64, f expiryListener) {
+	if !self.hasExpired(now) {
+		return
+	}
+	key := append(prefix, self.segment...)
+	if oldBytes, expired := self.expireValue(now); expired {
+		f(Stitch(key), nil, oldBytes, self.timestamp)
+	}
+	if self.use&treeValue != 0 {
+		stitched := Stitch(key)
+		self.treeValue.expireSub(now, func(subKey, _, oldBytes []byte, timestamp int64) {
+			f(stitched, subKey, oldBytes, timestamp)
+		})
+	}
+	for _, child := range self.children {
+		child.expire(key, now, f)
+	}
+	self.rehash(key, now)
+}
+
+// expiresAt will return the expiry time of the byteValue for the given key, if it exists.
+func (self *node) expiresAt(segment []Nibble) (expires int64) {
+	if n := self.valueAt(segment); n != nil {
+		expires = n.expires
+	}
+	return
+}
+
+// mergeableAt will return whether the byteValue for the given key exists and is mergeable.
+func (self *node) mergeableAt(segment []Nibble) bool {
+	n := self.valueAt(segment)
+	return n != nil && n.mergeable
+}
+
+// valueAt will return the node containing the byteValue for the given key, or nil if it doesn't exist.
+func (self *node) valueAt(segment []Nibble) (result *node) {
+	if self == nil {
+		return
+	}
+	beyond_self := false
+	beyond_segment := false
+	for i := 0; ; i++ {
+		beyond_self = i >= len(self.segment)
+		beyond_segment = i >= len(segment)
+		if beyond_self && beyond_segment {
+			if self.use&byteValue != 0 {
+				result = self
+			}
+			return
+		} else if beyond_segment {
+			return
+		} else if beyond_self {
+			return self.children[segment[i]].valueAt(segment[i:])
+		} else if segment[i] != self.segment[i] {
+			return
+		}
+	}
+	panic("Shouldn't happen")
+}
+
 // gc will garbage collect old tombstones.
 // If this node is an old tombstone, a replacement child will be returned.
 // If this node contains a tree which is empty and too old, it will be removed.
@@ -278,6 +415,23 @@ func (self *node) get(segment []Nibble) (byteValue []byte, treeValue *Tree, time
 	panic("Shouldn't happen")
 }
 
+// withPrefix will return the node containing all keys starting with segment, and the key of its parent (the prefix to iterate it with).
+func (self *node) withPrefix(prefix, segment []Nibble) (result *node, parentKey []Nibble) {
+	if self == nil {
+		return
+	}
+	for i := 0; ; i++ {
+		if i >= len(segment) {
+			return self, prefix
+		} else if i >= len(self.segment) {
+			return self.children[segment[i]].withPrefix(append(prefix, self.segment...), segment[i:])
+		} else if segment[i] != self.segment[i] {
+			return
+		}
+	}
+	panic("Shouldn't happen")
+}
+
 // del will return this node or a child replacement after removing the value type defined by use (byteValue and/or treeValue).
 func (self *node) del(prefix, segment []Nibble, use int, now int64) (result *node, oldBytes []byte, oldTree *Tree, timestamp int64, existed int) {
 	if self == nil {
@@ -293,7 +447,7 @@ func (self *node) del(prefix, segment []Nibble, use int, now int64) (result *nod
 				if self.use&use&byteValue != 0 {
 					oldBytes = self.byteValue
 					existed |= byteValue
-					self.byteValue, self.byteHash, self.use = nil, murmur.HashBytes(nil), self.use&^byteValue
+					self.byteValue, self.byteHash, self.use, self.expires, self.mergeable = nil, murmur.HashBytes(nil), self.use&^byteValue, 0, false
 				}
 				if self.use&use&treeValue != 0 {
 					oldTree = self.treeValue
@@ -313,7 +467,7 @@ func (self *node) del(prefix, segment []Nibble, use int, now int64) (result *nod
 				}
 				if n_children > 1 || self.segment == nil {
 					result, oldBytes, oldTree, timestamp, existed = self, self.byteValue, self.treeValue, self.timestamp, self.use
-					self.byteValue, self.byteHash, self.treeValue, self.empty, self.use, self.timestamp = nil, murmur.HashBytes(nil), nil, true, 0, 0
+					self.byteValue, self.byteHash, self.treeValue, self.empty, self.use, self.timestamp, self.expires, self.mergeable = nil, murmur.HashBytes(nil), nil, true, 0, 0, 0, false
 					self.rehash(append(prefix, segment...), now)
 				} else if n_children == 1 {
 					a_child.setSegment(append(self.segment, a_child.segment...))
@@ -380,7 +534,7 @@ func (self *node) insertHelp(prefix []Nibble, n *node, use int, now int64) (resu
 		if beyond_n && beyond_self {
 			result, oldBytes, oldTree, timestamp, existed = self, self.byteValue, self.treeValue, self.timestamp, self.use
 			if use&byteValue != 0 {
-				self.byteValue, self.byteHash = n.byteValue, n.byteHash
+				self.byteValue, self.byteHash, self.expires, self.mergeable = n.byteValue, n.byteHash, n.expires, n.mergeable
 				if n.use&byteValue == 0 {
 					self.use &^= byteValue
 				} else {
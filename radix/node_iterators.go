@@ -109,10 +109,11 @@ func (self *node) reverseEachBetween(prefix, min, max []Nibble, mincmp, maxcmp,
 }
 
 // sizeBetween will count values between min and max, including each depending on mincmp and maxcmp, counting values of types included in use (byteValue and/or treeValue)
-func (self *node) sizeBetween(prefix, min, max []Nibble, mincmp, maxcmp, use int) (result int) {
+// Unless use is 0, values that have expired at now are not counted, and the cached sizes of children containing such values are not used.
+func (self *node) sizeBetween(prefix, min, max []Nibble, mincmp, maxcmp, use int, now int64) (result int) {
 	prefix = append(prefix, self.segment...)
 	if !self.empty && (use == 0 || self.use&use != 0) && (min == nil || nComp(prefix, min) > mincmp) && (max == nil || nComp(prefix, max) < maxcmp) {
-		if use == 0 || self.use&use&byteValue != 0 {
+		if use == 0 || (self.use&use&byteValue != 0 && !self.expiredValue(now)) {
 			result++
 		}
 		if use == 0 || self.use&use&treeValue != 0 {
@@ -135,7 +136,7 @@ func (self *node) sizeBetween(prefix, min, max []Nibble, mincmp, maxcmp, use int
 			mires := nComp(childKey[:mmi], min[:mmi])
 			mares := nComp(childKey[:mma], max[:mma])
 			if (min == nil || mires > -1) && (max == nil || mares < 1) {
-				if (min == nil || mires > 0) && (max == nil || mares < 0) {
+				if (min == nil || mires > 0) && (max == nil || mares < 0) && (use == 0 || !child.hasExpired(now)) {
 					if use == 0 {
 						result += child.realSize
 					} else {
@@ -147,7 +148,7 @@ func (self *node) sizeBetween(prefix, min, max []Nibble, mincmp, maxcmp, use int
 						}
 					}
 				} else {
-					result += child.sizeBetween(prefix, min, max, mincmp, maxcmp, use)
+					result += child.sizeBetween(prefix, min, max, mincmp, maxcmp, use, now)
 				}
 			}
 		}
@@ -26,8 +26,14 @@ const (
 	parts    = 2
 	mirrored = "mirrored"
 	yes      = "yes"
+	maxSize  = "maxSize"
+	evict    = "evict"
+	highest  = "highest"
 )
 
+// backupPageSize is the number of values and sub trees BackupBetween collects while holding the lock.
+const backupPageSize = 128
+
 func nComp(a, b []Nibble) int {
 	return bytes.Compare(toBytes(a), toBytes(b))
 }
@@ -90,13 +96,15 @@ type Print struct {
 	TreeHash          []byte
 	TreeDataTimestamp int64
 	TreeSize          int
+	Expires           int64
+	Mergeable         bool
 }
 
 func (self *Print) coveredBy(other *Print) bool {
 	if self == nil {
 		return other == nil
 	}
-	return other != nil && (other.Timestamp > self.Timestamp || bytes.Compare(self.ByteHash, other.ByteHash) == 0)
+	return other != nil && (other.Timestamp > self.Timestamp || (bytes.Compare(self.ByteHash, other.ByteHash) == 0 && self.Expires == other.Expires && self.Mergeable == other.Mergeable))
 }
 func (self *Print) push(n *node) {
 	self.Key = append(self.Key, n.segment...)
@@ -111,6 +119,8 @@ func (self *Print) set(n *node) {
 	}
 	self.Empty = n.empty
 	self.Timestamp = n.timestamp
+	self.Expires = n.expires
+	self.Mergeable = n.mergeable
 	self.SubPrints = make([]SubPrint, len(n.children))
 	self.SubTree = n.treeValue != nil
 	for index, child := range n.children {
@@ -2,12 +2,16 @@ package radix
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"github.com/zond/god/common"
 	"github.com/zond/god/murmur"
+	"github.com/zond/god/persistence"
+	"io/ioutil"
 	"math/big"
 	"math/rand"
+	"os"
 	"reflect"
 	"runtime"
 	"testing"
@@ -204,6 +208,392 @@ func TestSubTree(t *testing.T) {
 	}
 }
 
+type testTimer struct {
+	now int64
+}
+
+func (self *testTimer) ContinuousTime() int64 {
+	return self.now
+}
+
+func TestTreeExpire(t *testing.T) {
+	timer := &testTimer{now: 10}
+	tree := NewTreeTimer(timer)
+	tree.PutExpires([]byte("a"), []byte("a"), 1, 20)
+	tree.Put([]byte("b"), []byte("b"), 1)
+	tree.SubPutExpires([]byte("c"), []byte("d"), []byte("e"), 1, 30)
+	assertSize(t, tree, 3)
+	assertExistance(t, tree, "a", "a")
+	timer.now = 25
+	if v, _, e := tree.Get([]byte("a")); v != nil || e {
+		t.Errorf("wrong result, wanted %v, %v got %v, %v", nil, false, v, e)
+	}
+	if v, ts, present := tree.GetTimestamp(Rip([]byte("a"))); v != nil || ts != 20 || present {
+		t.Errorf("wrong result, wanted %v, %v, %v got %v, %v, %v", nil, 20, false, v, ts, present)
+	}
+	assertSize(t, tree, 2)
+	if v, _, e := tree.SubGet([]byte("c"), []byte("d")); bytes.Compare(v, []byte("e")) != 0 || !e {
+		t.Errorf("wrong result, wanted %v, %v got %v, %v", []byte("e"), true, v, e)
+	}
+	timer.now = 35
+	if s := tree.SubSizeBetween([]byte("c"), nil, nil, true, true); s != 0 {
+		t.Errorf("wrong size, wanted %v got %v", 0, s)
+	}
+	assertSize(t, tree, 1)
+	deleted := NewTreeTimer(timer)
+	deleted.Put([]byte("a"), []byte("a"), 1)
+	deleted.FakeDel([]byte("a"), 20)
+	deleted.Put([]byte("b"), []byte("b"), 1)
+	deleted.SubPut([]byte("c"), []byte("d"), []byte("e"), 1)
+	deleted.SubFakeDel([]byte("c"), []byte("d"), 30)
+	if bytes.Compare(tree.Hash(), deleted.Hash()) != 0 {
+		t.Errorf("%v should have the same hash as %v", tree.Describe(), deleted.Describe())
+	}
+}
+
+func TestTreeExpireWhileReading(t *testing.T) {
+	timer := &testTimer{now: 10}
+	tree := NewTreeTimer(timer)
+	tree.PutExpires([]byte("a"), []byte("1"), 1, 20)
+	tree.Put([]byte("b"), []byte("2"), 1)
+	tree.SubPutExpires([]byte("c"), []byte("d"), []byte("33333333"), 1, 20)
+	tree.SubPut([]byte("c"), []byte("e"), []byte("44444444"), 1)
+	timer.now = 25
+	tree.lock.RLock()
+	defer tree.lock.RUnlock()
+	if v, ts, e := tree.Get([]byte("a")); v != nil || ts != 20 || e {
+		t.Errorf("wrong result, wanted %v, %v, %v got %v, %v, %v", nil, 20, false, v, ts, e)
+	}
+	if v, _, e := tree.SubGet([]byte("c"), []byte("d")); v != nil || e {
+		t.Errorf("wrong result, wanted %v, %v got %v, %v", nil, false, v, e)
+	}
+	var keys []string
+	tree.EachBetween(nil, nil, true, true, func(key, value []byte, timestamp int64) bool {
+		keys = append(keys, string(key))
+		return true
+	})
+	tree.SubEachBetween([]byte("c"), nil, nil, true, true, func(key, value []byte, timestamp int64) bool {
+		keys = append(keys, string(key))
+		return true
+	})
+	if !reflect.DeepEqual(keys, []string{"b", "e"}) {
+		t.Errorf("wanted %v but got %v", []string{"b", "e"}, keys)
+	}
+	if s := tree.Size(); s != 2 {
+		t.Errorf("wrong size, wanted %v got %v", 2, s)
+	}
+	if s := tree.SizeBetween(nil, nil, true, true); s != 2 {
+		t.Errorf("wrong size, wanted %v got %v", 2, s)
+	}
+	if s := tree.CountPrefix([]byte("a")); s != 0 {
+		t.Errorf("wrong count, wanted %v got %v", 0, s)
+	}
+	if s := tree.SubSize([]byte("c")); s != 1 {
+		t.Errorf("wrong size, wanted %v got %v", 1, s)
+	}
+	if a := tree.SubAggregateBetween([]byte("c"), nil, nil, true, true); a.Count != 1 {
+		t.Errorf("wrong aggregate, wanted %v values got %+v", 1, a)
+	}
+	if tree.root.nextExpiry != 20 {
+		t.Errorf("%v should not have been expired while read locked", tree.Describe())
+	}
+}
+
+func TestTreeExpireSubTree(t *testing.T) {
+	timer := &testTimer{now: 10}
+	tree := NewTreeTimer(timer)
+	var changes []common.Change
+	tree.SetChangeListener(func(change common.Change) {
+		changes = append(changes, change)
+	})
+	tree.PutExpires([]byte("a"), []byte("a"), 1, 20)
+	tree.SubPutExpires([]byte("c"), []byte("d"), []byte("e"), 1, 30)
+	changes = nil
+	timer.now = 35
+	if tree.SubCompareAndPut([]byte("c"), []byte("d"), []byte("x"), []byte("y"), 2, 0) {
+		t.Errorf("%v should not have accepted an expired expected value", tree.Describe())
+	}
+	deleted := NewTreeTimer(timer)
+	deleted.Put([]byte("a"), []byte("a"), 1)
+	deleted.FakeDel([]byte("a"), 20)
+	deleted.SubPut([]byte("c"), []byte("d"), []byte("e"), 1)
+	deleted.SubFakeDel([]byte("c"), []byte("d"), 30)
+	if bytes.Compare(tree.root.hash, deleted.root.hash) != 0 || tree.root.treeSize != deleted.root.treeSize {
+		t.Errorf("%v should have the same hash and size as %v", tree.Describe(), deleted.Describe())
+	}
+	wanted := []common.Change{
+		common.Change{Key: []byte("a"), Type: common.ChangeDel, OldValue: []byte("a"), Timestamp: 20},
+		common.Change{Key: []byte("c"), SubKey: []byte("d"), Sub: true, Type: common.ChangeDel, OldValue: []byte("e"), Timestamp: 30},
+	}
+	if !reflect.DeepEqual(changes, wanted) {
+		t.Errorf("wanted %+v but got %+v", wanted, changes)
+	}
+}
+
+func TestSyncExpires(t *testing.T) {
+	timer := &testTimer{now: 10}
+	tree1 := NewTreeTimer(timer)
+	tree2 := NewTreeTimer(timer)
+	tree1.PutExpires([]byte("a"), []byte("a"), 1, 20)
+	tree1.PutExpires([]byte("b"), []byte("b"), 1, 20)
+	tree2.Put([]byte("b"), []byte("b"), 1)
+	tree1.SubPutExpires([]byte("c"), []byte("d"), []byte("e"), 1, 20)
+	if bytes.Compare(tree1.Hash(), tree2.Hash()) == 0 {
+		t.Errorf("%v and %v should have different hashes", tree1.Describe(), tree2.Describe())
+	}
+	NewSync(tree1, tree2).Run()
+	if bytes.Compare(tree1.Hash(), tree2.Hash()) != 0 {
+		t.Errorf("%v and %v should have the same hash", tree1.Describe(), tree2.Describe())
+	}
+	timer.now = 25
+	assertSize(t, tree2, 0)
+	if v, ts, present := tree2.SubGetTimestamp(Rip([]byte("c")), Rip([]byte("d"))); v != nil || ts != 20 || present {
+		t.Errorf("wrong result, wanted %v, %v, %v got %v, %v, %v", nil, 20, false, v, ts, present)
+	}
+}
+
+func TestTreeCompareAndPut(t *testing.T) {
+	tree := NewTree()
+	if !tree.CompareAndPut([]byte("a"), nil, []byte("a"), 1, 0) {
+		t.Errorf("%v should have accepted a new value", tree.Describe())
+	}
+	if tree.CompareAndPut([]byte("a"), nil, []byte("b"), 2, 0) {
+		t.Errorf("%v should not have accepted a value without expected", tree.Describe())
+	}
+	if tree.CompareAndPut([]byte("a"), []byte("b"), []byte("b"), 2, 0) {
+		t.Errorf("%v should not have accepted a value with the wrong expected", tree.Describe())
+	}
+	if !tree.CompareAndPut([]byte("a"), []byte("a"), []byte("b"), 2, 0) {
+		t.Errorf("%v should have accepted a value with the right expected", tree.Describe())
+	}
+	assertExistance(t, tree, "a", "b")
+	if tree.CompareAndFakeDel([]byte("a"), []byte("a"), 3) {
+		t.Errorf("%v should not have deleted with the wrong expected", tree.Describe())
+	}
+	if !tree.CompareAndFakeDel([]byte("a"), []byte("b"), 3) {
+		t.Errorf("%v should have deleted with the right expected", tree.Describe())
+	}
+	assertNonExistance(t, tree, "a")
+	if !tree.SubCompareAndPut([]byte("b"), []byte("c"), nil, []byte("d"), 1, 0) {
+		t.Errorf("%v should have accepted a new value", tree.Describe())
+	}
+	if tree.SubCompareAndPut([]byte("b"), []byte("c"), []byte("e"), []byte("f"), 2, 0) {
+		t.Errorf("%v should not have accepted a value with the wrong expected", tree.Describe())
+	}
+	if !tree.SubCompareAndFakeDel([]byte("b"), []byte("c"), []byte("d"), 2) {
+		t.Errorf("%v should have deleted with the right expected", tree.Describe())
+	}
+	assertSize(t, tree, 0)
+}
+
+func TestTreeUpdate(t *testing.T) {
+	tree := NewTree()
+	appender := func(old []byte, existed bool) ([]byte, bool) {
+		return append(old, 'x'), true
+	}
+	if v, _, ok := tree.Update([]byte("a"), 1, appender); !ok || string(v) != "x" {
+		t.Errorf("%v should have updated to x, got %s", tree.Describe(), v)
+	}
+	if v, _, ok := tree.Update([]byte("a"), 2, appender); !ok || string(v) != "xx" {
+		t.Errorf("%v should have updated to xx, got %s", tree.Describe(), v)
+	}
+	if _, _, ok := tree.Update([]byte("a"), 3, func(old []byte, existed bool) ([]byte, bool) {
+		return nil, false
+	}); ok {
+		t.Errorf("%v should not have updated", tree.Describe())
+	}
+	assertExistance(t, tree, "a", "xx")
+	if v, _, ok := tree.SubUpdate([]byte("b"), []byte("c"), 1, appender); !ok || string(v) != "x" {
+		t.Errorf("%v should have updated to x, got %s", tree.Describe(), v)
+	}
+	if v, _, ok := tree.SubUpdate([]byte("b"), []byte("c"), 2, appender); !ok || string(v) != "xx" {
+		t.Errorf("%v should have updated to xx, got %s", tree.Describe(), v)
+	}
+	if v, ts, e := tree.SubGet([]byte("b"), []byte("c")); string(v) != "xx" || ts != 2 || !e {
+		t.Errorf("%v should contain xx, 2, true under b/c, got %s, %v, %v", tree.Describe(), v, ts, e)
+	}
+}
+
+func TestTreeSecondaryIndex(t *testing.T) {
+	tree := NewTree()
+	tree.SubPut([]byte("users"), []byte("1"), []byte(`{"email":"b@x"}`), 1)
+	tree.SubAddConfiguration([]byte("users"), 1, "index.email", "jsonpath:$.email")
+	tree.SubPut([]byte("users"), []byte("2"), []byte(`{"email":"a@x"}`), 1)
+	tree.SubPut([]byte("users"), []byte("3"), []byte(`{"name":"c"}`), 1)
+	tree.SubPut([]byte("users"), []byte("4"), []byte(`{"email":"c@x"}`), 1)
+	found := func(min, max []byte, mininc, maxinc bool) (result []string) {
+		tree.IndexEachBetween("email", min, max, mininc, maxinc, func(field, key, subKey, value []byte, timestamp int64) bool {
+			result = append(result, fmt.Sprintf("%s/%s/%s", field, key, subKey))
+			return true
+		})
+		return
+	}
+	if f := found(nil, nil, true, true); !reflect.DeepEqual(f, []string{"a@x/users/2", "b@x/users/1", "c@x/users/4"}) {
+		t.Errorf("%v should have indexed all emails, got %v", tree.Describe(), f)
+	}
+	if f := found([]byte("a@x"), []byte("c@x"), false, false); !reflect.DeepEqual(f, []string{"b@x/users/1"}) {
+		t.Errorf("%v should have found only b@x, got %v", tree.Describe(), f)
+	}
+	tree.SubPut([]byte("users"), []byte("1"), []byte(`{"email":"d@x"}`), 2)
+	tree.SubFakeDel([]byte("users"), []byte("2"), 2)
+	if f := found(nil, nil, true, true); !reflect.DeepEqual(f, []string{"c@x/users/4", "d@x/users/1"}) {
+		t.Errorf("%v should have reindexed changed emails, got %v", tree.Describe(), f)
+	}
+	tree.SubClear([]byte("users"), 3)
+	if f := found(nil, nil, true, true); len(f) != 0 {
+		t.Errorf("%v should have no indexed emails, got %v", tree.Describe(), f)
+	}
+}
+
+func TestTreePrefix(t *testing.T) {
+	tree := NewTree()
+	for _, k := range []string{"a", "ab", "abc", "abd", "b", "ba", "c"} {
+		tree.Put([]byte(k), []byte(k), 1)
+		tree.SubPut([]byte("sub"), []byte(k), []byte(k), 1)
+	}
+	for prefix, wanted := range map[string][]string{
+		"":   {"a", "ab", "abc", "abd", "b", "ba", "c"},
+		"a":  {"a", "ab", "abc", "abd"},
+		"ab": {"ab", "abc", "abd"},
+		"b":  {"b", "ba"},
+		"bb": nil,
+		"d":  nil,
+	} {
+		var found, subFound []string
+		tree.EachWithPrefix([]byte(prefix), func(key, value []byte, timestamp int64) bool {
+			found = append(found, string(key))
+			return true
+		})
+		tree.SubEachWithPrefix([]byte("sub"), []byte(prefix), func(key, value []byte, timestamp int64) bool {
+			subFound = append(subFound, string(key))
+			return true
+		})
+		if !reflect.DeepEqual(found, wanted) || !reflect.DeepEqual(subFound, wanted) {
+			t.Errorf("%v should have %v under %#v, got %v and %v", tree.Describe(), wanted, prefix, found, subFound)
+		}
+		if c, sc := tree.CountPrefix([]byte(prefix)), tree.SubCountPrefix([]byte("sub"), []byte(prefix)); c != len(wanted) || sc != len(wanted) {
+			t.Errorf("%v should have %v keys under %#v, got %v and %v", tree.Describe(), len(wanted), prefix, c, sc)
+		}
+	}
+	if deleted := tree.SubFakeDelPrefix([]byte("sub"), []byte("ab"), 2); deleted != 3 {
+		t.Errorf("%v should have deleted 3 keys, got %v", tree.Describe(), deleted)
+	}
+	if c := tree.SubCountPrefix([]byte("sub"), []byte("a")); c != 1 {
+		t.Errorf("%v should have 1 key under a, got %v", tree.Describe(), c)
+	}
+	if _, ts, e := tree.SubGet([]byte("sub"), []byte("abc")); e || ts != 2 {
+		t.Errorf("%v should have a tombstone at abc, got %v, %v", tree.Describe(), ts, e)
+	}
+}
+
+func TestTreeDelRange(t *testing.T) {
+	tree := NewTree()
+	for i := byte(1); i < 10; i++ {
+		tree.SubPut([]byte("sub"), []byte{i}, []byte{i}, 1)
+	}
+	if deleted := tree.SubFakeDelBetween([]byte("sub"), []byte{3}, []byte{6}, true, false, 2); deleted != 3 {
+		t.Errorf("%v should have deleted 3 keys, got %v", tree.Describe(), deleted)
+	}
+	if _, ts, e := tree.SubGet([]byte("sub"), []byte{4}); e || ts != 2 {
+		t.Errorf("%v should have a tombstone at 4, got %v, %v", tree.Describe(), ts, e)
+	}
+	min, max := 1, 2
+	if first, last, deleted := tree.SubFakeDelBetweenIndex([]byte("sub"), &min, &max, 3); deleted != 2 || bytes.Compare(first, []byte{2}) != 0 || bytes.Compare(last, []byte{6}) != 0 {
+		t.Errorf("%v should have deleted 2 keys between 2 and 6, got %v, %v, %v", tree.Describe(), deleted, first, last)
+	}
+	if size := tree.SubSize([]byte("sub")); size != 4 {
+		t.Errorf("%v should have 4 keys left, got %v", tree.Describe(), size)
+	}
+}
+
+func TestTreeCappedSubTree(t *testing.T) {
+	tree := NewTree()
+	for i := byte(1); i < 6; i++ {
+		tree.SubPut([]byte("sub"), []byte{i}, []byte{i}, int64(i))
+	}
+	tree.SubAddConfiguration([]byte("sub"), 6, "maxSize", "3")
+	if size := tree.SubSize([]byte("sub")); size != 3 {
+		t.Errorf("%v should have 3 keys, got %v", tree.Describe(), size)
+	}
+	if _, ts, e := tree.SubGet([]byte("sub"), []byte{2}); e || ts != 6 {
+		t.Errorf("%v should have a tombstone at 2, got %v, %v", tree.Describe(), ts, e)
+	}
+	tree.SubPut([]byte("sub"), []byte{6}, []byte{6}, 7)
+	if key, _, _, _ := tree.SubFirst([]byte("sub")); bytes.Compare(key, []byte{4}) != 0 {
+		t.Errorf("%v should start at 4, got %v", tree.Describe(), key)
+	}
+	tree.SubAddConfiguration([]byte("sub"), 8, "evict", "highest")
+	tree.SubPut([]byte("sub"), []byte{1}, []byte{1}, 9)
+	if key, _, _, _ := tree.SubLast([]byte("sub")); bytes.Compare(key, []byte{5}) != 0 {
+		t.Errorf("%v should end at 5, got %v", tree.Describe(), key)
+	}
+	if size := tree.SubSize([]byte("sub")); size != 3 {
+		t.Errorf("%v should have 3 keys, got %v", tree.Describe(), size)
+	}
+	tree.SubPutTimestamp(Rip([]byte("sub")), Rip([]byte{7}), []byte{7}, true, 0, 10, 0)
+	if size := tree.SubSize([]byte("sub")); size != 3 {
+		t.Errorf("%v should have 3 keys after a synchronized put, got %v", tree.Describe(), size)
+	}
+	tree.SubPutTimestamp(Rip([]byte("missing")), Rip([]byte{1}), nil, false, 0, 10, 0)
+	if _, timestamp, present := tree.SubGetTimestamp(Rip([]byte("missing")), Rip([]byte{1})); present || timestamp != 10 {
+		t.Errorf("%v should have a tombstone after a synchronized delete in a missing sub tree, got %v, %v", tree.Describe(), timestamp, present)
+	}
+	tree.SubAddConfiguration([]byte("sub"), 11, "maxSize", "4")
+	tree.SubPutTimestamp(Rip([]byte("sub")), Rip([]byte{9}), []byte{9}, true, 0, 100, 0)
+	tree.SubAddConfiguration([]byte("sub"), 12, "maxSize", "3")
+	tree.SubPut([]byte("sub"), []byte{0}, []byte{0}, 13)
+	if _, timestamp, present := tree.SubGetTimestamp(Rip([]byte("sub")), Rip([]byte{9})); present || timestamp != 101 {
+		t.Errorf("%v should have evicted a value newer than the eviction with a newer tombstone, got %v, %v", tree.Describe(), timestamp, present)
+	}
+}
+
+func TestTreeAggregate(t *testing.T) {
+	tree := NewTree()
+	var keys [][]byte
+	var values []int64
+	for i := 0; i < 500; i++ {
+		key := murmur.HashString(fmt.Sprint(i))
+		value := make([]byte, 8)
+		binary.BigEndian.PutUint64(value, uint64(rand.Int63n(1000)-500))
+		tree.SubPut([]byte("sub"), key, value, 1)
+		keys = append(keys, key)
+		values = append(values, int64(binary.BigEndian.Uint64(value)))
+	}
+	tree.SubPut([]byte("sub"), []byte("short"), []byte("x"), 1)
+	for i := 0; i < 100; i += 10 {
+		tree.SubFakeDel([]byte("sub"), keys[i], 2)
+	}
+	for n := 0; n < 20; n++ {
+		min, max := keys[rand.Intn(len(keys))], keys[rand.Intn(len(keys))]
+		if bytes.Compare(min, max) > 0 {
+			min, max = max, min
+		}
+		var wanted Aggregate
+		for i, key := range keys {
+			if i >= 100 || i%10 != 0 {
+				if bytes.Compare(key, min) > -1 && bytes.Compare(key, max) < 0 {
+					value := make([]byte, 8)
+					binary.BigEndian.PutUint64(value, uint64(values[i]))
+					wanted.add(value)
+				}
+			}
+		}
+		if found := tree.SubAggregateBetween([]byte("sub"), min, max, true, false); found.Count != wanted.Count || found.IntSum != wanted.IntSum || found.IntMin != wanted.IntMin || found.IntMax != wanted.IntMax {
+			t.Errorf("wanted %+v between %v and %v but got %+v", wanted, min, max, found)
+		}
+	}
+	all := tree.SubAggregateBetween([]byte("sub"), nil, nil, true, true)
+	if result, err := all.Result(common.AggregateSum, common.EncodingInt64); err != nil || int64(binary.BigEndian.Uint64(result)) != all.IntSum {
+		t.Errorf("wanted %v but got %v, %v", all.IntSum, result, err)
+	}
+	if _, err := all.Result("median", common.EncodingInt64); err == nil {
+		t.Errorf("wanted an error for an unknown function")
+	}
+	if result, err := tree.SubAggregateBetween([]byte("missing"), nil, nil, true, true).Result(common.AggregateMax, common.EncodingFloat64); err != nil || result != nil {
+		t.Errorf("wanted nil but got %v, %v", result, err)
+	}
+}
+
 func TestSyncSubTreeVersions(t *testing.T) {
 	tree1 := NewTree()
 	tree3 := NewTree()
@@ -460,6 +850,47 @@ func TestSyncConf(t *testing.T) {
 	}
 }
 
+func TestSyncMergeable(t *testing.T) {
+	tree1 := NewTree()
+	tree2 := NewTree()
+	hll1, hll2 := common.NewHyperLogLog(), common.NewHyperLogLog()
+	for i := 0; i < 1000; i++ {
+		hll1.Add([]byte(fmt.Sprint(i)))
+		hll2.Add([]byte(fmt.Sprint(i + 500)))
+	}
+	tree1.PutMergeable([]byte("hll"), hll1, 2, 0)
+	tree2.PutMergeable([]byte("hll"), hll2, 1, 0)
+	tree1.Put([]byte("plain"), []byte("newer"), 2)
+	tree2.Put([]byte("plain"), []byte("older"), 1)
+	tree1.Put([]byte("plainhll"), hll1, 2)
+	tree2.Put([]byte("plainhll"), hll2, 1)
+	NewSync(tree2, tree1).Run()
+	NewSync(tree1, tree2).Run()
+	if bytes.Compare(tree1.Hash(), tree2.Hash()) != 0 {
+		t.Errorf("%v and %v have hashes\n%v\n%v\nand they should be equal!", tree1.Describe(), tree2.Describe(), tree1.Hash(), tree2.Hash())
+	}
+	value, _, _ := tree2.Get([]byte("hll"))
+	if hll, err := common.ParseHyperLogLog(value); err != nil {
+		t.Errorf("%v", err)
+	} else if count := hll.Count(); count < 1450 || count > 1550 {
+		t.Errorf("wanted the merged HyperLogLog to count about 1500, but got %v", count)
+	}
+	if value, _, _ := tree1.Get([]byte("plain")); string(value) != "newer" {
+		t.Errorf("wanted the newest plain value, but got %v", string(value))
+	}
+	if value, _, _ := tree2.Get([]byte("plainhll")); bytes.Compare(value, hll1) != 0 {
+		t.Errorf("wanted the newest HyperLogLog put without PFAdd, but got %v", value)
+	}
+	if s := NewSync(tree2, tree1).Run(); s.PutCount() != 0 {
+		t.Errorf("wanted no puts when synchronizing converged trees, but got %v", s.PutCount())
+	}
+	tree1.Put([]byte("hll"), hll1, 10)
+	NewSync(tree1, tree2).Run()
+	if value, _, _, mergeable, _ := tree2.GetMergeable([]byte("hll")); mergeable || bytes.Compare(value, hll1) != 0 {
+		t.Errorf("wanted the newer plain put to replace the merged HyperLogLog, but got %v, %v", value, mergeable)
+	}
+}
+
 func TestTreeHash(t *testing.T) {
 	tree1 := NewTree()
 	var keys [][]byte
@@ -1183,3 +1614,190 @@ func BenchmarkTreeMirrorPut100000(b *testing.B) {
 func BenchmarkTreeMirrorPut1000000(b *testing.B) {
 	benchTree(b, 1000000, true, false)
 }
+
+func TestBackupBetween(t *testing.T) {
+	tree := NewTree()
+	tree.AddConfiguration(1, "mirrored", "yes")
+	tree.Put([]byte("a"), []byte("1"), 1)
+	tree.Put([]byte("b"), []byte("2"), 1)
+	tree.PutExpires([]byte("c"), []byte("3"), 1, time.Now().Add(time.Hour).UnixNano())
+	tree.Put([]byte("d"), []byte("4"), 1)
+	tree.FakeDel([]byte("d"), 2)
+	tree.SubAddConfiguration([]byte("e"), 1, "mirrored", "yes")
+	tree.SubPut([]byte("e"), []byte("x"), []byte("5"), 1)
+	restored := NewTree()
+	tree.BackupBetween([]byte("c"), []byte("b"), true, false, restored.Apply)
+	if conf, _ := restored.Configuration(); conf["mirrored"] != "yes" {
+		t.Errorf("wanted the configuration to be restored, but got %v", conf)
+	}
+	if _, _, existed := restored.Get([]byte("b")); existed {
+		t.Errorf("b is outside the range and should not be restored")
+	}
+	if _, _, existed := restored.Get([]byte("d")); existed {
+		t.Errorf("the tombstone of d should not be restored")
+	}
+	for key, value := range map[string]string{"a": "1", "c": "3"} {
+		if found, timestamp, _ := restored.Get([]byte(key)); string(found) != value || timestamp != 1 {
+			t.Errorf("wanted %v to be restored as %v at 1, but got %v at %v", key, value, string(found), timestamp)
+		}
+	}
+	if restored.root.expiresAt(Rip([]byte("c"))) == 0 {
+		t.Errorf("wanted the expiry of c to be restored")
+	}
+	if value, _, _ := restored.SubGet([]byte("e"), []byte("x")); string(value) != "5" {
+		t.Errorf("wanted the sub tree to be restored, but got %v", string(value))
+	}
+	if conf, _ := restored.SubConfiguration([]byte("e")); conf["mirrored"] != "yes" {
+		t.Errorf("wanted the sub tree configuration to be restored, but got %v", conf)
+	}
+}
+
+func TestBackupBetweenPages(t *testing.T) {
+	tree := NewTree()
+	for i := 0; i < backupPageSize*3; i++ {
+		tree.Put([]byte(fmt.Sprintf("%04d", i)), []byte("v"), 1)
+	}
+	restored := NewTree()
+	tree.BackupBetween([]byte(fmt.Sprintf("%04d", backupPageSize*2)), []byte(fmt.Sprintf("%04d", backupPageSize)), true, false, restored.Apply)
+	if size := restored.Size(); size != backupPageSize*2 {
+		t.Errorf("wanted %v values to be restored, but got %v", backupPageSize*2, size)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	tree := NewTree()
+	for i := 0; i < 100; i++ {
+		tree.Put([]byte(fmt.Sprintf("%04d", i)), []byte("old"), 1)
+	}
+	tree.SubPut([]byte("sub"), []byte("x"), []byte("old"), 1)
+	tree.AddConfiguration(1, "a", "b")
+	snapshot := tree.Snapshot()
+	wanted := tree.Describe()
+	hash := tree.Hash()
+	for i := 0; i < 100; i += 2 {
+		tree.Put([]byte(fmt.Sprintf("%04d", i)), []byte("new"), 2)
+	}
+	tree.Del([]byte("0001"))
+	tree.Put([]byte("new"), []byte("new"), 2)
+	tree.SubPut([]byte("sub"), []byte("x"), []byte("new"), 2)
+	tree.SubPut([]byte("sub"), []byte("y"), []byte("new"), 2)
+	tree.AddConfiguration(2, "a", "c")
+	if described := snapshot.Describe(); described != wanted {
+		t.Errorf("wanted the snapshot to stay\n%v\nbut got\n%v", wanted, described)
+	}
+	if bytes.Compare(snapshot.Hash(), hash) != 0 {
+		t.Errorf("wanted the snapshot hash to stay %v, but got %v", hash, snapshot.Hash())
+	}
+	restored := NewTree()
+	snapshot.BackupBetween(nil, nil, true, false, restored.Apply)
+	if value, _, _ := restored.Get([]byte("0000")); string(value) != "old" {
+		t.Errorf("wanted the backup of the snapshot to have the old value, but got %v", string(value))
+	}
+	if value, _, _ := restored.SubGet([]byte("sub"), []byte("x")); string(value) != "old" {
+		t.Errorf("wanted the backup of the snapshot to have the old sub value, but got %v", string(value))
+	}
+	if _, _, existed := restored.Get([]byte("new")); existed {
+		t.Errorf("wanted the backup of the snapshot not to have values put after it")
+	}
+	if conf, _ := restored.Configuration(); conf["a"] != "b" {
+		t.Errorf("wanted the backup of the snapshot to have the old configuration, but got %v", conf)
+	}
+}
+
+func TestApplyNewer(t *testing.T) {
+	tree := NewTree()
+	tree.Put([]byte("a"), []byte("new"), 2)
+	tree.Put([]byte("b"), []byte("deleted"), 1)
+	tree.FakeDel([]byte("b"), 3)
+	tree.SubPut([]byte("c"), []byte("x"), []byte("new"), 2)
+	tree.SubAddConfiguration([]byte("c"), 2, "maxSize", "10")
+	for _, op := range []persistence.Op{
+		{Key: []byte("a"), Value: []byte("old"), Timestamp: 1, Put: true},
+		{Key: []byte("b"), Value: []byte("old"), Timestamp: 2, Put: true},
+		{Key: []byte("c"), SubKey: []byte("x"), Value: []byte("old"), Timestamp: 1, Put: true},
+		{Key: []byte("c"), SubKey: []byte("y"), Value: []byte("restored"), Timestamp: 1, Put: true},
+		{Key: []byte("c"), Configuration: map[string]string{"maxSize": "1"}, Timestamp: 1},
+		{Key: []byte("d"), Value: []byte("restored"), Timestamp: 1, Put: true},
+	} {
+		tree.ApplyNewer(op)
+	}
+	if value, _, _ := tree.Get([]byte("a")); string(value) != "new" {
+		t.Errorf("a should not be replaced by an older value, but got %v", string(value))
+	}
+	if _, _, existed := tree.Get([]byte("b")); existed {
+		t.Errorf("b should not be replaced by a value older than its tombstone")
+	}
+	if value, _, _ := tree.SubGet([]byte("c"), []byte("x")); string(value) != "new" {
+		t.Errorf("c/x should not be replaced by an older value, but got %v", string(value))
+	}
+	if value, _, _ := tree.SubGet([]byte("c"), []byte("y")); string(value) != "restored" {
+		t.Errorf("c/y should be restored, but got %v", string(value))
+	}
+	if conf, _ := tree.SubConfiguration([]byte("c")); conf["maxSize"] != "10" {
+		t.Errorf("the configuration of c should not be replaced by an older one, but got %v", conf)
+	}
+	if value, _, _ := tree.Get([]byte("d")); string(value) != "restored" {
+		t.Errorf("d should be restored, but got %v", string(value))
+	}
+}
+
+func TestRestoreUntil(t *testing.T) {
+	os.RemoveAll("restorelogs")
+	defer os.RemoveAll("restorelogs")
+	tree := NewTree().Log("restorelogs")
+	tree.Put([]byte("a"), []byte("1"), 1)
+	tree.SubPut([]byte("b"), []byte("c"), []byte("2"), 2)
+	tree.FakeDel([]byte("a"), 3)
+	tree.SubFakeDel([]byte("b"), []byte("c"), 4)
+	tree.logger.Stop()
+	restored := NewTree().Log("restorelogs")
+	moved, err := restored.RestoreUntil(2, false)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	restored.logger.Stop()
+	if files, err := ioutil.ReadDir(moved); err != nil || len(files) == 0 {
+		t.Errorf("wanted the replaced logfiles to be moved to %v, but got %v, %v", moved, files, err)
+	}
+	// snapshotting the restored state must not bring back the replaced logfiles
+	<-restored.logger.Limit(1).Record()
+	restored.logger.Stop()
+	// the tree restored from the rewritten logs should have forgotten the operations after 2 as well
+	for _, tree := range []*Tree{restored, NewTree().Log("restorelogs").Restore()} {
+		if value, _, _ := tree.Get([]byte("a")); string(value) != "1" {
+			t.Errorf("wanted a to be 1 at 2, but got %v", string(value))
+		}
+		if value, _, _ := tree.SubGet([]byte("b"), []byte("c")); string(value) != "2" {
+			t.Errorf("wanted c in b to be 2 at 2, but got %v", string(value))
+		}
+	}
+}
+
+func TestRestoreUntilSnapshot(t *testing.T) {
+	os.RemoveAll("restorelogs")
+	defer os.RemoveAll("restorelogs")
+	tree := NewTree().LogWith(persistence.NewLogger("restorelogs").Limit(1))
+	for i := int64(1); i < 5; i++ {
+		tree.Put([]byte("a"), []byte(fmt.Sprint(i)), i)
+	}
+	tree.logger.Stop()
+	// a is only 4 in the snapshot, so the state at 2 can't be restored
+	restored := NewTree().Log("restorelogs")
+	if _, err := restored.RestoreUntil(2, false); err == nil {
+		t.Errorf("wanted an error when restoring to before the latest snapshot")
+	}
+	if size := restored.Size(); size != 0 {
+		t.Errorf("wanted nothing to be restored, but got %v", restored.Describe())
+	}
+	restored.logger.Stop()
+	restored = NewTree().Log("restorelogs")
+	if _, err := restored.RestoreUntil(time.Now().UnixNano(), false); err != nil {
+		t.Errorf("wanted restoring to after the latest snapshot to work, but got %v", err)
+	}
+	restored.logger.Stop()
+	restored = NewTree().Log("restorelogs")
+	if _, err := restored.RestoreUntil(2, true); err != nil {
+		t.Errorf("wanted a forced restore to work, but got %v", err)
+	}
+	restored.logger.Stop()
+}
@@ -23,8 +23,8 @@ func (self *subTreeWrapper) Finger(subKey []Nibble) *Print {
 func (self *subTreeWrapper) GetTimestamp(subKey []Nibble) (byteValue []byte, version int64, present bool) {
 	return self.parentTree.SubGetTimestamp(self.key, subKey)
 }
-func (self *subTreeWrapper) PutTimestamp(subKey []Nibble, byteValue []byte, present bool, expected, version int64) bool {
-	return self.parentTree.SubPutTimestamp(self.key, subKey, byteValue, present, expected, version)
+func (self *subTreeWrapper) PutTimestamp(subKey []Nibble, byteValue []byte, present bool, expected, version, expires int64, mergeable bool) bool {
+	return self.parentTree.SubPutTimestamp(self.key, subKey, byteValue, present, expected, version, expires)
 }
 func (self *subTreeWrapper) DelTimestamp(subKey []Nibble, expected int64) bool {
 	return self.parentTree.SubDelTimestamp(self.key, subKey, expected)
@@ -41,7 +41,7 @@ func (self *subTreeWrapper) SubFinger(key, subKey []Nibble) (result *Print) {
 func (self *subTreeWrapper) SubGetTimestamp(key, subKey []Nibble) (byteValue []byte, version int64, present bool) {
 	panic(subTreeError)
 }
-func (self *subTreeWrapper) SubPutTimestamp(key, subKey []Nibble, byteValue []byte, present bool, subExpected, subTimestamp int64) bool {
+func (self *subTreeWrapper) SubPutTimestamp(key, subKey []Nibble, byteValue []byte, present bool, subExpected, subTimestamp, subExpires int64) bool {
 	panic(subTreeError)
 }
 func (self *subTreeWrapper) SubDelTimestamp(key, subKey []Nibble, subExpected int64) bool {
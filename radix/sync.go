@@ -18,7 +18,7 @@ type HashTree interface {
 
 	Finger(key []Nibble) *Print
 	GetTimestamp(key []Nibble) (byteValue []byte, timestamp int64, present bool)
-	PutTimestamp(key []Nibble, byteValue []byte, present bool, expected, timestamp int64) bool
+	PutTimestamp(key []Nibble, byteValue []byte, present bool, expected, timestamp, expires int64, mergeable bool) bool
 	DelTimestamp(key []Nibble, expected int64) bool
 
 	SubConfiguration(key []byte) (conf map[string]string, timestamp int64)
@@ -26,7 +26,7 @@ type HashTree interface {
 
 	SubFinger(key, subKey []Nibble) (result *Print)
 	SubGetTimestamp(key, subKey []Nibble) (byteValue []byte, timestamp int64, present bool)
-	SubPutTimestamp(key, subKey []Nibble, byteValue []byte, present bool, subExpected, subTimestamp int64) bool
+	SubPutTimestamp(key, subKey []Nibble, byteValue []byte, present bool, subExpected, subTimestamp, subExpires int64) bool
 	SubDelTimestamp(key, subKey []Nibble, subExpected int64) bool
 	SubClearTimestamp(key []Nibble, expected, timestamp int64) (deleted int)
 	SubKillTimestamp(key []Nibble, expected int64) (deleted int)
@@ -122,6 +122,34 @@ func (self *Sync) withinLimits(key []Nibble) bool {
 	return common.BetweenIE(toBytes(key), toBytes(self.from), toBytes(self.to))
 }
 
+// merge will check if the source and destination contain different mergeable values that can be merged, like two common.HyperLogLogs,
+// put the merged value in the destination unless it already is there, and return whether they could be merged.
+// The merged value gets a timestamp newer than both values, so that the source will get it when the destination is synchronized back to it.
+func (self *Sync) merge(sourcePrint, destinationPrint *Print) bool {
+	if destinationPrint == nil || destinationPrint.Timestamp == 0 || destinationPrint.Empty || !sourcePrint.Mergeable || !destinationPrint.Mergeable || bytes.Compare(sourcePrint.ByteHash, destinationPrint.ByteHash) == 0 {
+		return false
+	}
+	sourceValue, sourceTimestamp, sourcePresent := self.source.GetTimestamp(sourcePrint.Key)
+	destinationValue, destinationTimestamp, destinationPresent := self.destination.GetTimestamp(sourcePrint.Key)
+	if !sourcePresent || !destinationPresent {
+		return false
+	}
+	merged, ok := common.MergeValues(sourceValue, destinationValue)
+	if !ok {
+		return false
+	}
+	if bytes.Compare(merged, destinationValue) != 0 {
+		timestamp := sourceTimestamp
+		if destinationTimestamp >= timestamp {
+			timestamp = destinationTimestamp + 1
+		}
+		if self.destination.PutTimestamp(sourcePrint.Key, merged, true, destinationTimestamp, timestamp, maxExpiry(sourcePrint.Expires, destinationPrint.Expires), true) {
+			self.putCount++
+		}
+	}
+	return true
+}
+
 // synchronize will recursively run the actual synchronization.
 func (self *Sync) synchronize(sourcePrint, destinationPrint *Print) {
 	// If there is a source key
@@ -158,12 +186,12 @@ func (self *Sync) synchronize(sourcePrint, destinationPrint *Print) {
 			}
 			// If the source has a byte value.
 			if sourcePrint.Timestamp > 0 {
-				// If the destination print is not covered by the source print (it is not equal and it is older)
-				if !sourcePrint.coveredBy(destinationPrint) {
+				// If the values are not mergeable, and the destination print is not covered by the source print (it is not equal and it is older)
+				if !self.merge(sourcePrint, destinationPrint) && !sourcePrint.coveredBy(destinationPrint) {
 					// If the source still contains the same timestamp
 					if value, timestamp, present := self.source.GetTimestamp(sourcePrint.Key); timestamp == sourcePrint.timestamp() {
 						// Put the found data in the destination
-						if self.destination.PutTimestamp(sourcePrint.Key, value, present, destinationPrint.timestamp(), sourcePrint.timestamp()) {
+						if self.destination.PutTimestamp(sourcePrint.Key, value, present, destinationPrint.timestamp(), sourcePrint.timestamp(), sourcePrint.Expires, sourcePrint.Mergeable) {
 							self.putCount++
 						}
 					}
@@ -7,8 +7,10 @@ import (
 	"github.com/zond/god/common"
 	"github.com/zond/god/murmur"
 	"github.com/zond/god/persistence"
+	"log"
 	"math/big"
 	"sync/atomic"
+	"time"
 )
 
 // NaiveTimer is a Timer that just provides the current system time.
@@ -20,6 +22,9 @@ func (self NaiveTimer) ContinuousTime() int64 {
 	return atomic.AddInt64(&faketime, 1)
 }
 
+// ChangeListener is a function listening to changes of values and sub trees in a Tree. It is called while the Tree is locked.
+type ChangeListener func(change common.Change)
+
 // TreeIterators iterate over trees, and see the key, value and timestamp of what they iterate over.
 // If they return false, the iteration will end.
 type TreeIterator func(key, value []byte, timestamp int64) (cont bool)
@@ -97,6 +102,12 @@ func newNodeIndexIterator(f TreeIndexIterator) nodeIndexIterator {
 // A Tree can be mirrored, which means that it contains another Tree where the keys are the values of the master Tree, and the values are the keys of the master Tree.
 //
 // A Tree is configured to be mirrored or not by using AddConfiguration or SubAddConfiguration (for a sub tree) setting 'mirrored' to 'yes'.
+//
+// A sub tree is capped by using SubAddConfiguration setting 'maxSize' to a number, which will make the Tree replace the lowest keys of the
+// sub tree with tombstones whenever it grows beyond that size. Setting 'evict' to 'highest' will make it replace the highest keys instead.
+//
+// A sub tree is indexed by using SubAddConfiguration setting 'index.NAME' to 'jsonpath:$.FIELD', which will make the Tree keep the JSON
+// values of the sub tree in the index NAME, sorted by FIELD, iterable using IndexEachBetween.
 type Tree struct {
 	lock                   *common.TimeLock
 	timer                  Timer
@@ -106,6 +117,10 @@ type Tree struct {
 	configuration          map[string]string
 	configurationTimestamp int64
 	dataTimestamp          int64
+	changeListener         ChangeListener
+	indexes                map[string]*Tree
+	indexed                map[string]map[string][]byte // the index entries of each sub tree, by index name, to avoid scanning the indexes when removing them
+	sub                    bool                         // sub trees leave replacing their expired values to the Tree they are in, which has to update its hashes and sizes
 }
 
 func NewTree() *Tree {
@@ -124,6 +139,28 @@ func NewTreeTimer(timer Timer) (result *Tree) {
 func (self *Tree) Load() float64 {
 	return self.lock.Load()
 }
+// Snapshot returns a copy of this Tree as it is now, which later writes to this Tree won't change. It shares the values with this Tree,
+// but has its own copy of every node, and doesn't log, mirror or index anything.
+func (self *Tree) Snapshot() *Tree {
+	self.rLock()
+	defer self.lock.RUnlock()
+	return self.snapshot()
+}
+func (self *Tree) snapshot() (result *Tree) {
+	if self == nil {
+		return
+	}
+	result = &Tree{
+		lock:          common.NewTimeLock(),
+		timer:         self.timer,
+		root:          self.root.clone(),
+		dataTimestamp: self.dataTimestamp,
+		sub:           self.sub,
+	}
+	result.configuration, result.configurationTimestamp = self.conf()
+	return
+}
+
 func (self *Tree) deepEqual(o *Tree) bool {
 	return self.Describe() == o.Describe()
 }
@@ -147,13 +184,13 @@ func (self *Tree) mirrorClear(timestamp int64) {
 		self.mirror.Clear(timestamp)
 	}
 }
-func (self *Tree) mirrorPut(key, value []byte, timestamp int64) {
+func (self *Tree) mirrorPut(key, value []byte, timestamp, expires int64) {
 	if self.mirror != nil {
 		escapedKey := escapeBytes(key)
 		newKey := make([]byte, len(escapedKey)+len(value)+1)
 		copy(newKey, value)
 		copy(newKey[len(value)+1:], escapedKey)
-		self.mirror.Put(newKey, key, timestamp)
+		self.mirror.PutExpires(newKey, key, timestamp, expires)
 	}
 }
 func (self *Tree) mirrorFakeDel(key, value []byte, timestamp int64) {
@@ -177,7 +214,7 @@ func (self *Tree) mirrorDel(key, value []byte) {
 func (self *Tree) startMirroring() {
 	self.mirror = NewTreeTimer(self.timer)
 	self.root.each(nil, byteValue, func(key, byteValue []byte, treeValue *Tree, use int, timestamp int64) bool {
-		self.mirrorPut(key, byteValue, timestamp)
+		self.mirrorPut(key, byteValue, timestamp, self.root.expiresAt(Rip(key)))
 		return true
 	})
 }
@@ -219,55 +256,353 @@ func (self *Tree) AddConfiguration(ts int64, key, value string) bool {
 
 // Log will make this Tree start logging using a new persistence.Logger.
 func (self *Tree) Log(dir string) *Tree {
-	self.logger = persistence.NewLogger(dir)
+	return self.LogFsync(dir, persistence.FsyncPolicy{})
+}
+
+// LogFsync will make this Tree start logging using a new persistence.Logger fsyncing its logfile according to policy.
+func (self *Tree) LogFsync(dir string, policy persistence.FsyncPolicy) *Tree {
+	return self.LogWith(persistence.NewLogger(dir).Fsync(policy))
+}
+
+// LogWith will make this Tree start logging using logger, which must not be recording.
+func (self *Tree) LogWith(logger *persistence.Logger) *Tree {
+	self.logger = logger
 	<-self.logger.Record()
 	return self
 }
 
+// SyncLog will return when all operations logged by this Tree before it was called are durable, if sync is set or the Logger of this Tree
+// always fsyncs. Since it waits for an fsync, it must not be called while holding any locks.
+func (self *Tree) SyncLog(sync bool) {
+	if self.logger != nil && self.logger.Recording() && (sync || self.logger.Policy().Always) {
+		self.logger.Sync()
+	}
+}
+
 // Restore will temporarily stop the Logger of this Tree, make it replay all operations
 // to allow us to restore the state logged in that directory, and then start recording again.
 func (self *Tree) Restore() *Tree {
 	self.logger.Stop()
-	self.logger.Play(func(op persistence.Op) {
-		if op.Configuration != nil {
-			if op.Key == nil {
-				self.Configure(op.Configuration, op.Timestamp)
-			} else {
-				self.SubConfigure(op.Key, op.Configuration, op.Timestamp)
-			}
-		} else if op.Put {
-			if op.SubKey == nil {
-				self.Put(op.Key, op.Value, op.Timestamp)
+	self.logger.Play(self.Apply)
+	<-self.logger.Record()
+	return self
+}
+
+// RestoreUntil will restore the state logged by the Logger of this Tree as it was at t, like Restore, and then log the restored state
+// in a new snapshot, so that the operations timestamped after t are forgotten. The replaced logfiles and snapshots are moved into the
+// directory moved, inside the directory of the Logger, before the restored state is logged, so that a Logger with a Limit can't merge
+// them into it.
+//
+// If the latest snapshot was created after t, the state at t can't be fully restored, and RestoreUntil will return an error without
+// changing anything, unless force is set.
+func (self *Tree) RestoreUntil(t int64, force bool) (moved string, err error) {
+	self.logger.Stop()
+	defer func() {
+		<-self.logger.Record()
+	}()
+	var lost, skipped int
+	if lost, skipped, err = self.logger.PlayUntil(t, force, self.Apply); err != nil {
+		return
+	}
+	if lost > 0 || skipped > 0 {
+		log.Printf("restored the state at %v, skipping %v later operations and losing about %v damaged ones", time.Unix(0, t), skipped, lost)
+	}
+	if moved, err = self.logger.MoveOlderThan(time.Now()); err != nil {
+		return
+	}
+	<-self.logger.Record()
+	self.BackupBetween(nil, nil, true, false, self.logger.Dump)
+	self.logger.Stop()
+	return
+}
+
+// Apply will perform op, as logged by this Tree or created by BackupBetween, on this Tree.
+func (self *Tree) Apply(op persistence.Op) {
+	if op.Configuration != nil {
+		if op.Key == nil {
+			self.Configure(op.Configuration, op.Timestamp)
+		} else {
+			self.SubConfigure(op.Key, op.Configuration, op.Timestamp)
+		}
+	} else if op.Put {
+		if op.SubKey == nil {
+			if op.Mergeable {
+				self.PutMergeable(op.Key, op.Value, op.Timestamp, op.Expires)
 			} else {
-				self.SubPut(op.Key, op.SubKey, op.Value, op.Timestamp)
+				self.PutExpires(op.Key, op.Value, op.Timestamp, op.Expires)
 			}
 		} else {
-			if op.SubKey == nil {
-				if op.Clear {
-					if op.Timestamp > 0 {
-						self.SubClear(op.Key, op.Timestamp)
-					} else {
-						self.SubKill(op.Key)
-					}
+			self.SubPutExpires(op.Key, op.SubKey, op.Value, op.Timestamp, op.Expires)
+		}
+	} else if op.Range {
+		self.SubFakeDelBetween(op.Key, op.Min, op.Max, op.MinInc, op.MaxInc, op.Timestamp)
+	} else {
+		if op.SubKey == nil {
+			if op.Clear {
+				if op.Timestamp > 0 {
+					self.SubClear(op.Key, op.Timestamp)
 				} else {
-					self.Del(op.Key)
+					self.SubKill(op.Key)
 				}
 			} else {
-				self.SubDel(op.Key, op.SubKey)
+				self.Del(op.Key)
+			}
+		} else {
+			self.SubDel(op.Key, op.SubKey)
+		}
+	}
+}
+
+// ApplyNewer will perform op, as created by BackupBetween, on this Tree, unless it would replace newer data.
+// Values are only put if they are newer than the value or tombstone under their keys, and configurations only if they are newer than
+// the configurations they replace. Other operations are ignored.
+func (self *Tree) ApplyNewer(op persistence.Op) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if op.Configuration != nil {
+		if op.Key == nil {
+			if _, timestamp := self.conf(); timestamp < op.Timestamp {
+				self.configure(op.Configuration, op.Timestamp)
+			}
+		} else if _, timestamp := self.subConfiguration(op.Key); timestamp < op.Timestamp {
+			self.subConfigure(op.Key, op.Configuration, op.Timestamp)
+		}
+	} else if op.Put {
+		if op.SubKey == nil {
+			if _, _, timestamp, ex := self.root.get(Rip(op.Key)); (ex&treeValue != 0 && ex&byteValue == 0) || timestamp < op.Timestamp {
+				self.putExpires(op.Key, op.Value, op.Timestamp, op.Expires, op.Mergeable)
 			}
+		} else {
+			var timestamp int64
+			if _, subTree, _, ex := self.root.get(Rip(op.Key)); ex&treeValue != 0 && subTree != nil {
+				_, timestamp, _ = subTree.Get(op.SubKey)
+			}
+			if timestamp < op.Timestamp {
+				self.subPutExpires(op.Key, op.SubKey, op.Value, op.Timestamp, op.Expires)
+			}
+		}
+	}
+}
+
+// BackupBetween will call f with the operations that, using Apply, recreate the configuration of this Tree, and the values and sub trees
+// between min and max with their configurations and expiry times.
+// If min is greater than max, the range wraps around the end of the keys, like the range owned by a dhash node can.
+//
+// The operations are collected one page of backupPageSize values and sub trees at a time, and f is only called after releasing the lock,
+// so a slow f will not block writes. Each page is consistent, but writes done during the backup may be partly included.
+func (self *Tree) BackupBetween(min, max []byte, mininc, maxinc bool, f func(op persistence.Op)) {
+	self.rLock()
+	conf, timestamp := self.conf()
+	self.lock.RUnlock()
+	if len(conf) > 0 {
+		f(persistence.Op{
+			Configuration: conf,
+			Timestamp:     timestamp,
+		})
+	}
+	if min != nil && max != nil && bytes.Compare(min, max) > 0 {
+		self.backupPages(min, nil, mininc, maxinc, f)
+		self.backupPages(nil, max, mininc, maxinc, f)
+	} else {
+		self.backupPages(min, max, mininc, maxinc, f)
+	}
+}
+
+// backupPages will call f with the operations that recreate the values and sub trees between min and max, one page at a time.
+func (self *Tree) backupPages(min, max []byte, mininc, maxinc bool, f func(op persistence.Op)) {
+	for {
+		ops, last, more := self.BackupPage(min, max, mininc, maxinc, backupPageSize)
+		for _, op := range ops {
+			f(op)
+		}
+		if !more {
+			return
 		}
+		min, mininc = last, false
+	}
+}
+
+// BackupPage will return the operations that, using Apply, recreate the first limit values and sub trees between min and max with their
+// configurations and expiry times, as they were at a single point in time. last is the key of the last of them, and more is whether there
+// may be more after it.
+func (self *Tree) BackupPage(min, max []byte, mininc, maxinc bool, limit int) (ops []persistence.Op, last []byte, more bool) {
+	self.rLock()
+	defer self.lock.RUnlock()
+	collect := func(op persistence.Op) {
+		ops = append(ops, op)
+	}
+	count := 0
+	mincmp, maxcmp := cmps(mininc, maxinc)
+	self.root.eachBetween(nil, Rip(min), Rip(max), mincmp, maxcmp, byteValue|treeValue, func(key, bValue []byte, tValue *Tree, use int, timestamp int64) bool {
+		if use&byteValue != 0 {
+			collect(persistence.Op{
+				Key:       key,
+				Value:     bValue,
+				Timestamp: timestamp,
+				Expires:   self.root.expiresAt(Rip(key)),
+				Mergeable: self.root.mergeableAt(Rip(key)),
+				Put:       true,
+			})
+		}
+		if use&treeValue != 0 && tValue != nil {
+			tValue.backupSub(key, collect)
+		}
+		count++
+		last = key
+		return count < limit
 	})
-	<-self.logger.Record()
-	return self
+	more = count == limit
+	return
+}
+
+// backupSub will call f with the operations that recreate this Tree as the sub tree key.
+func (self *Tree) backupSub(key []byte, f func(op persistence.Op)) {
+	self.rLock()
+	defer self.lock.RUnlock()
+	if conf, timestamp := self.conf(); len(conf) > 0 {
+		f(persistence.Op{
+			Key:           key,
+			Configuration: conf,
+			Timestamp:     timestamp,
+		})
+	}
+	self.root.each(nil, byteValue, func(subKey, bValue []byte, tValue *Tree, use int, timestamp int64) bool {
+		f(persistence.Op{
+			Key:       key,
+			SubKey:    subKey,
+			Value:     bValue,
+			Timestamp: timestamp,
+			Expires:   self.root.expiresAt(Rip(subKey)),
+			Put:       true,
+		})
+		return true
+	})
+}
+
+// rLock will read lock this Tree, after having replaced any expired values in it with tombstones if it can get the write lock.
+// Since it might not, reads filter out expired values themselves.
+func (self *Tree) rLock() {
+	self.lock.RLock()
+	if !self.sub && self.root.hasExpired(self.timer.ContinuousTime()) {
+		self.lock.RUnlock()
+		if self.lock.TryLock() {
+			self.expire(self.timer.ContinuousTime())
+			self.lock.Unlock()
+		}
+		self.lock.RLock()
+	}
+}
+
+// expiredAt returns the expiry time of the byte value at key, and whether it has expired at now, but not yet been replaced with a tombstone.
+// Must be called with the lock held.
+func (self *Tree) expiredAt(key []Nibble, now int64) (expires int64, expired bool) {
+	if self.root.hasExpired(now) {
+		expires = self.root.expiresAt(key)
+		expired = expires != 0 && expires <= now
+	}
+	return
+}
+
+// live returns f wrapped to skip the byte values that have expired, but not yet been replaced with tombstones since rLock couldn't get the write lock.
+// Must be called with the lock held.
+func (self *Tree) live(f nodeIterator) nodeIterator {
+	now := self.timer.ContinuousTime()
+	if !self.root.hasExpired(now) {
+		return f
+	}
+	return func(key, bValue []byte, tValue *Tree, use int, timestamp int64) (cont bool) {
+		if use&byteValue != 0 {
+			if _, expired := self.expiredAt(Rip(key), now); expired {
+				return true
+			}
+		}
+		return f(key, bValue, tValue, use, timestamp)
+	}
+}
+func (self *Tree) nextExpiry() int64 {
+	if self == nil {
+		return 0
+	}
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	return self.root.nextExpiry
+}
+
+// expire will replace the expired values in this Tree and its sub trees with tombstones, and tell any ChangeListener that they were deleted.
+// Sub trees are left to the Tree they are in.
+func (self *Tree) expire(now int64) {
+	if self.sub {
+		return
+	}
+	self.root.expire(nil, now, func(key, subKey, oldBytes []byte, timestamp int64) {
+		self.notifyChange(persistence.Op{
+			Key:       key,
+			SubKey:    subKey,
+			Timestamp: timestamp,
+		}, common.ChangeDel, oldBytes)
+	})
+}
+
+// expireSub will replace the expired values in this sub tree with tombstones, and call f with each of them.
+func (self *Tree) expireSub(now int64, f expiryListener) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.root.expire(nil, now, f)
+}
+
+// Expire will replace all values in this Tree and its sub trees whose expiry time has passed with tombstones timestamped with their expiry time.
+func (self *Tree) Expire() {
+	if self == nil {
+		return
+	}
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.expire(self.timer.ContinuousTime())
 }
 func (self *Tree) log(op persistence.Op) {
 	if self.logger != nil && self.logger.Recording() {
 		self.logger.Dump(op)
 	}
 }
-func (self *Tree) newTreeWith(key []Nibble, byteValue []byte, timestamp int64) (result *Tree) {
+
+// logChange will log op, and tell any ChangeListener that it caused a change of type typ from oldBytes.
+func (self *Tree) logChange(op persistence.Op, typ string, oldBytes []byte) {
+	self.log(op)
+	self.notifyChange(op, typ, oldBytes)
+}
+
+// notifyChange will tell any ChangeListener that op caused a change of type typ from oldBytes.
+func (self *Tree) notifyChange(op persistence.Op, typ string, oldBytes []byte) {
+	if self.changeListener != nil {
+		change := common.Change{
+			Key:       op.Key,
+			SubKey:    op.SubKey,
+			Sub:       op.SubKey != nil || op.Clear,
+			Type:      typ,
+			OldValue:  oldBytes,
+			Timestamp: op.Timestamp,
+		}
+		if typ == common.ChangePut {
+			change.NewValue = op.Value
+		}
+		self.changeListener(change)
+	}
+}
+
+// SetChangeListener will make l get all changes to the values and sub trees of this Tree, except removals of values and sub trees without tombstones.
+func (self *Tree) SetChangeListener(l ChangeListener) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.changeListener = l
+}
+func (self *Tree) newSubTree() (result *Tree) {
 	result = NewTreeTimer(self.timer)
-	result.PutTimestamp(key, byteValue, true, 0, timestamp)
+	result.sub = true
+	return
+}
+func (self *Tree) newTreeWith(key []Nibble, byteValue []byte, present bool, timestamp, expires int64) (result *Tree) {
+	result = self.newSubTree()
+	result.PutTimestamp(key, byteValue, present, 0, timestamp, expires, false)
 	return
 }
 
@@ -276,9 +611,9 @@ func (self *Tree) Each(f TreeIterator) {
 	if self == nil {
 		return
 	}
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
-	self.root.each(nil, byteValue, newNodeIterator(f))
+	self.root.each(nil, byteValue, self.live(newNodeIterator(f)))
 }
 
 // ReverseEach will iterate over the entire tree in reverse order using f.
@@ -286,9 +621,38 @@ func (self *Tree) ReverseEach(f TreeIterator) {
 	if self == nil {
 		return
 	}
-	self.lock.RLock()
+	self.rLock()
+	defer self.lock.RUnlock()
+	self.root.reverseEach(nil, byteValue, self.live(newNodeIterator(f)))
+}
+
+// EachWithPrefix will iterate over all keys starting with prefix using f.
+func (self *Tree) EachWithPrefix(prefix []byte, f TreeIterator) {
+	if self == nil {
+		return
+	}
+	self.rLock()
 	defer self.lock.RUnlock()
-	self.root.reverseEach(nil, byteValue, newNodeIterator(f))
+	if n, parentKey := self.root.withPrefix(nil, Rip(prefix)); n != nil {
+		n.each(parentKey, byteValue, self.live(newNodeIterator(f)))
+	}
+}
+
+// CountPrefix returns the number of keys starting with prefix.
+func (self *Tree) CountPrefix(prefix []byte) (result int) {
+	if self == nil {
+		return
+	}
+	self.rLock()
+	defer self.lock.RUnlock()
+	if n, parentKey := self.root.withPrefix(nil, Rip(prefix)); n != nil {
+		if now := self.timer.ContinuousTime(); n.hasExpired(now) {
+			result = n.sizeBetween(parentKey, nil, nil, 0, 0, byteValue, now)
+		} else {
+			result = n.byteSize
+		}
+	}
+	return
 }
 
 // MirrorEachBetween will iterate between min and max in the mirror Tree using f.
@@ -308,10 +672,10 @@ func (self *Tree) EachBetween(min, max []byte, mininc, maxinc bool, f TreeIterat
 	if self == nil {
 		return
 	}
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	mincmp, maxcmp := cmps(mininc, maxinc)
-	self.root.eachBetween(nil, Rip(min), Rip(max), mincmp, maxcmp, byteValue, newNodeIterator(f))
+	self.root.eachBetween(nil, Rip(min), Rip(max), mincmp, maxcmp, byteValue, self.live(newNodeIterator(f)))
 }
 
 // MirrorReverseEachBetween will iterate between min and max in the mirror Tree, in reverse order, using f.
@@ -331,10 +695,10 @@ func (self *Tree) ReverseEachBetween(min, max []byte, mininc, maxinc bool, f Tre
 	if self == nil {
 		return
 	}
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	mincmp, maxcmp := cmps(mininc, maxinc)
-	self.root.reverseEachBetween(nil, Rip(min), Rip(max), mincmp, maxcmp, byteValue, newNodeIterator(f))
+	self.root.reverseEachBetween(nil, Rip(min), Rip(max), mincmp, maxcmp, byteValue, self.live(newNodeIterator(f)))
 }
 
 // MirrorIndexOf will return the index of (or the index it would have if it existed) key in the mirror Tree.
@@ -363,7 +727,7 @@ func (self *Tree) IndexOf(key []byte) (index int, existed bool) {
 	if self == nil {
 		return
 	}
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	index, ex := self.root.indexOf(0, Rip(key), byteValue, true)
 	existed = ex&byteValue != 0
@@ -396,7 +760,7 @@ func (self *Tree) ReverseIndexOf(key []byte) (index int, existed bool) {
 	if self == nil {
 		return
 	}
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	index, ex := self.root.indexOf(0, Rip(key), byteValue, false)
 	existed = ex&byteValue != 0
@@ -416,7 +780,7 @@ func (self *Tree) EachBetweenIndex(min, max *int, f TreeIndexIterator) {
 	if self == nil {
 		return
 	}
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	self.root.eachBetweenIndex(nil, 0, min, max, byteValue, newNodeIndexIterator(f))
 }
@@ -434,7 +798,7 @@ func (self *Tree) ReverseEachBetweenIndex(min, max *int, f TreeIndexIterator) {
 	if self == nil {
 		return
 	}
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	self.root.reverseEachBetweenIndex(nil, 0, min, max, byteValue, newNodeIndexIterator(f))
 }
@@ -484,10 +848,10 @@ func (self *Tree) sizeBetween(min, max []byte, mininc, maxinc bool, use int) int
 	if self == nil {
 		return 0
 	}
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	mincmp, maxcmp := cmps(mininc, maxinc)
-	return self.root.sizeBetween(nil, Rip(min), Rip(max), mincmp, maxcmp, use)
+	return self.root.sizeBetween(nil, Rip(min), Rip(max), mincmp, maxcmp, use, self.timer.ContinuousTime())
 }
 
 // RealSizeBetween returns the real, as in 'including tombstones and sub trees', size of this Tree between min anx max.
@@ -531,8 +895,11 @@ func (self *Tree) Size() int {
 	if self == nil {
 		return 0
 	}
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
+	if now := self.timer.ContinuousTime(); self.root.hasExpired(now) {
+		return self.root.sizeBetween(nil, nil, nil, 0, 0, byteValue|treeValue, now)
+	}
 	return self.root.byteSize + self.root.treeSize
 }
 func (self *Tree) describeIndented(first, indent int) string {
@@ -569,14 +936,18 @@ func (self *Tree) Describe() string {
 func (self *Tree) FakeDel(key []byte, timestamp int64) (oldBytes []byte, oldTree *Tree, existed bool) {
 	self.lock.Lock()
 	defer self.lock.Unlock()
+	return self.fakeDel(key, timestamp)
+}
+func (self *Tree) fakeDel(key []byte, timestamp int64) (oldBytes []byte, oldTree *Tree, existed bool) {
 	var ex int
 	self.root, oldBytes, oldTree, _, ex = self.root.fakeDel(nil, Rip(key), byteValue, timestamp, self.timer.ContinuousTime())
 	existed = ex&byteValue != 0
 	if existed {
 		self.mirrorFakeDel(key, oldBytes, timestamp)
-		self.log(persistence.Op{
-			Key: key,
-		})
+		self.logChange(persistence.Op{
+			Key:       key,
+			Timestamp: timestamp,
+		}, common.ChangeDel, oldBytes)
 	}
 	return
 }
@@ -586,31 +957,131 @@ func (self *Tree) put(key []Nibble, byteValue []byte, treeValue *Tree, use int,
 	return
 }
 
+func (self *Tree) putBytes(key []Nibble, bValue []byte, timestamp, expires int64, mergeable bool) (oldBytes []byte, existed int) {
+	self.dataTimestamp = timestamp
+	n := newNode(key, bValue, nil, timestamp, false, byteValue)
+	n.expires, n.mergeable = expires, mergeable
+	self.root, oldBytes, _, _, existed = self.root.insert(nil, n, self.timer.ContinuousTime())
+	return
+}
+
 // Put will put key and value with timestamp in this Tree.
 func (self *Tree) Put(key []byte, bValue []byte, timestamp int64) (oldBytes []byte, existed bool) {
+	return self.PutExpires(key, bValue, timestamp, 0)
+}
+
+// PutExpires will put key and value with timestamp in this Tree, and replace it with a tombstone timestamped expires when that time has passed.
+// An expires of 0 means that the value will never expire.
+func (self *Tree) PutExpires(key []byte, bValue []byte, timestamp, expires int64) (oldBytes []byte, existed bool) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	return self.putExpires(key, bValue, timestamp, expires, false)
+}
+
+// PutMergeable will do PutExpires, but mark the value as written by a sketch operation, like common.HyperLogLog.Add.
+// A Sync will merge it with a different mergeable value under the same key, using common.MergeValues, instead of replacing it.
+func (self *Tree) PutMergeable(key []byte, bValue []byte, timestamp, expires int64) (oldBytes []byte, existed bool) {
 	self.lock.Lock()
 	defer self.lock.Unlock()
-	oldBytes, _, ex := self.put(Rip(key), bValue, nil, byteValue, timestamp)
+	return self.putExpires(key, bValue, timestamp, expires, true)
+}
+func (self *Tree) putExpires(key []byte, bValue []byte, timestamp, expires int64, mergeable bool) (oldBytes []byte, existed bool) {
+	oldBytes, ex := self.putBytes(Rip(key), bValue, timestamp, expires, mergeable)
 	existed = ex*byteValue != 0
 	if existed {
 		self.mirrorDel(key, oldBytes)
 	}
-	self.mirrorPut(key, bValue, timestamp)
-	self.log(persistence.Op{
+	self.mirrorPut(key, bValue, timestamp, expires)
+	self.logChange(persistence.Op{
 		Key:       key,
 		Value:     bValue,
 		Timestamp: timestamp,
+		Expires:   expires,
+		Mergeable: mergeable,
 		Put:       true,
-	})
+	}, common.ChangePut, oldBytes)
+	return
+}
+
+// matches returns whether the value at key is expected, where a nil expected means that key must not exist.
+func (self *Tree) matches(key, expected []byte) bool {
+	self.expire(self.timer.ContinuousTime())
+	current, _, _, ex := self.root.get(Rip(key))
+	if expected == nil {
+		return ex&byteValue == 0
+	}
+	return ex&byteValue != 0 && bytes.Compare(current, expected) == 0
+}
+
+// CompareAndPut will atomically do PutExpires if the current value at key is expected, and return whether it did.
+// A nil expected means that key must not exist.
+func (self *Tree) CompareAndPut(key, expected, bValue []byte, timestamp, expires int64) (swapped bool) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if swapped = self.matches(key, expected); swapped {
+		self.putExpires(key, bValue, timestamp, expires, false)
+	}
+	return
+}
+
+// CompareAndFakeDel will atomically do FakeDel if the current value at key is expected, and return whether it did.
+func (self *Tree) CompareAndFakeDel(key, expected []byte, timestamp int64) (deleted bool) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if deleted = expected != nil && self.matches(key, expected); deleted {
+		self.fakeDel(key, timestamp)
+	}
+	return
+}
+
+// Update will atomically put the value returned by f under key with timestamp, keeping any expiry time the current value has, unless f returns false.
+// f will get the current value under key, and whether it existed.
+func (self *Tree) Update(key []byte, timestamp int64, f func(oldBytes []byte, existed bool) (newBytes []byte, update bool)) (newBytes []byte, expires int64, updated bool) {
+	return self.update(key, timestamp, false, f)
+}
+
+// UpdateMergeable will do Update, but mark the new value as mergeable like PutMergeable does.
+func (self *Tree) UpdateMergeable(key []byte, timestamp int64, f func(oldBytes []byte, existed bool) (newBytes []byte, update bool)) (newBytes []byte, expires int64, updated bool) {
+	return self.update(key, timestamp, true, f)
+}
+func (self *Tree) update(key []byte, timestamp int64, mergeable bool, f func(oldBytes []byte, existed bool) (newBytes []byte, update bool)) (newBytes []byte, expires int64, updated bool) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.expire(self.timer.ContinuousTime())
+	ripped := Rip(key)
+	oldBytes, _, _, ex := self.root.get(ripped)
+	if newBytes, updated = f(oldBytes, ex&byteValue != 0); updated {
+		expires = self.root.expiresAt(ripped)
+		self.putExpires(key, newBytes, timestamp, expires, mergeable)
+	}
 	return
 }
 
 // Get will return the value and timestamp at key.
 func (self *Tree) Get(key []byte) (bValue []byte, timestamp int64, existed bool) {
-	self.lock.RLock()
+	bValue, timestamp, _, existed = self.GetExpires(key)
+	return
+}
+
+// GetExpires will return the value, timestamp and expiry time at key, where an expiry time of 0 means that the value will never expire.
+func (self *Tree) GetExpires(key []byte) (bValue []byte, timestamp, expires int64, existed bool) {
+	bValue, timestamp, expires, _, existed = self.GetMergeable(key)
+	return
+}
+
+// GetMergeable will do GetExpires, and also return whether the value was put with PutMergeable or UpdateMergeable.
+func (self *Tree) GetMergeable(key []byte) (bValue []byte, timestamp, expires int64, mergeable, existed bool) {
+	self.rLock()
 	defer self.lock.RUnlock()
-	bValue, _, timestamp, ex := self.root.get(Rip(key))
-	existed = ex&byteValue != 0
+	ripped := Rip(key)
+	bValue, _, timestamp, ex := self.root.get(ripped)
+	if existed = ex&byteValue != 0; existed {
+		n := self.root.valueAt(ripped)
+		expires, mergeable = n.expires, n.mergeable
+		if expires != 0 && expires <= self.timer.ContinuousTime() {
+			bValue, timestamp, expires, mergeable, existed = nil, expires, 0, false, false
+		}
+	}
 	return
 }
 
@@ -619,7 +1090,7 @@ func (self *Tree) PrevMarker(key []byte) (prevKey []byte, existed bool) {
 	if self == nil {
 		return
 	}
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	self.root.reverseEachBetween(nil, nil, Rip(key), 0, 0, 0, func(k, b []byte, t *Tree, u int, v int64) bool {
 		prevKey, existed = k, true
@@ -633,7 +1104,7 @@ func (self *Tree) NextMarker(key []byte) (nextKey []byte, existed bool) {
 	if self == nil {
 		return
 	}
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	self.root.eachBetween(nil, Rip(key), nil, 0, 0, 0, func(k, b []byte, t *Tree, u int, v int64) bool {
 		nextKey, existed = k, true
@@ -685,7 +1156,7 @@ func (self *Tree) NextMarkerIndex(index int) (key []byte, existed bool) {
 	if self == nil {
 		return
 	}
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	self.root.eachBetweenIndex(nil, 0, &index, nil, 0, func(k, b []byte, t *Tree, u int, v int64, i int) bool {
 		key, existed = k, true
@@ -699,7 +1170,7 @@ func (self *Tree) PrevMarkerIndex(index int) (key []byte, existed bool) {
 	if self == nil {
 		return
 	}
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	self.root.reverseEachBetweenIndex(nil, 0, nil, &index, 0, func(k, b []byte, t *Tree, u int, v int64, i int) bool {
 		key, existed = k, true
@@ -833,12 +1304,18 @@ func (self *Tree) ReverseIndex(n int) (key []byte, byteValue []byte, timestamp i
 func (self *Tree) Clear(timestamp int64) {
 	self.lock.Lock()
 	defer self.lock.Unlock()
-	self.dataTimestamp, self.root = timestamp, nil
+	self.dataTimestamp, self.root, self.indexes, self.indexed = timestamp, nil, nil, nil
 	self.root, _, _, _, _ = self.root.insert(nil, newNode(nil, nil, nil, 0, true, 0), self.timer.ContinuousTime())
 	self.mirrorClear(timestamp)
 	if self.logger != nil {
 		self.logger.Clear()
 	}
+	if self.changeListener != nil {
+		self.changeListener(common.Change{
+			Type:      common.ChangeClear,
+			Timestamp: timestamp,
+		})
+	}
 }
 func (self *Tree) del(key []Nibble, use int) (oldBytes []byte, existed bool) {
 	var ex int
@@ -862,7 +1339,7 @@ func (self *Tree) Del(key []byte) (oldBytes []byte, existed bool) {
 }
 
 func (self *Tree) SubMirrorReverseIndexOf(key, subKey []byte) (index int, existed bool) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		index, existed = subTree.MirrorReverseIndexOf(subKey)
@@ -870,7 +1347,7 @@ func (self *Tree) SubMirrorReverseIndexOf(key, subKey []byte) (index int, existe
 	return
 }
 func (self *Tree) SubMirrorIndexOf(key, subKey []byte) (index int, existed bool) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		index, existed = subTree.MirrorIndexOf(subKey)
@@ -878,7 +1355,7 @@ func (self *Tree) SubMirrorIndexOf(key, subKey []byte) (index int, existed bool)
 	return
 }
 func (self *Tree) SubReverseIndexOf(key, subKey []byte) (index int, existed bool) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		index, existed = subTree.ReverseIndexOf(subKey)
@@ -886,7 +1363,7 @@ func (self *Tree) SubReverseIndexOf(key, subKey []byte) (index int, existed bool
 	return
 }
 func (self *Tree) SubIndexOf(key, subKey []byte) (index int, existed bool) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		index, existed = subTree.IndexOf(subKey)
@@ -894,7 +1371,7 @@ func (self *Tree) SubIndexOf(key, subKey []byte) (index int, existed bool) {
 	return
 }
 func (self *Tree) SubMirrorPrevIndex(key []byte, index int) (foundKey, foundValue []byte, foundTimestamp int64, foundIndex int, existed bool) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		foundKey, foundValue, foundTimestamp, foundIndex, existed = subTree.MirrorPrevIndex(index)
@@ -902,7 +1379,7 @@ func (self *Tree) SubMirrorPrevIndex(key []byte, index int) (foundKey, foundValu
 	return
 }
 func (self *Tree) SubMirrorNextIndex(key []byte, index int) (foundKey, foundValue []byte, foundTimestamp int64, foundIndex int, existed bool) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		foundKey, foundValue, foundTimestamp, foundIndex, existed = subTree.MirrorNextIndex(index)
@@ -910,7 +1387,7 @@ func (self *Tree) SubMirrorNextIndex(key []byte, index int) (foundKey, foundValu
 	return
 }
 func (self *Tree) SubPrevIndex(key []byte, index int) (foundKey, foundValue []byte, foundTimestamp int64, foundIndex int, existed bool) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		foundKey, foundValue, foundTimestamp, foundIndex, existed = subTree.PrevIndex(index)
@@ -918,7 +1395,7 @@ func (self *Tree) SubPrevIndex(key []byte, index int) (foundKey, foundValue []by
 	return
 }
 func (self *Tree) SubNextIndex(key []byte, index int) (foundKey, foundValue []byte, foundTimestamp int64, foundIndex int, existed bool) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		foundKey, foundValue, foundTimestamp, foundIndex, existed = subTree.NextIndex(index)
@@ -926,7 +1403,7 @@ func (self *Tree) SubNextIndex(key []byte, index int) (foundKey, foundValue []by
 	return
 }
 func (self *Tree) SubMirrorFirst(key []byte) (firstKey []byte, firstBytes []byte, firstTimestamp int64, existed bool) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		firstKey, firstBytes, firstTimestamp, existed = subTree.MirrorFirst()
@@ -934,7 +1411,7 @@ func (self *Tree) SubMirrorFirst(key []byte) (firstKey []byte, firstBytes []byte
 	return
 }
 func (self *Tree) SubMirrorLast(key []byte) (lastKey []byte, lastBytes []byte, lastTimestamp int64, existed bool) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		lastKey, lastBytes, lastTimestamp, existed = subTree.MirrorLast()
@@ -942,7 +1419,7 @@ func (self *Tree) SubMirrorLast(key []byte) (lastKey []byte, lastBytes []byte, l
 	return
 }
 func (self *Tree) SubFirst(key []byte) (firstKey []byte, firstBytes []byte, firstTimestamp int64, existed bool) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		firstKey, firstBytes, firstTimestamp, existed = subTree.First()
@@ -950,7 +1427,7 @@ func (self *Tree) SubFirst(key []byte) (firstKey []byte, firstBytes []byte, firs
 	return
 }
 func (self *Tree) SubLast(key []byte) (lastKey []byte, lastBytes []byte, lastTimestamp int64, existed bool) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		lastKey, lastBytes, lastTimestamp, existed = subTree.Last()
@@ -958,7 +1435,7 @@ func (self *Tree) SubLast(key []byte) (lastKey []byte, lastBytes []byte, lastTim
 	return
 }
 func (self *Tree) SubMirrorPrev(key, subKey []byte) (prevKey, prevValue []byte, prevTimestamp int64, existed bool) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		prevKey, prevValue, prevTimestamp, existed = subTree.MirrorPrev(subKey)
@@ -966,7 +1443,7 @@ func (self *Tree) SubMirrorPrev(key, subKey []byte) (prevKey, prevValue []byte,
 	return
 }
 func (self *Tree) SubMirrorNext(key, subKey []byte) (nextKey, nextValue []byte, nextTimestamp int64, existed bool) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		nextKey, nextValue, nextTimestamp, existed = subTree.MirrorNext(subKey)
@@ -974,7 +1451,7 @@ func (self *Tree) SubMirrorNext(key, subKey []byte) (nextKey, nextValue []byte,
 	return
 }
 func (self *Tree) SubPrev(key, subKey []byte) (prevKey, prevValue []byte, prevTimestamp int64, existed bool) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		prevKey, prevValue, prevTimestamp, existed = subTree.Prev(subKey)
@@ -982,7 +1459,7 @@ func (self *Tree) SubPrev(key, subKey []byte) (prevKey, prevValue []byte, prevTi
 	return
 }
 func (self *Tree) SubNext(key, subKey []byte) (nextKey, nextValue []byte, nextTimestamp int64, existed bool) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		nextKey, nextValue, nextTimestamp, existed = subTree.Next(subKey)
@@ -990,7 +1467,7 @@ func (self *Tree) SubNext(key, subKey []byte) (nextKey, nextValue []byte, nextTi
 	return
 }
 func (self *Tree) SubSize(key []byte) (result int) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		result = subTree.Size()
@@ -998,7 +1475,7 @@ func (self *Tree) SubSize(key []byte) (result int) {
 	return
 }
 func (self *Tree) SubMirrorSizeBetween(key, min, max []byte, mininc, maxinc bool) (result int) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		result = subTree.MirrorSizeBetween(min, max, mininc, maxinc)
@@ -1006,7 +1483,7 @@ func (self *Tree) SubMirrorSizeBetween(key, min, max []byte, mininc, maxinc bool
 	return
 }
 func (self *Tree) SubSizeBetween(key, min, max []byte, mininc, maxinc bool) (result int) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		result = subTree.SizeBetween(min, max, mininc, maxinc)
@@ -1014,87 +1491,122 @@ func (self *Tree) SubSizeBetween(key, min, max []byte, mininc, maxinc bool) (res
 	return
 }
 func (self *Tree) SubGet(key, subKey []byte) (byteValue []byte, timestamp int64, existed bool) {
-	self.lock.RLock()
+	byteValue, timestamp, _, existed = self.SubGetExpires(key, subKey)
+	return
+}
+
+// SubGetExpires does GetExpires on the sub tree.
+func (self *Tree) SubGetExpires(key, subKey []byte) (byteValue []byte, timestamp, expires int64, existed bool) {
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
-		byteValue, timestamp, existed = subTree.Get(subKey)
+		byteValue, timestamp, expires, existed = subTree.GetExpires(subKey)
 	}
 	return
 }
 func (self *Tree) SubMirrorReverseEachBetween(key, min, max []byte, mininc, maxinc bool, f TreeIterator) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		subTree.MirrorReverseEachBetween(min, max, mininc, maxinc, f)
 	}
 }
 func (self *Tree) SubMirrorEachBetween(key, min, max []byte, mininc, maxinc bool, f TreeIterator) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		subTree.MirrorEachBetween(min, max, mininc, maxinc, f)
 	}
 }
 func (self *Tree) SubMirrorReverseEachBetweenIndex(key []byte, min, max *int, f TreeIndexIterator) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		subTree.MirrorReverseEachBetweenIndex(min, max, f)
 	}
 }
 func (self *Tree) SubMirrorEachBetweenIndex(key []byte, min, max *int, f TreeIndexIterator) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		subTree.MirrorEachBetweenIndex(min, max, f)
 	}
 }
 func (self *Tree) SubReverseEachBetween(key, min, max []byte, mininc, maxinc bool, f TreeIterator) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		subTree.ReverseEachBetween(min, max, mininc, maxinc, f)
 	}
 }
 func (self *Tree) SubEachBetween(key, min, max []byte, mininc, maxinc bool, f TreeIterator) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		subTree.EachBetween(min, max, mininc, maxinc, f)
 	}
 }
 func (self *Tree) SubReverseEachBetweenIndex(key []byte, min, max *int, f TreeIndexIterator) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		subTree.ReverseEachBetweenIndex(min, max, f)
 	}
 }
 func (self *Tree) SubEachBetweenIndex(key []byte, min, max *int, f TreeIndexIterator) {
-	self.lock.RLock()
+	self.rLock()
 	defer self.lock.RUnlock()
 	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
 		subTree.EachBetweenIndex(min, max, f)
 	}
 }
+
+// SubEachWithPrefix does EachWithPrefix on the sub tree.
+func (self *Tree) SubEachWithPrefix(key, prefix []byte, f TreeIterator) {
+	self.rLock()
+	defer self.lock.RUnlock()
+	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
+		subTree.EachWithPrefix(prefix, f)
+	}
+}
+
+// SubCountPrefix does CountPrefix on the sub tree.
+func (self *Tree) SubCountPrefix(key, prefix []byte) (result int) {
+	self.rLock()
+	defer self.lock.RUnlock()
+	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
+		result = subTree.CountPrefix(prefix)
+	}
+	return
+}
 func (self *Tree) SubPut(key, subKey []byte, byteValue []byte, timestamp int64) (oldBytes []byte, existed bool) {
+	return self.SubPutExpires(key, subKey, byteValue, timestamp, 0)
+}
+
+// SubPutExpires does PutExpires on the sub tree.
+func (self *Tree) SubPutExpires(key, subKey []byte, byteValue []byte, timestamp, expires int64) (oldBytes []byte, existed bool) {
 	self.lock.Lock()
 	defer self.lock.Unlock()
+	return self.subPutExpires(key, subKey, byteValue, timestamp, expires)
+}
+func (self *Tree) subPutExpires(key, subKey []byte, byteValue []byte, timestamp, expires int64) (oldBytes []byte, existed bool) {
 	ripped := Rip(key)
 	_, subTree, subTreeTimestamp, ex := self.root.get(ripped)
 	if ex&treeValue == 0 || subTree == nil {
-		subTree = self.newTreeWith(Rip(subKey), byteValue, timestamp)
-	} else {
-		oldBytes, existed = subTree.Put(subKey, byteValue, timestamp)
+		subTree = self.newSubTree()
 	}
+	oldBytes, existed = subTree.PutExpires(subKey, byteValue, timestamp, expires)
+	self.index(key, subKey, subTree, oldBytes, byteValue, timestamp)
 	self.put(ripped, nil, subTree, treeValue, subTreeTimestamp)
-	self.log(persistence.Op{
+	self.logChange(persistence.Op{
 		Key:       key,
 		SubKey:    subKey,
 		Value:     byteValue,
 		Timestamp: timestamp,
+		Expires:   expires,
 		Put:       true,
-	})
+	}, common.ChangePut, oldBytes)
+	self.evict(key, timestamp)
 	return
 }
 func (self *Tree) SubDel(key, subKey []byte) (oldBytes []byte, existed bool) {
@@ -1103,6 +1615,7 @@ func (self *Tree) SubDel(key, subKey []byte) (oldBytes []byte, existed bool) {
 	ripped := Rip(key)
 	if _, subTree, subTreeTimestamp, ex := self.root.get(ripped); ex&treeValue != 0 && subTree != nil {
 		oldBytes, existed = subTree.Del(subKey)
+		self.index(key, subKey, subTree, oldBytes, nil, 0)
 		if subTree.RealSize() == 0 {
 			self.del(ripped, treeValue)
 		} else {
@@ -1120,20 +1633,171 @@ func (self *Tree) SubDel(key, subKey []byte) (oldBytes []byte, existed bool) {
 func (self *Tree) SubFakeDel(key, subKey []byte, timestamp int64) (oldBytes []byte, existed bool) {
 	self.lock.Lock()
 	defer self.lock.Unlock()
+	return self.subFakeDel(key, subKey, timestamp)
+}
+func (self *Tree) subFakeDel(key, subKey []byte, timestamp int64) (oldBytes []byte, existed bool) {
 	ripped := Rip(key)
 	if _, subTree, subTreeTimestamp, ex := self.root.get(ripped); ex&treeValue != 0 && subTree != nil {
 		oldBytes, _, existed = subTree.FakeDel(subKey, timestamp)
+		self.index(key, subKey, subTree, oldBytes, nil, 0)
 		self.put(ripped, nil, subTree, treeValue, subTreeTimestamp)
 	}
 	if existed {
+		self.logChange(persistence.Op{
+			Key:       key,
+			SubKey:    subKey,
+			Timestamp: timestamp,
+		}, common.ChangeDel, oldBytes)
+	}
+	return
+}
+
+// SubFakeDelPrefix will replace all keys starting with prefix in the sub tree with tombstones, and return the number of keys it replaced.
+func (self *Tree) SubFakeDelPrefix(key, prefix []byte, timestamp int64) (deleted int) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	var subKeys [][]byte
+	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
+		subTree.EachWithPrefix(prefix, func(subKey, value []byte, subTimestamp int64) bool {
+			subKeys = append(subKeys, subKey)
+			return true
+		})
+	}
+	for _, subKey := range subKeys {
+		if _, existed := self.subFakeDel(key, subKey, timestamp); existed {
+			deleted++
+		}
+	}
+	return
+}
+
+// SubFakeDelBetween will replace all keys between min and max in the sub tree with tombstones, log it as one operation,
+// and return the number of keys it replaced.
+func (self *Tree) SubFakeDelBetween(key, min, max []byte, mininc, maxinc bool, timestamp int64) (deleted int) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	return self.subFakeDelBetween(key, min, max, mininc, maxinc, timestamp)
+}
+func (self *Tree) subFakeDelBetween(key, min, max []byte, mininc, maxinc bool, timestamp int64) (deleted int) {
+	ripped := Rip(key)
+	if _, subTree, subTreeTimestamp, ex := self.root.get(ripped); ex&treeValue != 0 && subTree != nil {
+		var subKeys [][]byte
+		subTree.EachBetween(min, max, mininc, maxinc, func(subKey, value []byte, subTimestamp int64) bool {
+			subKeys = append(subKeys, subKey)
+			return true
+		})
+		for _, subKey := range subKeys {
+			if oldBytes, _, existed := subTree.FakeDel(subKey, timestamp); existed {
+				deleted++
+				self.index(key, subKey, subTree, oldBytes, nil, 0)
+				self.notifyChange(persistence.Op{
+					Key:       key,
+					SubKey:    subKey,
+					Timestamp: timestamp,
+				}, common.ChangeDel, oldBytes)
+			}
+		}
+		self.put(ripped, nil, subTree, treeValue, subTreeTimestamp)
+	}
+	if deleted > 0 {
 		self.log(persistence.Op{
-			Key:    key,
-			SubKey: subKey,
+			Key:       key,
+			Min:       min,
+			Max:       max,
+			MinInc:    mininc,
+			MaxInc:    maxinc,
+			Range:     true,
+			Timestamp: timestamp,
 		})
 	}
 	return
 }
 
+// SubFakeDelBetweenIndex will replace all keys between index min and max in the sub tree with tombstones, and return the number of keys it replaced.
+// A min or max of nil means the start or end of the sub tree. first and last are the keys between which it replaced everything, to allow
+// repeating the deletion using SubFakeDelBetween.
+func (self *Tree) SubFakeDelBetweenIndex(key []byte, min, max *int, timestamp int64) (first, last []byte, deleted int) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
+		subTree.EachBetweenIndex(min, max, func(subKey, value []byte, subTimestamp int64, index int) bool {
+			if first == nil {
+				first = subKey
+			}
+			last = subKey
+			return true
+		})
+	}
+	if last == nil {
+		return
+	}
+	if min == nil {
+		first = nil
+	}
+	if max == nil {
+		last = nil
+	}
+	deleted = self.subFakeDelBetween(key, first, last, true, true, timestamp)
+	return
+}
+
+func (self *Tree) subMatches(key, subKey, expected []byte) bool {
+	self.expire(self.timer.ContinuousTime())
+	if _, subTree, _, ex := self.root.get(Rip(key)); ex&treeValue != 0 && subTree != nil {
+		subTree.lock.Lock()
+		defer subTree.lock.Unlock()
+		return subTree.matches(subKey, expected)
+	}
+	return expected == nil
+}
+
+// SubUpdate does Update on the sub tree.
+func (self *Tree) SubUpdate(key, subKey []byte, timestamp int64, f func(oldBytes []byte, existed bool) (newBytes []byte, update bool)) (newBytes []byte, expires int64, updated bool) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.expire(self.timer.ContinuousTime())
+	ripped := Rip(key)
+	_, subTree, subTreeTimestamp, ex := self.root.get(ripped)
+	if ex&treeValue == 0 || subTree == nil {
+		subTree = self.newSubTree()
+	}
+	oldBytes, _, _ := subTree.Get(subKey)
+	if newBytes, expires, updated = subTree.Update(subKey, timestamp, f); updated {
+		self.index(key, subKey, subTree, oldBytes, newBytes, timestamp)
+		self.put(ripped, nil, subTree, treeValue, subTreeTimestamp)
+		self.logChange(persistence.Op{
+			Key:       key,
+			SubKey:    subKey,
+			Value:     newBytes,
+			Timestamp: timestamp,
+			Expires:   expires,
+			Put:       true,
+		}, common.ChangePut, oldBytes)
+		self.evict(key, timestamp)
+	}
+	return
+}
+
+// SubCompareAndPut does CompareAndPut on the sub tree.
+func (self *Tree) SubCompareAndPut(key, subKey, expected, byteValue []byte, timestamp, expires int64) (swapped bool) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if swapped = self.subMatches(key, subKey, expected); swapped {
+		self.subPutExpires(key, subKey, byteValue, timestamp, expires)
+	}
+	return
+}
+
+// SubCompareAndFakeDel does CompareAndFakeDel on the sub tree.
+func (self *Tree) SubCompareAndFakeDel(key, subKey, expected []byte, timestamp int64) (deleted bool) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if deleted = expected != nil && self.subMatches(key, subKey, expected); deleted {
+		self.subFakeDel(key, subKey, timestamp)
+	}
+	return
+}
+
 // SubClear does Clear on the sub tree.
 func (self *Tree) SubClear(key []byte, timestamp int64) (deleted int) {
 	self.lock.Lock()
@@ -1142,14 +1806,15 @@ func (self *Tree) SubClear(key []byte, timestamp int64) (deleted int) {
 	if _, subTree, subTreeTimestamp, ex := self.root.get(ripped); ex&treeValue != 0 && subTree != nil {
 		deleted = subTree.Size()
 		subTree.Clear(timestamp)
+		self.unindex(key)
 		self.put(ripped, nil, subTree, treeValue, subTreeTimestamp)
 	}
 	if deleted > 0 {
-		self.log(persistence.Op{
+		self.logChange(persistence.Op{
 			Key:       key,
 			Clear:     true,
 			Timestamp: timestamp,
-		})
+		}, common.ChangeClear, nil)
 	}
 	return
 }
@@ -1162,6 +1827,7 @@ func (self *Tree) SubKill(key []byte) (deleted int) {
 	if _, subTree, _, ex := self.root.get(ripped); ex&treeValue != 0 && subTree != nil {
 		deleted = subTree.Size()
 		self.del(ripped, treeValue)
+		self.unindex(key)
 	}
 	if deleted > 0 {
 		self.log(persistence.Op{
@@ -1183,14 +1849,26 @@ func (self *Tree) GetTimestamp(key []Nibble) (bValue []byte, timestamp int64, pr
 	present = ex&byteValue != 0
 	return
 }
-func (self *Tree) putTimestamp(key []Nibble, bValue []byte, treeValue *Tree, nodeUse, insertUse int, expected, timestamp int64) (result bool, oldBytes []byte) {
+func (self *Tree) putTimestamp(key []Nibble, bValue []byte, treeValue *Tree, nodeUse, insertUse int, expected, timestamp, expires int64, mergeable bool) (result bool, oldBytes []byte) {
 	if _, _, current, _ := self.root.get(key); current == expected {
 		self.dataTimestamp, result = timestamp, true
-		self.root, oldBytes, _, _, _ = self.root.insertHelp(nil, newNode(key, bValue, treeValue, timestamp, false, nodeUse), insertUse, self.timer.ContinuousTime())
+		n := newNode(key, bValue, treeValue, timestamp, false, nodeUse)
+		if nodeUse&byteValue != 0 {
+			n.expires, n.mergeable = expires, mergeable
+		}
+		self.root, oldBytes, _, _, _ = self.root.insertHelp(nil, n, insertUse, self.timer.ContinuousTime())
 	}
 	return
 }
-func (self *Tree) PutTimestamp(key []Nibble, bValue []byte, present bool, expected, timestamp int64) (result bool) {
+
+// changeType returns the type of change caused by putting a value that is present or not.
+func changeType(present bool) string {
+	if present {
+		return common.ChangePut
+	}
+	return common.ChangeDel
+}
+func (self *Tree) PutTimestamp(key []Nibble, bValue []byte, present bool, expected, timestamp, expires int64, mergeable bool) (result bool) {
 	self.lock.Lock()
 	defer self.lock.Unlock()
 	nodeUse := 0
@@ -1198,17 +1876,19 @@ func (self *Tree) PutTimestamp(key []Nibble, bValue []byte, present bool, expect
 		nodeUse = byteValue
 	}
 	var oldBytes []byte
-	result, oldBytes = self.putTimestamp(key, bValue, nil, nodeUse, byteValue, expected, timestamp)
+	result, oldBytes = self.putTimestamp(key, bValue, nil, nodeUse, byteValue, expected, timestamp, expires, mergeable)
 	if result {
 		stitched := Stitch(key)
 		self.mirrorDel(stitched, oldBytes)
-		self.mirrorPut(stitched, bValue, timestamp)
-		self.log(persistence.Op{
+		self.mirrorPut(stitched, bValue, timestamp, expires)
+		self.logChange(persistence.Op{
 			Key:       Stitch(key),
 			Value:     bValue,
 			Timestamp: timestamp,
+			Expires:   expires,
+			Mergeable: mergeable,
 			Put:       true,
-		})
+		}, changeType(present), oldBytes)
 	}
 	return
 }
@@ -1250,15 +1930,17 @@ func (self *Tree) subConfigure(key []byte, conf map[string]string, timestamp int
 	ripped := Rip(key)
 	_, subTree, subTreeTimestamp, ex := self.root.get(ripped)
 	if ex&treeValue == 0 || subTree == nil {
-		subTree = NewTreeTimer(self.timer)
+		subTree = self.newSubTree()
 	}
 	subTree.Configure(conf, timestamp)
+	self.reindex(key, subTree)
 	self.put(ripped, nil, subTree, treeValue, subTreeTimestamp)
 	self.log(persistence.Op{
 		Key:           key,
 		Configuration: conf,
 		Timestamp:     timestamp,
 	})
+	self.evict(key, timestamp)
 }
 func (self *Tree) SubConfigure(key []byte, conf map[string]string, timestamp int64) {
 	self.lock.Lock()
@@ -1294,25 +1976,40 @@ func (self *Tree) SubGetTimestamp(key, subKey []Nibble) (byteValue []byte, times
 	}
 	return
 }
-func (self *Tree) SubPutTimestamp(key, subKey []Nibble, bValue []byte, present bool, subExpected, subTimestamp int64) (result bool) {
+func (self *Tree) SubPutTimestamp(key, subKey []Nibble, bValue []byte, present bool, subExpected, subTimestamp, subExpires int64) (result bool) {
 	self.lock.Lock()
 	defer self.lock.Unlock()
 	_, subTree, subTreeTimestamp, _ := self.root.get(key)
+	var oldBytes []byte
 	if subTree == nil {
 		result = true
-		subTree = self.newTreeWith(subKey, bValue, subTimestamp)
+		subTree = self.newTreeWith(subKey, bValue, present, subTimestamp, subExpires)
+		if present {
+			self.index(Stitch(key), Stitch(subKey), subTree, nil, bValue, subTimestamp)
+		}
 	} else {
-		result = subTree.PutTimestamp(subKey, bValue, present, subExpected, subTimestamp)
+		oldBytes, _, _ = subTree.GetTimestamp(subKey)
+		if result = subTree.PutTimestamp(subKey, bValue, present, subExpected, subTimestamp, subExpires, false); result {
+			var newBytes []byte
+			if present {
+				newBytes = bValue
+			}
+			self.index(Stitch(key), Stitch(subKey), subTree, oldBytes, newBytes, subTimestamp)
+		}
 	}
-	self.putTimestamp(key, nil, subTree, treeValue, treeValue, subTreeTimestamp, subTreeTimestamp)
+	self.putTimestamp(key, nil, subTree, treeValue, treeValue, subTreeTimestamp, subTreeTimestamp, 0, false)
 	if result {
-		self.log(persistence.Op{
+		self.logChange(persistence.Op{
 			Key:       Stitch(key),
 			SubKey:    Stitch(subKey),
 			Value:     bValue,
 			Timestamp: subTimestamp,
+			Expires:   subExpires,
 			Put:       true,
-		})
+		}, changeType(present), oldBytes)
+		if present {
+			self.evict(Stitch(key), subTimestamp)
+		}
 	}
 	return
 }
@@ -1320,11 +2017,14 @@ func (self *Tree) SubDelTimestamp(key, subKey []Nibble, subExpected int64) (resu
 	self.lock.Lock()
 	defer self.lock.Unlock()
 	if _, subTree, subTreeTimestamp, ex := self.root.get(key); ex&treeValue != 0 && subTree != nil {
-		result = subTree.DelTimestamp(subKey, subExpected)
+		oldBytes, _, _ := subTree.GetTimestamp(subKey)
+		if result = subTree.DelTimestamp(subKey, subExpected); result {
+			self.index(Stitch(key), Stitch(subKey), subTree, oldBytes, nil, 0)
+		}
 		if subTree.Size() == 0 {
 			self.delTimestamp(key, treeValue, subTreeTimestamp)
 		} else {
-			self.putTimestamp(key, nil, subTree, treeValue, treeValue, subTreeTimestamp, subTreeTimestamp)
+			self.putTimestamp(key, nil, subTree, treeValue, treeValue, subTreeTimestamp, subTreeTimestamp, 0, false)
 		}
 	}
 	if result {
@@ -1341,7 +2041,8 @@ func (self *Tree) SubClearTimestamp(key []Nibble, expected, timestamp int64) (de
 	if _, subTree, subTreeTimestamp, ex := self.root.get(key); ex&treeValue != 0 && subTree != nil && subTree.DataTimestamp() == expected {
 		deleted = subTree.Size()
 		subTree.Clear(timestamp)
-		self.putTimestamp(key, nil, subTree, treeValue, treeValue, subTreeTimestamp, subTreeTimestamp)
+		self.unindex(Stitch(key))
+		self.putTimestamp(key, nil, subTree, treeValue, treeValue, subTreeTimestamp, subTreeTimestamp, 0, false)
 	}
 	if deleted > 0 {
 		self.log(persistence.Op{
@@ -1358,6 +2059,7 @@ func (self *Tree) SubKillTimestamp(key []Nibble, expected int64) (deleted int) {
 	if _, subTree, subTreeTimestamp, ex := self.root.get(key); ex&treeValue != 0 && subTree != nil && subTree.DataTimestamp() == expected {
 		deleted = subTree.Size()
 		self.delTimestamp(key, treeValue, subTreeTimestamp)
+		self.unindex(Stitch(key))
 	}
 	if deleted > 0 {
 		self.log(persistence.Op{
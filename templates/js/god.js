@@ -290,7 +290,9 @@ function b64decode(obj) {
 		var rval = {};
 		for (var name in obj) {
 			var value = obj[name];
-			if (typeof(value) == "string") {
+			if (name.indexOf("Decoded") == 0) {
+				rval[name] = value;
+			} else if (typeof(value) == "string") {
 			  try {
 					rval[name] = $.base64.decode2s(value);
 				} catch (e) {